@@ -4,6 +4,8 @@ import (
 	"aidalinfo-copilot/backend"
 	"context"
 	"fmt"
+	"path/filepath"
+	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -22,8 +24,24 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	// Initialiser le contexte pour LogToFrontend
-	backend.AppCtxForLogToFrontend = ctx
+	// Chaque changement d'état d'un job est envoyé au frontend
+	backend.Jobs.SetListener(func(job backend.JobInfo) {
+		runtime.EventsEmit(ctx, "job-update", job)
+	})
+	// Progression détaillée (octets, débit, ETA) des téléchargements, uploads, dumps et restaurations
+	backend.SetProgressListener(func(event backend.ProgressEvent) {
+		runtime.EventsEmit(ctx, "progress", event)
+	})
+	// Les logs du backend partent vers le terminal, le frontend et le fichier de log
+	if err := backend.SetupLogging(backend.LogOptions{Level: backend.LevelInfo, File: true, WailsCtx: ctx}); err != nil {
+		backend.Log.Warn("Fichier de log indisponible", backend.F("error", err))
+	}
+	// Charge la configuration (utilisateur + .aidalinfo.yaml du dossier courant + environnement)
+	if cfg, err := backend.LoadConfig("."); err == nil {
+		backend.SetConfig(cfg)
+	} else {
+		backend.Log.Warn(fmt.Sprintf("Configuration ignorée: %v", err))
+	}
 	// Force la fenêtre à se maximiser sur l'écran courant au démarrage
 	runtime.WindowMaximise(ctx)
 }
@@ -73,11 +91,15 @@ func (a *App) GetLastCommits(submodules []string) ([]backend.Commit, error) {
 
 // Backend Setup operations
 func (a *App) InstallSubmodules(path string, branches []string) error {
-	return backend.SubmoduleAction(path, branches...)
+	return a.runJob("install", "Installation des submodules", func(ctx context.Context) error {
+		return backend.SubmoduleAction(ctx, path, branches...)
+	})
 }
 
 func (a *App) InstallNpmDependencies(path string, all bool) error {
-	return backend.NpmAction(path, all)
+	return a.runJob("npm", "Installation des dépendances NPM", func(ctx context.Context) error {
+		return backend.NpmAction(ctx, path, all)
+	})
 }
 
 func (a *App) UpdateGitSubmodules(path string, submodules []string) error {
@@ -107,7 +129,9 @@ func (a *App) NpmUpdateAction(path string) error {
 
 // Expose DownloadBackupWithCreds to frontend
 func (a *App) DownloadBackupWithCreds(creds backend.S3Credentials, s3Path, destPath string) error {
-	return backend.DownloadBackupWithCreds(a.ctx, creds, s3Path, destPath)
+	return a.runJob("download", "Téléchargement de "+s3Path, func(ctx context.Context) error {
+		return backend.DownloadBackupWithCreds(ctx, creds, s3Path, destPath)
+	})
 }
 
 // Update operations
@@ -139,23 +163,57 @@ func (a *App) ListBackupsWithCreds(creds backend.S3Credentials, s3Dir string) ([
 	return backend.ListBackupsWithCreds(a.ctx, creds, s3Dir)
 }
 
+// ListBackupsPage retourne une page de backups S3 (pages suivantes via NextToken, dossiers avec query.Folders)
+func (a *App) ListBackupsPage(creds backend.S3Credentials, query backend.BackupListQuery) (*backend.BackupPage, error) {
+	return backend.ListBackupsPage(a.ctx, creds, query)
+}
+
+// StatS3Object retourne les métadonnées d'un objet S3
+func (a *App) StatS3Object(creds backend.S3Credentials, key string) (*backend.ObjectInfo, error) {
+	return backend.StatS3Object(a.ctx, creds, key)
+}
+
+// UploadS3Object envoie un fichier local sous key
+func (a *App) UploadS3Object(creds backend.S3Credentials, localPath, key string) error {
+	return a.runJob("upload", "Envoi de "+filepath.Base(localPath), func(ctx context.Context) error {
+		return backend.UploadS3Object(ctx, creds, localPath, key)
+	})
+}
+
+// DeleteS3Object supprime un objet S3
+func (a *App) DeleteS3Object(creds backend.S3Credentials, key string) error {
+	return backend.DeleteS3Object(a.ctx, creds, key)
+}
+
+// PresignS3Object génère un lien GET ou PUT vers key, valable expiresSeconds secondes
+// (20 minutes si 0, 7 jours au plus)
+func (a *App) PresignS3Object(creds backend.S3Credentials, key, method string, expiresSeconds int) (*backend.PresignedURL, error) {
+	return backend.PresignS3Object(a.ctx, creds, key, method, time.Duration(expiresSeconds)*time.Second)
+}
+
 // Expose RestoreMongoBackup to frontend
 func (a *App) RestoreMongoBackup(creds backend.S3Credentials, s3Path, mongoHost, mongoPort, mongoUser, mongoPassword string) error {
-	return backend.RestoreMongoBackup(a.ctx, creds, s3Path, mongoHost, mongoPort, mongoUser, mongoPassword)
+	return a.runJob("restore-mongo", "Restauration MongoDB de "+s3Path, func(ctx context.Context) error {
+		return backend.RestoreMongoBackup(ctx, creds, s3Path, mongoHost, mongoPort, mongoUser, mongoPassword)
+	})
 }
 
 // Expose RestoreS3Backup to frontend
 // wailsjs/go/main/App.d.ts doit être régénéré pour :
 // export function RestoreS3Backup(cloudCreds: backend.S3Credentials, localCreds: backend.S3Credentials, s3Path: string, s3Host: string, s3Port: string, s3Region: string, s3UseHttps: boolean): Promise<void>;
 func (a *App) RestoreS3Backup(cloudCreds backend.S3Credentials, localCreds backend.S3Credentials, s3Path, s3Host, s3Port, s3Region string, s3UseHttps bool) error {
-	return backend.RestoreS3Backup(a.ctx, cloudCreds, localCreds, s3Path, s3Host, s3Port, s3Region, s3UseHttps)
+	return a.runJob("restore-s3", "Restauration S3 de "+s3Path, func(ctx context.Context) error {
+		return backend.RestoreS3Backup(ctx, cloudCreds, localCreds, s3Path, s3Host, s3Port, s3Region, s3UseHttps)
+	})
 }
 
 // Expose RestoreS3BackupFromLocal to frontend
 // wailsjs/go/main/App.d.ts doit être régénéré pour :
 // export function RestoreS3BackupFromLocal(localCreds: backend.S3Credentials, localArchivePath: string, s3Host: string, s3Port: string, s3Region: string, s3UseHttps: boolean): Promise<void>;
 func (a *App) RestoreS3BackupFromLocal(localCreds backend.S3Credentials, localArchivePath, s3Host, s3Port, s3Region string, s3UseHttps bool) error {
-	return backend.RestoreS3BackupFromLocal(a.ctx, localCreds, localArchivePath, s3Host, s3Port, s3Region, s3UseHttps)
+	return a.runJob("restore-s3", "Restauration S3 de "+localArchivePath, func(ctx context.Context) error {
+		return backend.RestoreS3BackupFromLocal(ctx, localCreds, localArchivePath, s3Host, s3Port, s3Region, s3UseHttps)
+	})
 }
 
 // OpenS3BackupFileDialog ouvre un sélecteur de fichier pour les backups S3 locaux
@@ -182,12 +240,28 @@ func (a *App) ListMongoDatabases(mongoHost, mongoPort, mongoUser, mongoPassword
 	return backend.ListMongoDatabases(a.ctx, mongoHost, mongoPort, mongoUser, mongoPassword)
 }
 
+func (a *App) ListMongoDatabaseStats(mongoHost, mongoPort, mongoUser, mongoPassword string) ([]backend.MongoDatabaseStats, error) {
+	return backend.ListMongoDatabaseStats(a.ctx, mongoHost, mongoPort, mongoUser, mongoPassword)
+}
+
+func (a *App) TestMongoConnection(mongoHost, mongoPort, mongoUser, mongoPassword string) error {
+	return backend.TestMongoConnection(a.ctx, mongoHost, mongoPort, mongoUser, mongoPassword)
+}
+
 func (a *App) TransferMongoDatabase(sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database string, dropExisting bool) error {
-	return backend.TransferMongoDatabase(a.ctx, sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database, dropExisting)
+	return a.runJob("transfer-mongo", "Transfert MongoDB de "+database, func(ctx context.Context) error {
+		return backend.TransferMongoDatabase(ctx, sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database, dropExisting)
+	})
 }
 
 func (a *App) DumpMongoDatabase(mongoHost, mongoPort, mongoUser, mongoPassword, database string) (string, error) {
-	return backend.DumpMongoDatabase(a.ctx, mongoHost, mongoPort, mongoUser, mongoPassword, database)
+	var dumpPath string
+	err := a.runJob("dump-mongo", "Dump MongoDB de "+database, func(ctx context.Context) error {
+		var err error
+		dumpPath, err = backend.DumpMongoDatabase(ctx, mongoHost, mongoPort, mongoUser, mongoPassword, database)
+		return err
+	})
+	return dumpPath, err
 }
 
 // Expose MySQL functions to frontend
@@ -196,15 +270,25 @@ func (a *App) ListMySQLDatabases(mysqlHost, mysqlPort, mysqlUser, mysqlPassword
 }
 
 func (a *App) TransferMySQLDatabase(sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database string, dropExisting bool) error {
-	return backend.TransferMySQLDatabase(a.ctx, sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database, dropExisting)
+	return a.runJob("transfer-mysql", "Transfert MySQL de "+database, func(ctx context.Context) error {
+		return backend.TransferMySQLDatabase(ctx, sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database, dropExisting)
+	})
 }
 
 func (a *App) DumpMySQLDatabase(mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database string) (string, error) {
-	return backend.DumpMySQLDatabase(a.ctx, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database)
+	var dumpPath string
+	err := a.runJob("dump-mysql", "Dump MySQL de "+database, func(ctx context.Context) error {
+		var err error
+		dumpPath, err = backend.DumpMySQLDatabase(ctx, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database)
+		return err
+	})
+	return dumpPath, err
 }
 
 func (a *App) RestoreMySQLBackup(creds backend.S3Credentials, s3Path, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database string) error {
-	return backend.RestoreMySQLBackup(a.ctx, creds, s3Path, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database)
+	return a.runJob("restore-mysql", "Restauration MySQL de "+s3Path, func(ctx context.Context) error {
+		return backend.RestoreMySQLBackup(ctx, creds, s3Path, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database)
+	})
 }
 
 func (a *App) TestMySQLConnection(mysqlHost, mysqlPort, mysqlUser, mysqlPassword string) error {
@@ -217,13 +301,224 @@ func (a *App) ListPostgresDatabases(pgHost, pgPort, pgUser, pgPassword string) (
 }
 
 func (a *App) TransferPostgresDatabase(sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database string, dropExisting bool) error {
-	return backend.TransferPostgresDatabase(a.ctx, sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database, dropExisting)
+	return a.runJob("transfer-postgres", "Transfert PostgreSQL de "+database, func(ctx context.Context) error {
+		return backend.TransferPostgresDatabase(ctx, sourceHost, sourcePort, sourceUser, sourcePassword, destHost, destPort, destUser, destPassword, database, dropExisting)
+	})
 }
 
 func (a *App) DumpPostgresDatabase(pgHost, pgPort, pgUser, pgPassword, database string) (string, error) {
-	return backend.DumpPostgresDatabase(a.ctx, pgHost, pgPort, pgUser, pgPassword, database)
+	var dumpPath string
+	err := a.runJob("dump-postgres", "Dump PostgreSQL de "+database, func(ctx context.Context) error {
+		var err error
+		dumpPath, err = backend.DumpPostgresDatabase(ctx, pgHost, pgPort, pgUser, pgPassword, database)
+		return err
+	})
+	return dumpPath, err
 }
 
 func (a *App) RestorePostgresBackup(creds backend.S3Credentials, s3Path, pgHost, pgPort, pgUser, pgPassword, pgDatabase string) error {
-	return backend.RestorePostgresBackup(a.ctx, creds, s3Path, pgHost, pgPort, pgUser, pgPassword, pgDatabase)
+	return a.runJob("restore-postgres", "Restauration PostgreSQL de "+s3Path, func(ctx context.Context) error {
+		return backend.RestorePostgresBackup(ctx, creds, s3Path, pgHost, pgPort, pgUser, pgPassword, pgDatabase)
+	})
+}
+
+// BackupDatabase exporte une base d'un serveur enregistré (ID ou nom) vers le dépôt de backups S3
+func (a *App) BackupDatabase(serverID, database string) (*backend.BackupResult, error) {
+	profile, err := backend.GetServerProfile(serverID)
+	if err != nil {
+		return nil, err
+	}
+	var result *backend.BackupResult
+	err = a.runJob("backup-"+profile.Engine, fmt.Sprintf("Sauvegarde de %s (%s) vers S3", database, profile.Name), func(ctx context.Context) error {
+		var err error
+		result, err = backend.BackupDatabase(ctx, profile, database)
+		return err
+	})
+	return result, err
+}
+
+// SnapshotS3BucketWithCreds copie les objets d'un bucket (sous prefix si non vide) dans une
+// archive locale (destPath relatif au dossier Downloads) et retourne son chemin
+func (a *App) SnapshotS3BucketWithCreds(creds backend.S3Credentials, bucket, prefix, destPath string) (string, error) {
+	if bucket == "" {
+		bucket = creds.Bucket
+	}
+	var archivePath string
+	err := a.runJob("snapshot-s3", "Snapshot du bucket "+bucket, func(ctx context.Context) error {
+		var err error
+		archivePath, err = backend.SnapshotS3BucketWithCreds(ctx, creds, bucket, prefix, destPath)
+		return err
+	})
+	return archivePath, err
+}
+
+// BackupS3Bucket envoie le snapshot d'un bucket d'un serveur S3 enregistré (ID ou nom) vers le
+// dépôt de backups
+func (a *App) BackupS3Bucket(serverID, bucket, prefix string) (*backend.BackupResult, error) {
+	profile, err := backend.GetServerProfile(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if bucket == "" {
+		bucket = profile.Bucket
+	}
+	var result *backend.BackupResult
+	err = a.runJob("backup-s3", fmt.Sprintf("Snapshot de %s (%s) vers S3", bucket, profile.Name), func(ctx context.Context) error {
+		var err error
+		result, err = backend.BackupS3Bucket(ctx, profile, bucket, prefix)
+		return err
+	})
+	return result, err
+}
+
+// SyncS3 synchronise dst sur src (buckets de serveurs S3 enregistrés ou dossiers locaux) ; avec
+// opts.DryRun, le rapport liste les changements sans les appliquer
+func (a *App) SyncS3(src, dst backend.SyncEndpoint, opts backend.SyncOptions) (*backend.SyncReport, error) {
+	var report *backend.SyncReport
+	err := a.runJob("sync-s3", fmt.Sprintf("Synchronisation %s -> %s", src, dst), func(ctx context.Context) error {
+		var err error
+		report, err = backend.SyncS3(ctx, src, dst, opts)
+		return err
+	})
+	return report, err
+}
+
+// ListBackupCatalog liste les sauvegardes du dépôt de backups avec les métadonnées de leur manifeste
+func (a *App) ListBackupCatalog(filter backend.CatalogFilter) ([]backend.CatalogEntry, error) {
+	return backend.ListBackupCatalog(a.ctx, filter)
+}
+
+// VerifyBackup vérifie une sauvegarde du dépôt (empreinte, décompression et, si restoreServer
+// est renseigné, restauration dans une base jetable) et enregistre le rapport à côté d'elle
+func (a *App) VerifyBackup(key, restoreServer string) (*backend.VerifyReport, error) {
+	var report *backend.VerifyReport
+	err := a.runJob("verify", "Vérification de "+key, func(ctx context.Context) error {
+		var err error
+		report, err = backend.VerifyBackup(ctx, key, backend.VerifyOptions{RestoreServer: restoreServer})
+		return err
+	})
+	return report, err
+}
+
+// RestoreRepositoryBackup restaure une sauvegarde du dépôt (S3 ou dossier local) sur un serveur
+// enregistré ; database vide reprend la base d'origine
+func (a *App) RestoreRepositoryBackup(key, server, database string) error {
+	return a.runJob("restore", "Restauration de "+key, func(ctx context.Context) error {
+		return backend.RestoreRepositoryBackup(ctx, key, backend.RestoreOptions{Server: server, Database: database})
+	})
+}
+
+// GetArchiveStatusWithCreds indique si une sauvegarde archivée en stockage froid est lisible
+func (a *App) GetArchiveStatusWithCreds(creds backend.S3Credentials, s3Path string) (*backend.ArchiveStatus, error) {
+	return backend.GetArchiveStatusWithCreds(a.ctx, creds, s3Path)
+}
+
+// ThawBackupWithCreds demande la restauration d'une sauvegarde archivée ; avec opts.Wait, le
+// job attend que la sauvegarde soit lisible (annulable depuis la liste des opérations)
+func (a *App) ThawBackupWithCreds(creds backend.S3Credentials, s3Path string, opts backend.ThawOptions) (*backend.ArchiveStatus, error) {
+	var status *backend.ArchiveStatus
+	err := a.runJob("thaw", "Restauration depuis le stockage froid de "+s3Path, func(ctx context.Context) error {
+		var err error
+		status, err = backend.ThawBackupWithCreds(ctx, creds, s3Path, opts)
+		return err
+	})
+	return status, err
+}
+
+// ThawBackup demande la restauration d'une sauvegarde archivée du dépôt de backups
+func (a *App) ThawBackup(key string, opts backend.ThawOptions) (*backend.ArchiveStatus, error) {
+	var status *backend.ArchiveStatus
+	err := a.runJob("thaw", "Restauration depuis le stockage froid de "+key, func(ctx context.Context) error {
+		var err error
+		status, err = backend.ThawBackup(ctx, key, opts)
+		return err
+	})
+	return status, err
+}
+
+// Expose le store de profils serveurs (remplace les anciens stores localStorage du frontend)
+// ListServerProfiles retourne les profils avec leurs secrets résolus depuis le coffre
+func (a *App) ListServerProfiles(engine string) ([]backend.ServerProfile, error) {
+	profiles, err := backend.ListServerProfiles(engine)
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		if err := backend.ResolveServerSecrets(&profiles[i]); err != nil {
+			backend.Log.Warn(err.Error())
+		}
+	}
+	return profiles, nil
+}
+
+func (a *App) SaveServerProfile(profile backend.ServerProfile) (backend.ServerProfile, error) {
+	return backend.SaveServerProfile(profile)
+}
+
+func (a *App) DeleteServerProfile(id string) error {
+	return backend.DeleteServerProfile(id)
+}
+
+func (a *App) ReplaceServerProfiles(engine string, profiles []backend.ServerProfile) error {
+	return backend.ReplaceServerProfiles(engine, profiles)
+}
+
+// ImportLocalStorageProfiles migre le contenu d'une ancienne clé localStorage vers le store Go
+func (a *App) ImportLocalStorageProfiles(engine, raw string) (int, error) {
+	return backend.ImportLocalStorageProfiles(engine, raw)
+}
+
+func (a *App) GetBackupRepositoryServerID() string {
+	profile, err := backend.GetBackupRepositoryProfile()
+	if err != nil {
+		return ""
+	}
+	return profile.ID
+}
+
+func (a *App) SetBackupRepositoryServer(id string) error {
+	return backend.SetBackupRepositoryProfile(id)
+}
+
+// Expose le coffre de secrets au frontend (jamais les valeurs, seulement l'état)
+func (a *App) GetVaultStatus() backend.VaultStatus {
+	vault, err := backend.DefaultVault()
+	if err != nil {
+		return backend.VaultStatus{}
+	}
+	return vault.Status()
+}
+
+func (a *App) UnlockVault(passphrase string) error {
+	return backend.UnlockVault(passphrase)
+}
+
+// CleanWorkDir supprime les fichiers temporaires abandonnés du dossier de travail (tous avec all)
+func (a *App) CleanWorkDir(all bool) (*backend.CacheCleanReport, error) {
+	return backend.CleanWorkDir(all)
+}
+
+// Clés de chiffrement des sauvegardes (seuls les identifiants sont exposés)
+func (a *App) ListBackupKeys() ([]string, error) {
+	return backend.ListBackupKeys()
+}
+
+func (a *App) CreateBackupKey(id string) (string, error) {
+	return backend.CreateBackupKey(id)
+}
+
+// runJob exécute une opération longue via le gestionnaire de jobs et attend sa fin.
+// Le frontend peut suivre l'opération (ListJobs, événement "job-update") et l'annuler (CancelJob).
+func (a *App) runJob(kind, title string, fn backend.JobFunc) error {
+	return backend.Jobs.Run(a.ctx, kind, title, fn)
+}
+
+// ListJobs retourne les jobs en cours et terminés, les plus récents en premier
+func (a *App) ListJobs() []backend.JobInfo {
+	return backend.Jobs.List()
+}
+
+// CancelJob annule un job en cours
+func (a *App) CancelJob(id string) error {
+	return backend.Jobs.Cancel(id)
 }
@@ -0,0 +1,385 @@
+package backend
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Les archives (tar.gz, tar.zst) et la compression des dumps sont gérées en Go :
+// aucun binaire gzip, gunzip ou tar n'est nécessaire sur la machine.
+
+// Compression est l'algorithme de compression d'une archive ou d'un dump
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ErrUnsafeArchivePath est retourné quand une entrée d'archive sortirait du dossier d'extraction
+var ErrUnsafeArchivePath = errors.New("chemin d'archive non sûr")
+
+// ParseCompression convertit un nom de compression ("" vaut gzip)
+func ParseCompression(name string) (Compression, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "gzip", "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return CompressionZstd, nil
+	case "none":
+		return CompressionNone, nil
+	}
+	return CompressionGzip, fmt.Errorf("compression inconnue: %s (gzip, zstd ou none)", name)
+}
+
+// Extension retourne le suffixe de fichier d'une archive tar avec cette compression
+func (c Compression) Extension() string {
+	switch c {
+	case CompressionZstd:
+		return ".tar.zst"
+	case CompressionNone:
+		return ".tar"
+	}
+	return ".tar.gz"
+}
+
+// detectCompression lit les premiers octets de r sans les consommer
+func detectCompression(r *bufio.Reader) (Compression, error) {
+	head, err := r.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return CompressionNone, fmt.Errorf("erreur lecture de l'archive: %v", err)
+	}
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return CompressionGzip, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		return CompressionZstd, nil
+	}
+	return CompressionNone, nil
+}
+
+// newCompressWriter retourne un writer qui compresse vers w. Close doit être appelé
+// pour terminer le flux compressé (w n'est pas fermé).
+func newCompressWriter(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip:
+		gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return nil, fmt.Errorf("erreur compression gzip: %v", err)
+		}
+		return gz, nil
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("erreur compression zstd: %v", err)
+		}
+		return zw, nil
+	}
+	return nopWriteCloser{w}, nil
+}
+
+// newDecompressReader détecte la compression de r (gzip, zstd ou aucune) et la retire à la volée.
+// Une sauvegarde chiffrée est d'abord déchiffrée.
+func newDecompressReader(r io.Reader) (io.ReadCloser, Compression, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, 64*1024)
+	}
+	br, err := maybeDecrypt(br)
+	if err != nil {
+		return nil, CompressionNone, err
+	}
+	c, err := detectCompression(br)
+	if err != nil {
+		return nil, c, err
+	}
+	switch c {
+	case CompressionGzip:
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, c, fmt.Errorf("archive gzip invalide: %v", err)
+		}
+		return gz, c, nil
+	case CompressionZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, c, fmt.Errorf("archive zstd invalide: %v", err)
+		}
+		return zr.IOReadCloser(), c, nil
+	}
+	return io.NopCloser(br), c, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// CreateTarArchive archive le contenu de srcDir dans destPath (tar compressé avec c).
+// Les chemins sont relatifs à srcDir ; l'archive partielle est supprimée en cas d'erreur.
+func CreateTarArchive(ctx context.Context, srcDir, destPath string, c Compression) (err error) {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("erreur création de l'archive: %v", err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("erreur écriture de l'archive: %v", closeErr)
+		}
+		if err != nil {
+			os.Remove(destPath)
+		}
+	}()
+
+	cw, err := newCompressWriter(out, c)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(cw)
+
+	absDest, _ := filepath.Abs(destPath)
+	err = filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		// L'archive peut être créée dans le dossier archivé : elle ne doit pas s'inclure elle-même
+		if abs, _ := filepath.Abs(path); abs == absDest {
+			return nil
+		}
+		return addTarEntry(tw, path, filepath.ToSlash(rel), d)
+	})
+	if err != nil {
+		return fmt.Errorf("erreur création de l'archive: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("erreur écriture de l'archive: %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("erreur écriture de l'archive: %v", err)
+	}
+	return nil
+}
+
+func addTarEntry(tw *tar.Writer, path, name string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	} else if !info.Mode().IsRegular() && !info.IsDir() {
+		// Sockets, FIFO, périphériques : ignorés comme le ferait une sauvegarde de projet
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ExtractTarArchive extrait archivePath (tar, tar.gz ou tar.zst, détecté d'après son contenu)
+// dans destDir. Les entrées qui sortiraient de destDir (chemins absolus, "..", liens
+// pointant hors du dossier) sont refusées avec ErrUnsafeArchivePath.
+func ExtractTarArchive(ctx context.Context, archivePath, destDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("erreur ouverture de l'archive: %v", err)
+	}
+	defer f.Close()
+	return extractTar(ctx, f, destDir)
+}
+
+func extractTar(ctx context.Context, r io.Reader, destDir string) error {
+	dr, _, err := newDecompressReader(r)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	root, err := filepath.Abs(destDir)
+	if err != nil {
+		return fmt.Errorf("erreur extraction de l'archive: %v", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return fmt.Errorf("erreur création répertoire: %v", err)
+	}
+
+	tr := tar.NewReader(dr)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erreur extraction de l'archive: %v", err)
+		}
+		if err := extractTarEntry(tr, header, root); err != nil {
+			return fmt.Errorf("erreur extraction de %s: %w", header.Name, err)
+		}
+	}
+}
+
+func extractTarEntry(tr *tar.Reader, header *tar.Header, root string) error {
+	target, err := safeArchivePath(root, header.Name)
+	if err != nil {
+		return err
+	}
+	if target == root {
+		return nil
+	}
+	// Les vérifications de chemin sont lexicales : une entrée écrite à travers un lien extrait
+	// plus tôt pourrait sortir de root
+	if err := checkNoSymlinkParents(root, target); err != nil {
+		return err
+	}
+	mode := os.FileMode(header.Mode).Perm()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, mode|0o700)
+	case tar.TypeReg, tar.TypeRegA:
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		// Un lien existant sous ce nom est remplacé, jamais suivi
+		if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if err := os.Remove(target); err != nil {
+				return err
+			}
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0o600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	case tar.TypeSymlink:
+		// La cible est résolue depuis le dossier du lien et doit rester dans root
+		linkTarget := header.Linkname
+		if !filepath.IsAbs(linkTarget) {
+			linkTarget = filepath.Join(filepath.Dir(target), linkTarget)
+		}
+		if !withinDir(root, linkTarget) {
+			return fmt.Errorf("%w: lien vers %s", ErrUnsafeArchivePath, header.Linkname)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		os.Remove(target)
+		return os.Symlink(header.Linkname, target)
+	case tar.TypeLink:
+		source, err := safeArchivePath(root, header.Linkname)
+		if err != nil {
+			return err
+		}
+		if err := checkNoSymlinkParents(root, source); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		os.Remove(target)
+		return os.Link(source, target)
+	}
+	// Les autres types (périphériques, FIFO...) ne sont pas restaurés
+	Log.Debug(fmt.Sprintf("Entrée d'archive ignorée: %s (type %c)", header.Name, header.Typeflag))
+	return nil
+}
+
+// safeArchivePath retourne le chemin d'extraction de name, en refusant ce qui sort de root
+func safeArchivePath(root, name string) (string, error) {
+	cleaned := filepath.FromSlash(name)
+	if filepath.IsAbs(cleaned) || filepath.VolumeName(cleaned) != "" || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+	target := filepath.Join(root, cleaned)
+	if !withinDir(root, target) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+	return target, nil
+}
+
+// checkNoSymlinkParents refuse target si l'un de ses dossiers parents sous root est un lien
+// symbolique (qui a pu être créé par une entrée précédente de l'archive)
+func checkNoSymlinkParents(root, target string) error {
+	rel, err := filepath.Rel(root, filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return nil
+	}
+	dir := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			// Les dossiers manquants sont créés par l'extraction
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s passe par un lien symbolique", ErrUnsafeArchivePath, target)
+		}
+	}
+	return nil
+}
+
+func withinDir(root, path string) bool {
+	rel, err := filepath.Rel(root, filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,106 @@
+package backend
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// tarEntry décrit une entrée d'archive de test : un lien si Linkname est renseigné
+type tarEntry struct {
+	Name     string
+	Linkname string
+	Body     string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.Name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(e.Body))}
+		if e.Linkname != "" {
+			header = &tar.Header{Name: e.Name, Mode: 0o777, Typeflag: tar.TypeSymlink, Linkname: e.Linkname}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.Body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTarRefusesSymlinkChain(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("liens symboliques non disponibles sans privilèges")
+	}
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "dest")
+	archive := buildTar(t, []tarEntry{
+		{Name: "sub/l", Linkname: ".."},
+		{Name: "sub/l/l2", Linkname: ".."},
+		{Name: "sub/l/l2/evil", Body: "evil"},
+	})
+
+	err := extractTar(context.Background(), archive, dest)
+	if !errors.Is(err, ErrUnsafeArchivePath) {
+		t.Fatalf("extractTar: %v, attendu ErrUnsafeArchivePath", err)
+	}
+	for _, path := range []string{filepath.Join(parent, "evil"), filepath.Join(parent, "l2"), filepath.Join(dest, "l2")} {
+		if _, err := os.Lstat(path); err == nil {
+			t.Errorf("%s a été créé hors de l'arborescence attendue", path)
+		}
+	}
+}
+
+func TestExtractTarDoesNotWriteThroughSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("liens symboliques non disponibles sans privilèges")
+	}
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "dest")
+	outside := filepath.Join(parent, "outside.txt")
+	if err := os.WriteFile(outside, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	archive := buildTar(t, []tarEntry{
+		{Name: "sub/up", Linkname: ".."},
+		{Name: "link", Linkname: "sub/up/../outside.txt"},
+		{Name: "link", Body: "evil"},
+	})
+
+	// Le lien est accepté (cible lexicalement dans dest), mais le fichier le remplace sans le suivre
+	if err := extractTar(context.Background(), archive, dest); err != nil {
+		t.Fatalf("extractTar: %v", err)
+	}
+	if data, _ := os.ReadFile(outside); string(data) != "original" {
+		t.Errorf("fichier hors de dest modifié: %q", data)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "link")); err != nil || string(data) != "evil" {
+		t.Errorf("dest/link = %q, %v", data, err)
+	}
+}
+
+func TestExtractTarRegularArchive(t *testing.T) {
+	dest := t.TempDir()
+	archive := buildTar(t, []tarEntry{
+		{Name: "a/b/file.txt", Body: "contenu"},
+		{Name: "top.txt", Body: "racine"},
+	})
+	if err := extractTar(context.Background(), archive, dest); err != nil {
+		t.Fatalf("extractTar: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "a", "b", "file.txt")); err != nil || string(data) != "contenu" {
+		t.Errorf("a/b/file.txt = %q, %v", data, err)
+	}
+}
@@ -1,11 +1,10 @@
 package backend
 
 import (
+	"bufio"
 	"context"
 	"fmt"
-	"io"
 	"net"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,9 +13,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
@@ -32,17 +28,29 @@ type S3Credentials struct {
 	Region    string `json:"region"`
 	UseHttps  bool   `json:"useHttps"`
 	Bucket    string `json:"bucket"`
+	// SessionToken accompagne des clés temporaires (STS)
+	SessionToken string `json:"sessionToken,omitempty"`
+	// Profile est un profil AWS (~/.aws/config) utilisé quand AccessKey est vide
+	Profile string `json:"profile,omitempty"`
+	// VirtualHost adresse les buckets en bucket.host au lieu de host/bucket
+	VirtualHost bool `json:"virtualHost,omitempty"`
+	// CABundle est un fichier PEM d'autorités de certification supplémentaires
+	CABundle string `json:"caBundle,omitempty"`
 }
 
 func resolveS3Config(creds S3Credentials) (bucket string, region string, endpoint string) {
+	defaults := CurrentConfig().Backup.S3
+	RegisterSecret(creds.SecretKey)
+
 	bucket = strings.TrimSpace(creds.Bucket)
 	if bucket == "" {
-		bucket = "backup-global"
+		bucket = defaults.Bucket
 	}
 
 	host := strings.TrimSpace(creds.Host)
-	if host == "" {
-		host = S3BaseURL
+	usingDefaultHost := host == ""
+	if usingDefaultHost {
+		host = defaults.Host
 	}
 	host = strings.TrimPrefix(host, "http://")
 	host = strings.TrimPrefix(host, "https://")
@@ -57,17 +65,20 @@ func resolveS3Config(creds S3Credentials) (bucket string, region string, endpoin
 
 	region = strings.TrimSpace(creds.Region)
 	if region == "" {
-		region = S3Region
+		region = defaults.Region
 	}
 
 	port := strings.TrimSpace(creds.Port)
+	if port == "" && usingDefaultHost {
+		port = defaults.Port
+	}
 	if port == "" {
 		port = hostPort
 	}
 
 	useHttps := creds.UseHttps
-	if creds.Host == "" && port == "" {
-		useHttps = true
+	if usingDefaultHost {
+		useHttps = defaults.UseHttps == nil || *defaults.UseHttps
 	}
 	protocol := "https"
 	if !useHttps {
@@ -82,6 +93,11 @@ func resolveS3Config(creds S3Credentials) (bucket string, region string, endpoin
 		port = ""
 	}
 
+	// Pour AWS (ou sans hôte), le SDK résout lui-même le point d'accès de la région
+	if hostOnly == "" || strings.HasSuffix(hostOnly, "amazonaws.com") {
+		return bucket, region, ""
+	}
+
 	endpointHost := hostOnly
 	if port != "" {
 		endpointHost = net.JoinHostPort(hostOnly, port)
@@ -91,237 +107,170 @@ func resolveS3Config(creds S3Credentials) (bucket string, region string, endpoin
 	return bucket, region, endpoint
 }
 
-// BackupInfo structure for frontend (nom, taille, date)
+// BackupInfo structure for frontend (nom, taille, date, classe de stockage)
 type BackupInfo struct {
-	Name         string `json:"name"`
-	Size         int64  `json:"size"`
-	LastModified string `json:"lastModified"`
+	Name         string    `json:"name"`
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	StorageClass string    `json:"storageClass"`
+	// Archived indique une classe froide (GLACIER, DEEP_ARCHIVE) : la sauvegarde doit être
+	// restaurée (ThawBackupWithCreds) avant d'être téléchargée
+	Archived bool `json:"archived"`
 }
 
-// ListBackupsWithCreds liste les backups S3 avec infos (nom, taille, date) - exclut les backups Glacier
-func ListBackupsWithCreds(ctx context.Context, creds S3Credentials, s3Dir string) ([]BackupInfo, error) {
-	bucket, region, endpoint := resolveS3Config(creds)
-	prefix := s3Dir
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKey, creds.SecretKey, "")),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("erreur chargement config AWS: %v", err)
-	}
-	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
-		o.UsePathStyle = true
-	})
-	var files []BackupInfo
-
-	// Utilise ListObjectsV2 avec tri par date de modification (plus récent en premier)
-	input := &s3.ListObjectsV2Input{
-		Bucket: &bucket,
-		Prefix: &prefix,
-		// Note: AWS S3 ne supporte pas le filtrage par StorageClass dans ListObjectsV2
-		// mais on peut optimiser en récupérant les métadonnées seulement pour les objets standards
-	}
-
-	paginator := s3.NewListObjectsV2Paginator(client, input)
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("erreur lors du listing S3: %v", err)
-		}
-		for _, obj := range page.Contents {
-			if !strings.HasSuffix(*obj.Key, "/") {
-				// Vérification rapide de la classe de stockage
-				storageClass := string(obj.StorageClass)
-
-				// Filtre directement sans logging excessif
-				isStandard := storageClass == "" || storageClass == "STANDARD"
-
-				if isStandard {
-					files = append(files, BackupInfo{
-						Name:         lastPathPart(*obj.Key),
-						Size:         derefInt64(obj.Size),
-						LastModified: obj.LastModified.Format("2006-01-02 15:04:05"),
-					})
-				}
-			}
-		}
-	}
-
-	// Trie les backups du plus récent au plus ancien
-	sort.Slice(files, func(i, j int) bool {
-		// Parse les dates pour pouvoir les comparer
-		timeI, errI := time.Parse("2006-01-02 15:04:05", files[i].LastModified)
-		timeJ, errJ := time.Parse("2006-01-02 15:04:05", files[j].LastModified)
-
-		// Si une des dates ne peut pas être parsée, utilise l'ordre alphabétique inverse
-		if errI != nil || errJ != nil {
-			return files[i].LastModified > files[j].LastModified
-		}
+// Taille des pages de listing (S3 ne retourne pas plus de 1000 clés par requête)
+const (
+	defaultBackupPageSize = 100
+	maxBackupPageSize     = 1000
+)
 
-		// Retourne true si timeI est plus récent que timeJ (ordre décroissant)
-		return timeI.After(timeJ)
-	})
+// BackupListQuery décrit une page de listing S3. Les clés sont retournées dans l'ordre
+// lexicographique de S3 ; Prefix et StartAfter sont appliqués par S3, Contains sur la page reçue.
+type BackupListQuery struct {
+	Prefix string `json:"prefix"`
+	// Folders liste un seul niveau : les objets placés directement sous Prefix, et les
+	// sous-dossiers dans BackupPage.Folders
+	Folders bool `json:"folders"`
+	// Delimiter remplace le séparateur "/" des dossiers (implique Folders)
+	Delimiter string `json:"delimiter"`
+	// StartAfter commence le listing après cette clé (ex: <prefix>/20240101 pour ignorer les
+	// sauvegardes horodatées plus anciennes)
+	StartAfter string `json:"startAfter"`
+	// ContinuationToken est le NextToken de la page précédente
+	ContinuationToken string `json:"continuationToken"`
+	PageSize          int    `json:"pageSize"`
+	// Contains ne garde que les objets dont le nom contient cette chaîne
+	Contains string `json:"contains"`
+}
 
-	return files, nil
+// BackupPage est une page de listing S3
+type BackupPage struct {
+	Items   []BackupInfo `json:"items"`
+	Folders []string     `json:"folders"`
+	// NextToken est vide sur la dernière page
+	NextToken string `json:"nextToken"`
 }
 
-// ListBackupsWithCredsPaged liste les backups S3 paginés (10 par page)
-func ListBackupsWithCredsPaged(ctx context.Context, creds S3Credentials, s3Dir string, page int, pageSize int) ([]BackupInfo, int, error) {
-	all, err := ListBackupsWithCreds(ctx, creds, s3Dir)
+// ListBackupsPage retourne une page de backups S3. Les pages suivantes sont obtenues en
+// repassant NextToken dans query.ContinuationToken.
+func ListBackupsPage(ctx context.Context, creds S3Credentials, query BackupListQuery) (*BackupPage, error) {
+	client, bucket, err := newS3Client(ctx, creds)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-	total := len(all)
+	return listBackupsPage(ctx, client, bucket, query)
+}
+
+func listBackupsPage(ctx context.Context, client *s3.Client, bucket string, query BackupListQuery) (*BackupPage, error) {
+	pageSize := query.PageSize
 	if pageSize <= 0 {
-		pageSize = 10
+		pageSize = defaultBackupPageSize
 	}
-	if page <= 0 {
-		page = 1
+	if pageSize > maxBackupPageSize {
+		pageSize = maxBackupPageSize
 	}
-	start := (page - 1) * pageSize
-	if start > total {
-		return []BackupInfo{}, total, nil
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		Prefix:  aws.String(query.Prefix),
+		MaxKeys: aws.Int32(int32(pageSize)),
 	}
-	end := start + pageSize
-	if end > total {
-		end = total
+	switch {
+	case query.Delimiter != "":
+		input.Delimiter = aws.String(query.Delimiter)
+	case query.Folders:
+		input.Delimiter = aws.String("/")
+	}
+	if query.StartAfter != "" {
+		input.StartAfter = aws.String(query.StartAfter)
+	}
+	if query.ContinuationToken != "" {
+		input.ContinuationToken = aws.String(query.ContinuationToken)
 	}
-	return all[start:end], total, nil
-}
-
-func lastPathPart(path string) string {
-	parts := strings.Split(path, "/")
-	return parts[len(parts)-1]
-}
 
-// generatePresignedURL génère une URL de téléchargement temporaire S3 (20 minutes)
-func generatePresignedURL(ctx context.Context, client *s3.Client, bucket, key string) (string, error) {
-	presigner := s3.NewPresignClient(client)
-	presignInput := &s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &key,
-	}
-	presigned, err := presigner.PresignGetObject(ctx, presignInput, func(opts *s3.PresignOptions) {
-		// Utiliser une durée de validité de 20 minutes comme demandé
-		opts.Expires = 20 * time.Minute
-	})
+	out, err := client.ListObjectsV2(ctx, input)
 	if err != nil {
-		return "", fmt.Errorf("erreur génération presigned URL: %v", err)
+		return nil, fmt.Errorf("erreur lors du listing S3: %v", err)
 	}
-	return presigned.URL, nil
-}
-
-// Télécharge une URL HTTP avec retry et timeout long
-func downloadWithRetry(url string, maxAttempts int, timeout time.Duration) (io.ReadCloser, error) {
-	var lastErr error
-
-	// Utilise un client HTTP avec des timeouts plus longs et des paramètres optimisés pour les gros fichiers
-	client := &http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 100,
-			IdleConnTimeout:     90 * time.Second,
-			// Augmente les timeouts pour les gros fichiers
-			ResponseHeaderTimeout: 30 * time.Second,
-			TLSHandshakeTimeout:   20 * time.Second,
-			// Permet plus de temps pour établir une connexion
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-		},
-	}
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		LogToFrontend("debug", fmt.Sprintf("Tentative de téléchargement #%d/%d", attempt, maxAttempts))
-
-		// Crée une requête pour pouvoir personnaliser les headers
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			lastErr = err
+	page := &BackupPage{Items: []BackupInfo{}, Folders: []string{}}
+	for _, obj := range out.Contents {
+		key := aws.ToString(obj.Key)
+		name := lastPathPart(key)
+		// Les marqueurs de dossier ("photos/") ne sont pas des backups
+		if strings.HasSuffix(key, "/") || !strings.Contains(name, query.Contains) {
 			continue
 		}
+		page.Items = append(page.Items, BackupInfo{
+			Name:         name,
+			Key:          key,
+			Size:         derefInt64(obj.Size),
+			LastModified: aws.ToTime(obj.LastModified),
+			StorageClass: storageClassLabel(string(obj.StorageClass)),
+			Archived:     isArchivedStorageClass(string(obj.StorageClass)),
+		})
+	}
+	for _, p := range out.CommonPrefixes {
+		page.Folders = append(page.Folders, aws.ToString(p.Prefix))
+	}
+	if aws.ToBool(out.IsTruncated) {
+		page.NextToken = aws.ToString(out.NextContinuationToken)
+	}
+	return page, nil
+}
 
-		// Ajoute des headers pour optimiser le téléchargement
-		req.Header.Set("Connection", "keep-alive")
-
-		// Exécute la requête
-		resp, err := client.Do(req)
-
-		if err == nil && resp.StatusCode == 200 {
-			LogToFrontend("debug", "Connexion établie avec succès, début du téléchargement")
-			return resp.Body, nil
+// ListBackupsWithCreds liste tous les backups S3 sous s3Dir (y compris ceux archivés en
+// stockage froid), du plus récent au plus ancien
+func ListBackupsWithCreds(ctx context.Context, creds S3Credentials, s3Dir string) ([]BackupInfo, error) {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	files := []BackupInfo{}
+	query := BackupListQuery{Prefix: s3Dir, PageSize: maxBackupPageSize}
+	for {
+		page, err := listBackupsPage(ctx, client, bucket, query)
+		if err != nil {
+			return nil, err
 		}
-
-		// Gestion des erreurs
-		if resp != nil {
-			statusText := resp.Status
-			resp.Body.Close()
-			LogToFrontend("warn", fmt.Sprintf("Échec tentative #%d: HTTP status %s", attempt, statusText))
-			lastErr = fmt.Errorf("HTTP status: %s", statusText)
-		} else if err != nil {
-			LogToFrontend("warn", fmt.Sprintf("Échec tentative #%d: %v", attempt, err))
-			lastErr = err
+		files = append(files, page.Items...)
+		if page.NextToken == "" {
+			break
 		}
-
-		// Pause exponentielle entre les tentatives
-		backoffTime := time.Duration(attempt*attempt) * 2 * time.Second
-		LogToFrontend("debug", fmt.Sprintf("Attente de %v avant la prochaine tentative", backoffTime))
-		time.Sleep(backoffTime)
+		query.ContinuationToken = page.NextToken
 	}
 
-	return nil, fmt.Errorf("échec téléchargement après %d tentatives: %v", maxAttempts, lastErr)
+	sort.Slice(files, func(i, j int) bool { return files[i].LastModified.After(files[j].LastModified) })
+	return files, nil
+}
+
+func lastPathPart(path string) string {
+	parts := strings.Split(path, "/")
+	return parts[len(parts)-1]
 }
 
 // DownloadBackupWithCreds télécharge un backup S3 avec credentials fournis (bucket privé, signature S3 via AWS SDK)
 func DownloadBackupWithCreds(ctx context.Context, creds S3Credentials, s3Path, destPath string) error {
-	bucket, region, endpoint := resolveS3Config(creds)
-	objectName := s3Path
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKey, creds.SecretKey, "")),
-	)
+	client, bucket, err := newS3Client(ctx, creds)
 	if err != nil {
-		return fmt.Errorf("erreur chargement config AWS: %v", err)
-	}
-	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
-		o.UsePathStyle = true
-	})
-	getObjInput := &s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &objectName,
+		return err
 	}
-	resp, err := client.GetObject(ctx, getObjInput)
+	downloadPath, err := resolveDownloadPath(destPath)
 	if err != nil {
-		return fmt.Errorf("erreur téléchargement S3: %v", err)
-	}
-	defer resp.Body.Close()
-
-	downloadPath := destPath
-	if !filepath.IsAbs(destPath) {
-		downloadsDir, err := getUserDownloadsDir()
-		if err != nil {
-			return fmt.Errorf("erreur récupération dossier Downloads: %v", err)
-		}
-		downloadPath = filepath.Join(downloadsDir, destPath)
-	}
-	if err := os.MkdirAll(filepath.Dir(downloadPath), 0o755); err != nil {
-		return fmt.Errorf("erreur création dossier de destination: %v", err)
+		return err
 	}
+	return downloadS3Object(ctx, client, bucket, s3Path, downloadPath)
+}
 
-	f, err := os.Create(downloadPath)
-	if err != nil {
-		return fmt.Errorf("erreur création fichier: %v", err)
+// resolveDownloadPath place un chemin relatif dans le dossier Downloads de l'utilisateur
+func resolveDownloadPath(destPath string) (string, error) {
+	if filepath.IsAbs(destPath) {
+		return destPath, nil
 	}
-	defer f.Close()
-	_, err = io.Copy(f, resp.Body)
+	downloadsDir, err := getUserDownloadsDir()
 	if err != nil {
-		return fmt.Errorf("erreur écriture fichier: %v", err)
+		return "", fmt.Errorf("erreur récupération dossier Downloads: %v", err)
 	}
-	return nil
+	return filepath.Join(downloadsDir, destPath), nil
 }
 
 // getUserDownloadsDir retourne le dossier Downloads de l'utilisateur (Windows/macOS/Linux)
@@ -337,319 +286,125 @@ func getUserDownloadsDir() (string, error) {
 	return downloadsDir, nil
 }
 
-// getUserTmpDir retourne un dossier temporaire sécurisé compatible avec tous les OS
-func getUserTmpDir() (string, error) {
-	downloadsDir, err := getUserDownloadsDir()
-	if err != nil {
-		return "", err
-	}
-
-	// Crée un sous-dossier spécifique à l'application pour éviter les conflits
-	appTmpDir := filepath.Join(downloadsDir, "aidalinfo-cli-tmp")
-	
-	// Vérifie si le dossier existe, sinon le crée
-	if _, err := os.Stat(appTmpDir); os.IsNotExist(err) {
-		err = os.MkdirAll(appTmpDir, 0o755)
-		if err != nil {
-			return "", fmt.Errorf("erreur création dossier tmp: %v", err)
-		}
-	}
-	
-	return appTmpDir, nil
-}
-
 // RestoreMongoBackup télécharge un backup S3 et le restaure dans MongoDB
 func RestoreMongoBackup(ctx context.Context, creds S3Credentials, s3Path string, mongoHost, mongoPort, mongoUser, mongoPassword string) error {
-	bucket, region, endpoint := resolveS3Config(creds)
-	objectName := s3Path
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKey, creds.SecretKey, "")),
-	)
-	if err != nil {
-		return fmt.Errorf("erreur chargement config AWS: %v", err)
-	}
-	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
-		o.UsePathStyle = true
-	})
-	presignedURL, err := generatePresignedURL(ctx, client, bucket, objectName)
+	storage, err := newS3Storage(ctx, creds)
 	if err != nil {
 		return err
 	}
+	return restoreMongoFromStorage(ctx, storage, s3Path, mongoHost, mongoPort, mongoUser, mongoPassword)
+}
 
-	// Récupère la taille du fichier pour la progression
-	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &objectName})
-	var totalSize int64 = 0
-	if err == nil && head.ContentLength != nil {
-		totalSize = *head.ContentLength
-		LogToFrontend("info", fmt.Sprintf("Taille du backup à télécharger: %.2f MB", float64(totalSize)/(1024*1024)))
+// mongoArchivePath retourne le fichier local de la sauvegarde key : le fichier lui-même pour un
+// dépôt local, sinon un téléchargement repris au prochain lancement s'il est interrompu
+func mongoArchivePath(ctx context.Context, storage BackupStorage, key string) (string, error) {
+	if local, ok := storage.(*localStorage); ok {
+		return local.path(key)
 	}
-
-	respBody, err := downloadWithRetry(presignedURL, 3, 30*time.Minute)
+	tmpDir, err := getWorkDir()
 	if err != nil {
-		return fmt.Errorf("erreur téléchargement HTTP: %v", err)
+		return "", err
+	}
+	archivePath := resumableDownloadPath(tmpDir, storage.String(), key)
+	Log.Info("Début du téléchargement du backup MongoDB...")
+	if err := storage.Download(ctx, key, archivePath); err != nil {
+		return "", err
 	}
-	defer respBody.Close()
+	Log.Success("Téléchargement du backup MongoDB terminé.")
+	return archivePath, nil
+}
 
-	tmpDir, err := getUserTmpDir()
+// restoreMongoFromStorage télécharge la sauvegarde key de storage et la restaure avec
+// mongorestore ; extraArgs complète la commande (--nsFrom/--nsTo pour renommer la base)
+func restoreMongoFromStorage(ctx context.Context, storage BackupStorage, objectName string, mongoHost, mongoPort, mongoUser, mongoPassword string, extraArgs ...string) error {
+	archivePath, err := mongoArchivePath(ctx, storage, objectName)
 	if err != nil {
 		return err
 	}
-	tmpFile, err := os.CreateTemp(tmpDir, "mongo-backup-*.bson.gz")
-	if err != nil {
-		return fmt.Errorf("erreur création fichier temporaire: %v", err)
-	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
-
-	// Progression du téléchargement
-	progressReader := &progressReaderWithLog{
-		r:     respBody,
-		total: totalSize,
-	}
-	LogToFrontend("info", "Début du téléchargement du backup MongoDB...")
-	_, err = io.Copy(tmpFile, progressReader)
-	if err != nil {
-		return fmt.Errorf("erreur écriture fichier: %v", err)
+	if _, ok := storage.(*localStorage); !ok {
+		defer os.Remove(archivePath)
 	}
-	LogToFrontend("success", "Téléchargement du backup MongoDB terminé.")
 
-	LogToFrontend("debug", fmt.Sprintf("mongoHost=%s, mongoPort=%s, mongoUser=%s, mongoPassword=%s", mongoHost, mongoPort, mongoUser, mongoPassword))
+	Log.Debug(fmt.Sprintf("mongoHost=%s, mongoPort=%s, mongoUser=%s", mongoHost, mongoPort, mongoUser))
 
 	// Prépare la commande mongorestore
-	args := []string{"--gzip", "--archive=" + tmpFile.Name(), "--host", mongoHost, "--port", mongoPort}
-	if mongoUser != "" {
-		args = append(args, "--username", mongoUser)
-		// Ajouter la base d'authentification (par défaut admin)
-		args = append(args, "--authenticationDatabase", "admin")
+	connArgs, cleanup, err := mongoToolArgs(mongoHost, mongoPort, mongoUser, mongoPassword)
+	if err != nil {
+		return err
 	}
-	if mongoPassword != "" {
-		args = append(args, "--password", mongoPassword)
+	defer cleanup()
+	// L'archive est lue sur l'entrée standard pour suivre la progression de la restauration
+	args := append(append([]string{"--gzip", "--archive"}, extraArgs...), connArgs...)
+	archive, err := openFileWithProgress(ctx, archivePath, PhaseRestore, objectName)
+	if err != nil {
+		return err
 	}
-	LogToFrontend("info", "Début de la restauration mongorestore...")
-	cmd := exec.Command("mongorestore", args...)
+	defer archive.Close()
+	// Une sauvegarde chiffrée est déchiffrée à la volée avant mongorestore
+	stdin, err := maybeDecrypt(bufio.NewReaderSize(archive, 64*1024))
+	if err != nil {
+		return err
+	}
+	Log.Info("Début de la restauration mongorestore...")
+	cmd := exec.CommandContext(ctx, "mongorestore", args...)
+	cmd.Stdin = stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	LogToFrontend("debug", fmt.Sprintf("mongorestore args: %v", args))
+	Log.Debug(fmt.Sprintf("mongorestore args: %v", args))
 	if err := cmd.Run(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("mongorestore error: %v", err))
+		Log.Error(fmt.Sprintf("mongorestore error: %v", err))
 		return fmt.Errorf("erreur restauration mongorestore: %v", err)
 	}
-	LogToFrontend("success", "Restauration mongorestore terminée avec succès.")
+	archive.Finish()
+	Log.Success("Restauration mongorestore terminée avec succès.")
 	return nil
 }
 
 // RestoreS3Backup télécharge un backup S3 (tar.gz) et le restaure dans un S3 local (MinIO ou autre)
 func RestoreS3Backup(ctx context.Context, cloudCreds S3Credentials, localCreds S3Credentials, s3Path, s3Host, s3Port, s3Region string, s3UseHttps bool) error {
-	bucket, region, endpoint := resolveS3Config(cloudCreds)
-	objectName := s3Path
-
-	LogToFrontend("debug", "RestoreS3Backup: Début de la restauration S3")
-	LogToFrontend("debug", fmt.Sprintf("Paramètres: bucket=%s, objectName=%s, s3Host=%s, s3Port=%s", bucket, objectName, s3Host, s3Port))
-
 	// Utilise les credentials cloud pour télécharger le backup
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cloudCreds.AccessKey, cloudCreds.SecretKey, "")),
-	)
+	storage, err := newS3Storage(ctx, cloudCreds)
 	if err != nil {
-		return fmt.Errorf("erreur chargement config AWS: %v", err)
+		return err
 	}
-	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
-		o.UsePathStyle = true
-	})
+	objectName := s3Path
 
-	// Récupère la taille du fichier avant de commencer
-	var totalSize int64 = 0
-	if head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &objectName}); err == nil && head.ContentLength != nil {
-		totalSize = *head.ContentLength
-		LogToFrontend("info", fmt.Sprintf("Taille du fichier à télécharger: %.2f MB", float64(totalSize)/(1024*1024)))
-	} else {
-		LogToFrontend("warn", fmt.Sprintf("Impossible de récupérer la taille du fichier: %v", err))
-	}
+	Log.Debug("RestoreS3Backup: Début de la restauration S3")
+	Log.Debug(fmt.Sprintf("Paramètres: bucket=%s, objectName=%s, s3Host=%s, s3Port=%s", storage.bucket, objectName, s3Host, s3Port))
 
-	LogToFrontend("debug", "Génération de l'URL présignée...")
-	presignedURL, err := generatePresignedURL(ctx, client, bucket, objectName)
+	tmpDir, err := getWorkDir()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur génération URL présignée: %v", err))
+		Log.Error(fmt.Sprintf("Erreur récupération dossier tmp: %v", err))
 		return err
 	}
-	LogToFrontend("debug", "URL présignée générée avec succès (valide 12 heures)")
+	Log.Debug(fmt.Sprintf("Dossier temporaire: %s", tmpDir))
 
-	// Prépare le fichier temporaire avant de commencer le téléchargement
-	tmpDir, err := getUserTmpDir()
-	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur récupération dossier tmp: %v", err))
-		return err
+	// L'archive téléchargée puis extraite occupe au moins deux fois sa taille
+	if obj, err := storage.Stat(ctx, objectName); err == nil {
+		if err := ensureFreeSpace(tmpDir, 2*obj.Size, "la restauration de "+objectName); err != nil {
+			return err
+		}
 	}
-	LogToFrontend("debug", fmt.Sprintf("Dossier temporaire: %s", tmpDir))
 
-	tmpFile, err := os.CreateTemp(tmpDir, "s3-backup-*.tar.gz")
-	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur création fichier temporaire: %v", err))
-		return fmt.Errorf("erreur création fichier temporaire: %v", err)
+	// Un téléchargement interrompu est conservé et repris au prochain lancement
+	tmpFilePath := resumableDownloadPath(tmpDir, storage.String(), objectName)
+	Log.Info("Début du téléchargement, cela peut prendre plusieurs minutes...")
+	if err := storage.Download(ctx, objectName, tmpFilePath); err != nil {
+		Log.Error(fmt.Sprintf("ERREUR téléchargement: %v", err))
+		return fmt.Errorf("erreur téléchargement: %w", err)
 	}
-	tmpFilePath := tmpFile.Name()
-	LogToFrontend("debug", fmt.Sprintf("Fichier temporaire créé: %s", tmpFilePath))
+	Log.Success("Téléchargement terminé avec succès!")
 
-	// Ferme le fichier pour le rouvrir en mode append plus tard
-	tmpFile.Close()
-
-	// Vérification de l'espace disque disponible
-	df, err := exec.Command("df", "-h", tmpDir).Output()
-	if err == nil {
-		LogToFrontend("debug", fmt.Sprintf("Espace disque disponible: %s", string(df)))
-	}
-
-	LogToFrontend("info", "Début du téléchargement, cela peut prendre plusieurs minutes...")
-
-	// Utilise un context avec timeout plus long pour les gros fichiers
-	copyCtx, cancel := context.WithTimeout(ctx, 4*time.Hour)
-	defer cancel()
-
-	// Canal pour récupérer le résultat du téléchargement
-	type downloadResult struct {
-		written int64
-		err     error
-	}
-	resultChan := make(chan downloadResult, 1)
-
-	// Lance le téléchargement dans une goroutine avec gestion avancée
-	go func() {
-		var totalWritten int64 = 0
-		var downloadErr error
-
-		// Nombre maximum de tentatives pour le téléchargement complet
-		maxAttempts := 5
-		for attempt := 1; attempt <= maxAttempts; attempt++ {
-			if attempt > 1 {
-				LogToFrontend("warn", fmt.Sprintf("Tentative #%d de reprise du téléchargement...", attempt))
-				// Regenere un nouveau lien présigné pour chaque nouvelle tentative
-				presignedURL, err = generatePresignedURL(ctx, client, bucket, objectName)
-				if err != nil {
-					downloadErr = fmt.Errorf("erreur regénération URL présignée: %v", err)
-					break
-				}
-			}
-
-			// Ouvre le fichier en mode append pour reprendre le téléchargement
-			tmpFile, err = os.OpenFile(tmpFilePath, os.O_WRONLY|os.O_APPEND, 0644)
-			if err != nil {
-				downloadErr = fmt.Errorf("erreur ouverture fichier temporaire: %v", err)
-				break
-			}
-
-			// Si nous avons déjà téléchargé une partie, utilisons un Range request
-			var respBody io.ReadCloser
-			if totalWritten > 0 {
-				// Crée une requête avec Range header
-				req, err := http.NewRequest("GET", presignedURL, nil)
-				if err != nil {
-					tmpFile.Close()
-					downloadErr = fmt.Errorf("erreur création requête: %v", err)
-					break
-				}
-
-				// Spécifie à partir d'où reprendre le téléchargement
-				req.Header.Set("Range", fmt.Sprintf("bytes=%d-", totalWritten))
-				LogToFrontend("info", fmt.Sprintf("Reprise du téléchargement à partir de %.2f MB", float64(totalWritten)/(1024*1024)))
-
-				// Utilise un client HTTP avec timeout long
-				client := &http.Client{Timeout: 2 * time.Hour}
-				resp, err := client.Do(req)
-				if err != nil || (resp.StatusCode != 200 && resp.StatusCode != 206) {
-					statusText := "erreur"
-					if resp != nil {
-						statusText = resp.Status
-						resp.Body.Close()
-					}
-					tmpFile.Close()
-					downloadErr = fmt.Errorf("erreur reprise téléchargement: %v, status: %s", err, statusText)
-					// Attente avant nouvelle tentative
-					time.Sleep(5 * time.Second)
-					continue
-				}
-				respBody = resp.Body
-			} else {
-				// Premier téléchargement
-				respBody, err = downloadWithRetry(presignedURL, 3, 2*time.Hour)
-				if err != nil {
-					tmpFile.Close()
-					downloadErr = fmt.Errorf("erreur téléchargement HTTP: %v", err)
-					// Attente avant nouvelle tentative
-					time.Sleep(5 * time.Second)
-					continue
-				}
-			}
-
-			// Configure le lecteur avec suivi de progression
-			progressReader := &progressReaderWithLog{
-				r:          respBody,
-				total:      totalSize,
-				read:       0,
-				last:       0,
-				lastUpdate: time.Time{},
-				lastLog:    time.Time{},
-			}
-
-			// Copie les données
-			written, err := io.Copy(tmpFile, progressReader)
-			respBody.Close()
-			tmpFile.Close()
-
-			if err != nil {
-				LogToFrontend("warn", fmt.Sprintf("Erreur pendant le téléchargement: %v (écrit %.2f MB)",
-					err, float64(totalWritten+written)/(1024*1024)))
-				// Enregistre ce qui a été écrit jusqu'à présent
-				totalWritten += written
-				if attempt == maxAttempts {
-					downloadErr = fmt.Errorf("erreur téléchargement après %d tentatives: %v", maxAttempts, err)
-				}
-				// Attente avant nouvelle tentative
-				time.Sleep(5 * time.Second)
-				continue
-			}
-
-			// Téléchargement réussi
-			totalWritten += written
-			LogToFrontend("success", fmt.Sprintf("Téléchargement terminé avec succès! Écrit: %.2f MB", float64(totalWritten)/(1024*1024)))
-			break
+	defer func() {
+		if err := os.Remove(tmpFilePath); err != nil {
+			Log.Warn(fmt.Sprintf("Impossible de supprimer le fichier temporaire: %v", err))
 		}
-
-		resultChan <- downloadResult{written: totalWritten, err: downloadErr}
 	}()
-
-	// Attend le résultat ou le timeout
-	var written int64
-	var downloadErr error
-	select {
-	case result := <-resultChan:
-		written = result.written
-		downloadErr = result.err
-		LogToFrontend("debug", "Téléchargement terminé")
-	case <-copyCtx.Done():
-		LogToFrontend("error", "TIMEOUT lors du téléchargement après 4 heures")
-		return fmt.Errorf("timeout lors du téléchargement après 4 heures")
-	}
-
-	if downloadErr != nil {
-		LogToFrontend("error", fmt.Sprintf("ERREUR téléchargement: %v (écrit: %.2f MB)", downloadErr, float64(written)/(1024*1024)))
-		return fmt.Errorf("erreur téléchargement: %v", downloadErr)
-	}
-
-	LogToFrontend("debug", fmt.Sprintf("Téléchargement terminé, %.2f MB téléchargés", float64(written)/(1024*1024)))
-
 	if err := restoreS3FromArchive(ctx, localCreds, tmpFilePath, tmpDir, s3Host, s3Port, s3Region, s3UseHttps); err != nil {
 		return err
 	}
 
-	if err := os.Remove(tmpFilePath); err != nil {
-		LogToFrontend("warn", fmt.Sprintf("Impossible de supprimer le fichier temporaire: %v", err))
-	}
-
-	LogToFrontend("success", "Restauration S3 terminée avec succès.")
+	Log.Success("Restauration S3 terminée avec succès.")
 	return nil
 }
 
@@ -663,12 +418,12 @@ func RestoreS3BackupFromLocal(ctx context.Context, localCreds S3Credentials, loc
 		return fmt.Errorf("fichier de backup local introuvable: %v", err)
 	}
 
-	LogToFrontend("debug", "RestoreS3BackupFromLocal: Début de la restauration S3 depuis un fichier local")
-	LogToFrontend("debug", fmt.Sprintf("Archive locale: %s", archivePath))
+	Log.Debug("RestoreS3BackupFromLocal: Début de la restauration S3 depuis un fichier local")
+	Log.Debug(fmt.Sprintf("Archive locale: %s", archivePath))
 
-	tmpDir, err := getUserTmpDir()
+	tmpDir, err := getWorkDir()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur récupération dossier tmp: %v", err))
+		Log.Error(fmt.Sprintf("Erreur récupération dossier tmp: %v", err))
 		return err
 	}
 
@@ -676,138 +431,7 @@ func RestoreS3BackupFromLocal(ctx context.Context, localCreds S3Credentials, loc
 		return err
 	}
 
-	LogToFrontend("success", "Restauration S3 terminée avec succès.")
-	return nil
-}
-
-func restoreS3FromArchive(ctx context.Context, localCreds S3Credentials, archivePath, tmpDir, s3Host, s3Port, s3Region string, s3UseHttps bool) error {
-	LogToFrontend("debug", "Début de la décompression...")
-
-	// Décompresse le tar.gz dans un dossier temporaire
-	extractDir, err := os.MkdirTemp(tmpDir, "s3-restore-*")
-	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur création dossier temporaire: %v", err))
-		return fmt.Errorf("erreur création dossier temporaire: %v", err)
-	}
-	defer os.RemoveAll(extractDir)
-	LogToFrontend("debug", fmt.Sprintf("Extraction tar.gz dans: %s", extractDir))
-	cmdTar := exec.Command("tar", "-xzf", archivePath, "-C", extractDir)
-	cmdTar.Stdout = os.Stdout
-	cmdTar.Stderr = os.Stderr
-	if err := cmdTar.Run(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur extraction tar.gz: %v", err))
-		return fmt.Errorf("erreur extraction tar.gz: %v", err)
-	}
-
-	// On suppose que le dossier du bucket est à la racine de l'archive
-	entries, err := os.ReadDir(extractDir)
-	if err != nil || len(entries) == 0 {
-		LogToFrontend("error", "Aucun dossier de bucket trouvé dans l'archive")
-		return fmt.Errorf("aucun dossier de bucket trouvé dans l'archive")
-	}
-	bucketDir := entries[0].Name()
-	bucketPath := extractDir + "/" + bucketDir
-	LogToFrontend("debug", fmt.Sprintf("Bucket extrait: %s, chemin: %s", bucketDir, bucketPath))
-
-	// Utilise les credentials locaux pour uploader dans le S3 local
-	// Détermine le protocole à utiliser
-	protocol := "http"
-	if s3UseHttps {
-		protocol = "https"
-	}
-
-	s3LocalCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(s3Region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(localCreds.AccessKey, localCreds.SecretKey, "")),
-		config.WithEndpointResolverWithOptions(
-			aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
-				return aws.Endpoint{
-					URL:               fmt.Sprintf("%s://%s:%s", protocol, s3Host, s3Port),
-					HostnameImmutable: true,
-				}, nil
-			}),
-		),
-	)
-	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur config S3 local: %v", err))
-		return fmt.Errorf("erreur config S3 local: %v", err)
-	}
-
-	localClient := s3.NewFromConfig(s3LocalCfg, func(o *s3.Options) {
-		o.UsePathStyle = true
-	})
-
-	// Vérifie si le bucket existe, sinon le crée
-	_, err = localClient.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &bucketDir})
-	if err != nil {
-		LogToFrontend("debug", fmt.Sprintf("Bucket %s n'existe pas, création...", bucketDir))
-		_, err = localClient.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: &bucketDir})
-		if err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur création bucket local: %v", err))
-			return fmt.Errorf("erreur création bucket local: %v", err)
-		}
-	}
-	LogToFrontend("debug", fmt.Sprintf("Début upload fichiers dans le bucket local: %s", bucketDir))
-
-	dirEntries, err := os.ReadDir(bucketPath)
-	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur lecture du dossier bucket extrait: %v", err))
-		return fmt.Errorf("erreur lecture du dossier bucket extrait: %v", err)
-	}
-
-	// Compte le nombre de fichiers pour afficher la progression
-	totalFiles := 0
-	for _, entry := range dirEntries {
-		if !entry.IsDir() {
-			totalFiles++
-		}
-	}
-
-	// Upload des fichiers avec barre de progression
-	uploader := manager.NewUploader(localClient, func(u *manager.Uploader) {
-		u.PartSize = 16 * 1024 * 1024
-	})
-
-	uploadedFiles := 0
-	for _, entry := range dirEntries {
-		if entry.IsDir() {
-			continue // on ne gère que les fichiers à la racine
-		}
-		filePath := bucketPath + "/" + entry.Name()
-		uploadedFiles++
-		LogToFrontend("info", fmt.Sprintf("Upload fichier %d/%d: %s", uploadedFiles, totalFiles, entry.Name()))
-
-		f, err := os.Open(filePath)
-		if err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur ouverture fichier à restaurer: %v", err))
-			return fmt.Errorf("erreur ouverture fichier à restaurer: %v", err)
-		}
-
-		fileInfo, err := f.Stat()
-		if err != nil {
-			f.Close()
-			LogToFrontend("error", fmt.Sprintf("Erreur stat fichier à restaurer: %v", err))
-			return fmt.Errorf("erreur stat fichier à restaurer: %v", err)
-		}
-		if fileInfo.Size() > 10*1024*1024 {
-			LogToFrontend("debug", fmt.Sprintf("Fichier volumineux: %.2f MB", float64(fileInfo.Size())/(1024*1024)))
-		}
-
-		name := entry.Name()
-		_, err = uploader.Upload(ctx, &s3.PutObjectInput{
-			Bucket:        &bucketDir,
-			Key:           &name,
-			Body:          f,
-			ContentLength: aws.Int64(fileInfo.Size()),
-		})
-		f.Close()
-
-		if err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur upload objet S3 local: %v", err))
-			return fmt.Errorf("erreur upload objet S3 local: %v", err)
-		}
-	}
-
+	Log.Success("Restauration S3 terminée avec succès.")
 	return nil
 }
 
@@ -818,45 +442,9 @@ func derefInt64(ptr *int64) int64 {
 	return *ptr
 }
 
-type progressReaderWithLog struct {
-	r          io.Reader
-	total      int64
-	read       int64
-	last       int64
-	lastUpdate time.Time
-	lastLog    time.Time
-}
-
-func (p *progressReaderWithLog) Read(b []byte) (int, error) {
-	n, err := p.r.Read(b)
-	if n > 0 {
-		p.read += int64(n)
-		percent := int(float64(p.read) / float64(p.total) * 100)
-
-		// Update progress at most every 500ms to avoid flooding the logs
-		// Also update if percentage has changed, or at least every 5MB
-		now := time.Now()
-		percentChanged := percent != int(float64(p.last)/float64(p.total)*100)
-		timePassed := now.Sub(p.lastUpdate) >= 500*time.Millisecond
-		sizePassed := (p.read - p.last) >= 5*1024*1024 // 5MB
-
-		if percentChanged && (timePassed || sizePassed) {
-			mbRead := float64(p.read) / (1024 * 1024)
-			mbTotal := float64(p.total) / (1024 * 1024)
-			LogToFrontend("info", fmt.Sprintf("Téléchargement: %d%% (%.2f/%.2f MB)", percent, mbRead, mbTotal))
-			p.last = p.read
-			p.lastUpdate = now
-		}
-	}
-	if err != nil && err != io.EOF {
-		LogToFrontend("error", fmt.Sprintf("ERREUR lecture progressReaderWithLog: %v (lu jusqu'à présent: %d octets)", err, p.read))
-	}
-	return n, err
-}
-
 // DumpMongoDatabase crée un dump d'une base MongoDB
 func DumpMongoDatabase(ctx context.Context, mongoHost, mongoPort, mongoUser, mongoPassword, database string) (string, error) {
-	tmpDir, err := getUserTmpDir()
+	tmpDir, err := getWorkDir()
 	if err != nil {
 		return "", err
 	}
@@ -870,34 +458,35 @@ func DumpMongoDatabase(ctx context.Context, mongoHost, mongoPort, mongoUser, mon
 	tmpFile.Close()
 
 	// Prépare la commande mongodump
-	args := []string{
-		"--gzip",
-		"--archive=" + tmpFilePath,
-		"--host", mongoHost,
-		"--port", mongoPort,
-		"--db", database,
-	}
-	if mongoUser != "" {
-		args = append(args, "--username", mongoUser)
-		// Ajouter la base d'authentification (par défaut admin)
-		args = append(args, "--authenticationDatabase", "admin")
+	connArgs, cleanup, err := mongoToolArgs(mongoHost, mongoPort, mongoUser, mongoPassword)
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return "", err
 	}
-	if mongoPassword != "" {
-		args = append(args, "--password", mongoPassword)
+	defer cleanup()
+	// L'archive est écrite sur la sortie standard pour suivre la progression du dump
+	args := append([]string{"--gzip", "--archive", "--db", database}, connArgs...)
+
+	outFile, err := os.Create(tmpFilePath)
+	if err != nil {
+		return "", fmt.Errorf("erreur création fichier sortie: %v", err)
 	}
+	defer outFile.Close()
 
-	LogToFrontend("info", fmt.Sprintf("Création du dump de la base %s...", database))
-	cmd := exec.Command("mongodump", args...)
-	cmd.Stdout = os.Stdout
+	Log.Info(fmt.Sprintf("Création du dump de la base %s...", database))
+	progress := NewProgress(ctx, PhaseDump, database, 0)
+	cmd := exec.CommandContext(ctx, "mongodump", args...)
+	cmd.Stdout = progress.Writer(outFile)
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		os.Remove(tmpFilePath)
-		LogToFrontend("error", fmt.Sprintf("Erreur mongodump: %v", err))
+		Log.Error(fmt.Sprintf("Erreur mongodump: %v", err))
 		return "", fmt.Errorf("erreur mongodump: %v", err)
 	}
+	progress.Finish()
 
-	LogToFrontend("success", fmt.Sprintf("Dump de %s créé avec succès", database))
+	Log.Success(fmt.Sprintf("Dump de %s créé avec succès", database))
 	return tmpFilePath, nil
 }
 
@@ -905,7 +494,7 @@ func DumpMongoDatabase(ctx context.Context, mongoHost, mongoPort, mongoUser, mon
 func TransferMongoDatabase(ctx context.Context, sourceHost, sourcePort, sourceUser, sourcePassword, 
 	destHost, destPort, destUser, destPassword, database string, dropExisting bool) error {
 	
-	LogToFrontend("info", fmt.Sprintf("Début du transfert de la base %s", database))
+	Log.Info(fmt.Sprintf("Début du transfert de la base %s", database))
 	
 	// Étape 1: Créer le dump de la source
 	dumpFile, err := DumpMongoDatabase(ctx, sourceHost, sourcePort, sourceUser, sourcePassword, database)
@@ -915,246 +504,84 @@ func TransferMongoDatabase(ctx context.Context, sourceHost, sourcePort, sourceUs
 	defer os.Remove(dumpFile)
 	
 	// Étape 2: Restaurer sur la destination
-	args := []string{
-		"--gzip",
-		"--archive=" + dumpFile,
-		"--host", destHost,
-		"--port", destPort,
-	}
-	if destUser != "" {
-		args = append(args, "--username", destUser)
-		// Ajouter la base d'authentification (par défaut admin)
-		args = append(args, "--authenticationDatabase", "admin")
-	}
-	if destPassword != "" {
-		args = append(args, "--password", destPassword)
+	connArgs, cleanup, err := mongoToolArgs(destHost, destPort, destUser, destPassword)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
+	args := append([]string{"--gzip", "--archive"}, connArgs...)
 	if dropExisting {
 		args = append(args, "--drop")
 	}
+	archive, err := openFileWithProgress(ctx, dumpFile, PhaseRestore, database)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
 	
-	LogToFrontend("info", fmt.Sprintf("Restauration de %s sur le serveur de destination...", database))
-	cmd := exec.Command("mongorestore", args...)
+	Log.Info(fmt.Sprintf("Restauration de %s sur le serveur de destination...", database))
+	cmd := exec.CommandContext(ctx, "mongorestore", args...)
+	cmd.Stdin = archive
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	
 	if err := cmd.Run(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur mongorestore: %v", err))
+		Log.Error(fmt.Sprintf("Erreur mongorestore: %v", err))
 		return fmt.Errorf("erreur mongorestore: %v", err)
 	}
+	archive.Finish()
 	
-	LogToFrontend("success", fmt.Sprintf("Transfert de %s terminé avec succès", database))
+	Log.Success(fmt.Sprintf("Transfert de %s terminé avec succès", database))
 	return nil
 }
 
-// ListMongoDatabases liste les bases de données disponibles sur un serveur MongoDB
-func ListMongoDatabases(ctx context.Context, mongoHost, mongoPort, mongoUser, mongoPassword string) ([]string, error) {
-	// Construire l'URI MongoDB
-	var uri string
-	if mongoUser != "" && mongoPassword != "" {
-		// Ajouter authSource=admin pour l'authentification
-		uri = fmt.Sprintf("mongodb://%s:%s@%s:%s/?authSource=admin", mongoUser, mongoPassword, mongoHost, mongoPort)
-	} else {
-		uri = fmt.Sprintf("mongodb://%s:%s", mongoHost, mongoPort)
-	}
-	
-	// Utiliser mongo shell pour lister les bases
-	args := []string{
-		uri,
-		"--quiet",
-		"--eval",
-		"db.adminCommand('listDatabases').databases.forEach(function(d){print(d.name)})",
-	}
-	
-	cmd := exec.Command("mongosh", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		// Fallback sur l'ancienne commande mongo si mongosh n'est pas disponible
-		args[0] = uri
-		cmd = exec.Command("mongo", args...)
-		output, err = cmd.Output()
-		if err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur listing databases: %v", err))
-			return nil, fmt.Errorf("erreur listing databases: %v", err)
-		}
-	}
-	
-	// Parser la sortie pour obtenir la liste des bases
-	lines := strings.Split(string(output), "\n")
-	var databases []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" && !strings.HasPrefix(line, "MongoDB") && !strings.Contains(line, "connecting") {
-			databases = append(databases, line)
-		}
-	}
-	
-	return databases, nil
-}
-
-// RestorePostgresBackup télécharge un backup S3 et le restaure dans PostgreSQL
+// RestorePostgresBackup restaure un backup S3 dans PostgreSQL. Le backup est lu en flux
+// depuis S3 et décompressé à la volée, sans fichier temporaire.
 func RestorePostgresBackup(ctx context.Context, creds S3Credentials, s3Path string, pgHost, pgPort, pgUser, pgPassword, pgDatabase string) error {
-	bucket, region, endpoint := resolveS3Config(creds)
-	objectName := s3Path
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKey, creds.SecretKey, "")),
-	)
-	if err != nil {
-		return fmt.Errorf("erreur chargement config AWS: %v", err)
-	}
-	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
-		o.UsePathStyle = true
-	})
-	presignedURL, err := generatePresignedURL(ctx, client, bucket, objectName)
-	if err != nil {
-		return err
-	}
-
-	// Récupère la taille du fichier pour la progression
-	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &objectName})
-	var totalSize int64 = 0
-	if err == nil && head.ContentLength != nil {
-		totalSize = *head.ContentLength
-		LogToFrontend("info", fmt.Sprintf("Taille du backup à télécharger: %.2f MB", float64(totalSize)/(1024*1024)))
-	}
-
-	respBody, err := downloadWithRetry(presignedURL, 3, 30*time.Minute)
-	if err != nil {
-		return fmt.Errorf("erreur téléchargement HTTP: %v", err)
-	}
-	defer respBody.Close()
-
-	tmpDir, err := getUserTmpDir()
+	storage, err := newS3Storage(ctx, creds)
 	if err != nil {
 		return err
 	}
-	tmpFile, err := os.CreateTemp(tmpDir, "postgres-backup-*.sql.gz")
-	if err != nil {
-		return fmt.Errorf("erreur création fichier temporaire: %v", err)
-	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
-
-	// Progression du téléchargement
-	progressReader := &progressReaderWithLog{
-		r:     respBody,
-		total: totalSize,
-	}
-	LogToFrontend("info", "Début du téléchargement du backup PostgreSQL...")
-	_, err = io.Copy(tmpFile, progressReader)
-	if err != nil {
-		return fmt.Errorf("erreur écriture fichier: %v", err)
-	}
-	LogToFrontend("success", "Téléchargement du backup PostgreSQL terminé.")
+	return restorePostgresFromStorage(ctx, storage, s3Path, pgHost, pgPort, pgUser, pgPassword, pgDatabase)
+}
 
-	LogToFrontend("debug", fmt.Sprintf("pgHost=%s, pgPort=%s, pgUser=%s, pgDatabase=%s", pgHost, pgPort, pgUser, pgDatabase))
+// restorePostgresFromStorage restaure la sauvegarde key de storage dans la base PostgreSQL pgDatabase
+func restorePostgresFromStorage(ctx context.Context, storage BackupStorage, key string, pgHost, pgPort, pgUser, pgPassword, pgDatabase string) error {
+	Log.Debug(fmt.Sprintf("pgHost=%s, pgPort=%s, pgUser=%s, pgDatabase=%s", pgHost, pgPort, pgUser, pgDatabase))
 
 	// Définir PGPASSWORD dans l'environnement
-	env := os.Environ()
-	if pgPassword != "" {
-		env = append(env, fmt.Sprintf("PGPASSWORD=%s", pgPassword))
-	}
+	env := postgresEnv(pgPassword)
 
 	// Créer la base de données si elle n'existe pas
-	LogToFrontend("info", fmt.Sprintf("Création de la base de données %s si elle n'existe pas...", pgDatabase))
-	
-	// D'abord vérifier si la base existe
-	checkCmd := exec.Command("psql",
-		"-h", pgHost,
-		"-p", pgPort,
-		"-U", pgUser,
-		"-d", "postgres",
-		"-t",
-		"-c", fmt.Sprintf("SELECT 1 FROM pg_database WHERE datname = '%s'", pgDatabase))
-	checkCmd.Env = env
-	checkOutput, _ := checkCmd.Output()
-	
-	// Si la base n'existe pas, la créer
-	if strings.TrimSpace(string(checkOutput)) == "" {
-		createCmd := exec.Command("psql",
-			"-h", pgHost,
-			"-p", pgPort,
-			"-U", pgUser,
-			"-d", "postgres",
-			"-c", fmt.Sprintf("CREATE DATABASE %s", pgDatabase))
-		createCmd.Env = env
-		if err := createCmd.Run(); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Impossible de créer la base: %v", err))
-			return fmt.Errorf("impossible de créer la base de données: %v", err)
-		}
-		LogToFrontend("success", fmt.Sprintf("Base de données %s créée avec succès", pgDatabase))
+	Log.Info(fmt.Sprintf("Création de la base de données %s si elle n'existe pas...", pgDatabase))
+	if err := ensurePostgresDatabase(ctx, env, pgHost, pgPort, pgUser, pgDatabase); err != nil {
+		return err
 	}
 
-	// Décompresser et restaurer avec pg_restore ou psql selon le format
-	LogToFrontend("info", "Début de la restauration PostgreSQL...")
-	
-	// D'abord, décompresser le fichier pour déterminer son format
-	cmd := exec.Command("gunzip", "-c", tmpFile.Name())
-	cmd.Env = env
-	unzippedData, err := cmd.Output()
+	body, totalSize, err := storage.Open(ctx, key)
 	if err != nil {
-		return fmt.Errorf("erreur décompression: %v", err)
+		return err
 	}
-
-	// Créer un fichier temporaire pour les données décompressées
-	tmpUnzipped, err := os.CreateTemp(tmpDir, "postgres-backup-*.sql")
-	if err != nil {
-		return fmt.Errorf("erreur création fichier temporaire décompressé: %v", err)
+	defer body.Close()
+	if totalSize > 0 {
+		Log.Info(fmt.Sprintf("Taille du backup à restaurer: %.2f MB", float64(totalSize)/(1024*1024)))
 	}
-	defer os.Remove(tmpUnzipped.Name())
-	
-	if _, err := tmpUnzipped.Write(unzippedData); err != nil {
-		tmpUnzipped.Close()
-		return fmt.Errorf("erreur écriture fichier décompressé: %v", err)
-	}
-	tmpUnzipped.Close()
-
-	// Vérifier si c'est un dump custom format ou SQL plain text
-	fileCmd := exec.Command("file", tmpUnzipped.Name())
-	fileOutput, _ := fileCmd.Output()
-	fileType := string(fileOutput)
-
-	var restoreCmd *exec.Cmd
-	if strings.Contains(fileType, "PostgreSQL") && strings.Contains(fileType, "custom") {
-		// Format custom, utiliser pg_restore
-		restoreCmd = exec.Command("pg_restore",
-			"-h", pgHost,
-			"-p", pgPort,
-			"-U", pgUser,
-			"-d", pgDatabase,
-			"--clean",
-			"--if-exists",
-			"--no-owner",
-			"--no-privileges",
-			tmpUnzipped.Name())
-	} else {
-		// Format SQL plain text, utiliser psql
-		restoreCmd = exec.Command("psql",
-			"-h", pgHost,
-			"-p", pgPort,
-			"-U", pgUser,
-			"-d", pgDatabase,
-			"-f", tmpUnzipped.Name())
-	}
-
-	restoreCmd.Env = env
-	restoreCmd.Stdout = os.Stdout
-	restoreCmd.Stderr = os.Stderr
-	
-	if err := restoreCmd.Run(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur restauration PostgreSQL: %v", err))
-		return fmt.Errorf("erreur restauration PostgreSQL: %v", err)
+
+	// La progression suit les octets compressés lus depuis le stockage
+	Log.Info("Début de la restauration PostgreSQL...")
+	progress := NewProgress(ctx, PhaseRestore, pgDatabase, totalSize)
+	if err := restorePostgresStream(ctx, progress.Reader(body), env, pgHost, pgPort, pgUser, pgDatabase); err != nil {
+		return err
 	}
+	progress.Finish()
 
-	LogToFrontend("success", "Restauration PostgreSQL terminée avec succès.")
+	Log.Success("Restauration PostgreSQL terminée avec succès.")
 	return nil
 }
 
 // DumpPostgresDatabase crée un dump d'une base PostgreSQL
 func DumpPostgresDatabase(ctx context.Context, pgHost, pgPort, pgUser, pgPassword, database string) (string, error) {
-	tmpDir, err := getUserTmpDir()
+	tmpDir, err := getWorkDir()
 	if err != nil {
 		return "", err
 	}
@@ -1168,15 +595,11 @@ func DumpPostgresDatabase(ctx context.Context, pgHost, pgPort, pgUser, pgPasswor
 	tmpFile.Close()
 
 	// Définir PGPASSWORD dans l'environnement
-	env := os.Environ()
-	if pgPassword != "" {
-		env = append(env, fmt.Sprintf("PGPASSWORD=%s", pgPassword))
-	}
+	env := postgresEnv(pgPassword)
 
-	LogToFrontend("info", fmt.Sprintf("Création du dump de la base %s...", database))
+	Log.Info(fmt.Sprintf("Création du dump de la base %s...", database))
 	
-	// Utiliser pg_dump avec compression
-	dumpCmd := exec.Command("pg_dump",
+	dumpCmd := exec.CommandContext(ctx, "pg_dump",
 		"-h", pgHost,
 		"-p", pgPort,
 		"-U", pgUser,
@@ -1184,40 +607,38 @@ func DumpPostgresDatabase(ctx context.Context, pgHost, pgPort, pgUser, pgPasswor
 		"--clean",
 		"--if-exists",
 		"--no-owner",
-		"--no-privileges",
-		"-Z", "9") // Compression maximale
-	
-	dumpCmd.Env = env
-	dumpCmd.Stderr = os.Stderr
-
-	// Récupérer la sortie de pg_dump
-	dumpOutput, err := dumpCmd.Output()
-	if err != nil {
-		os.Remove(tmpFilePath)
-		LogToFrontend("error", fmt.Sprintf("Erreur pg_dump: %v", err))
-		return "", fmt.Errorf("erreur pg_dump: %v", err)
-	}
+		"--no-privileges")
 
-	// Compresser avec gzip
-	gzipCmd := exec.Command("gzip", "-9")
-	gzipCmd.Stdin = strings.NewReader(string(dumpOutput))
-	
+	// La sortie de pg_dump est compressée en gzip directement dans le fichier
+	// (pg_dump -Z n'est plus utilisé, il produisait une double compression)
 	outFile, err := os.Create(tmpFilePath)
 	if err != nil {
 		return "", fmt.Errorf("erreur création fichier de sortie: %v", err)
 	}
 	defer outFile.Close()
-	
-	gzipCmd.Stdout = outFile
-	gzipCmd.Stderr = os.Stderr
-	
-	if err := gzipCmd.Run(); err != nil {
+	progress := NewProgress(ctx, PhaseDump, database, 0)
+	gz, err := newCompressWriter(progress.Writer(outFile), CompressionGzip)
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return "", err
+	}
+
+	dumpCmd.Env = env
+	dumpCmd.Stdout = gz
+	dumpCmd.Stderr = os.Stderr
+	if err := dumpCmd.Run(); err != nil {
+		os.Remove(tmpFilePath)
+		Log.Error(fmt.Sprintf("Erreur pg_dump: %v", err))
+		return "", fmt.Errorf("erreur pg_dump: %v", err)
+	}
+	if err := gz.Close(); err != nil {
 		os.Remove(tmpFilePath)
-		LogToFrontend("error", fmt.Sprintf("Erreur compression gzip: %v", err))
+		Log.Error(fmt.Sprintf("Erreur compression gzip: %v", err))
 		return "", fmt.Errorf("erreur compression gzip: %v", err)
 	}
+	progress.Finish()
 
-	LogToFrontend("success", fmt.Sprintf("Dump de %s créé avec succès", database))
+	Log.Success(fmt.Sprintf("Dump de %s créé avec succès", database))
 	return tmpFilePath, nil
 }
 
@@ -1225,7 +646,7 @@ func DumpPostgresDatabase(ctx context.Context, pgHost, pgPort, pgUser, pgPasswor
 func TransferPostgresDatabase(ctx context.Context, sourceHost, sourcePort, sourceUser, sourcePassword,
 	destHost, destPort, destUser, destPassword, database string, dropExisting bool) error {
 
-	LogToFrontend("info", fmt.Sprintf("Début du transfert de la base %s", database))
+	Log.Info(fmt.Sprintf("Début du transfert de la base %s", database))
 
 	// Étape 1: Créer le dump de la source
 	dumpFile, err := DumpPostgresDatabase(ctx, sourceHost, sourcePort, sourceUser, sourcePassword, database)
@@ -1234,40 +655,13 @@ func TransferPostgresDatabase(ctx context.Context, sourceHost, sourcePort, sourc
 	}
 	defer os.Remove(dumpFile)
 
-	// Étape 2: Décompresser le dump
-	tmpDir, err := getUserTmpDir()
-	if err != nil {
-		return err
-	}
-	
-	tmpUnzipped, err := os.CreateTemp(tmpDir, "postgres-transfer-*.sql")
-	if err != nil {
-		return fmt.Errorf("erreur création fichier temporaire: %v", err)
-	}
-	defer os.Remove(tmpUnzipped.Name())
-	tmpUnzipped.Close()
-
-	// Décompresser
-	cmd := exec.Command("gunzip", "-c", dumpFile)
-	output, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("erreur décompression: %v", err)
-	}
-
-	if err := os.WriteFile(tmpUnzipped.Name(), output, 0644); err != nil {
-		return fmt.Errorf("erreur écriture fichier décompressé: %v", err)
-	}
-
-	// Étape 3: Gérer la base de données de destination
-	env := os.Environ()
-	if destPassword != "" {
-		env = append(env, fmt.Sprintf("PGPASSWORD=%s", destPassword))
-	}
+	// Étape 2: Gérer la base de données de destination
+	env := postgresEnv(destPassword)
 
 	if dropExisting {
 		// Supprimer la base si elle existe
-		LogToFrontend("info", fmt.Sprintf("Suppression de la base %s sur le serveur de destination si elle existe...", database))
-		dropCmd := exec.Command("psql",
+		Log.Info(fmt.Sprintf("Suppression de la base %s sur le serveur de destination si elle existe...", database))
+		dropCmd := exec.CommandContext(ctx, "psql",
 			"-h", destHost,
 			"-p", destPort,
 			"-U", destUser,
@@ -1275,74 +669,40 @@ func TransferPostgresDatabase(ctx context.Context, sourceHost, sourcePort, sourc
 			"-c", fmt.Sprintf("DROP DATABASE IF EXISTS %s", database))
 		dropCmd.Env = env
 		if err := dropCmd.Run(); err != nil {
-			LogToFrontend("warn", fmt.Sprintf("Impossible de supprimer la base: %v", err))
+			Log.Warn(fmt.Sprintf("Impossible de supprimer la base: %v", err))
 		}
 	}
 
 	// Toujours créer la base de données si elle n'existe pas
-	LogToFrontend("info", fmt.Sprintf("Création de la base %s si elle n'existe pas...", database))
-	
-	// D'abord vérifier si la base existe
-	checkCmd := exec.Command("psql",
-		"-h", destHost,
-		"-p", destPort,
-		"-U", destUser,
-		"-d", "postgres",
-		"-t",
-		"-c", fmt.Sprintf("SELECT 1 FROM pg_database WHERE datname = '%s'", database))
-	checkCmd.Env = env
-	checkOutput, _ := checkCmd.Output()
-	
-	// Si la base n'existe pas, la créer
-	if strings.TrimSpace(string(checkOutput)) == "" {
-		createCmd := exec.Command("psql",
-			"-h", destHost,
-			"-p", destPort,
-			"-U", destUser,
-			"-d", "postgres",
-			"-c", fmt.Sprintf("CREATE DATABASE %s", database))
-		createCmd.Env = env
-		if err := createCmd.Run(); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Impossible de créer la base: %v", err))
-			return fmt.Errorf("impossible de créer la base de données: %v", err)
-		}
-		LogToFrontend("success", fmt.Sprintf("Base de données %s créée avec succès", database))
-	} else {
-		LogToFrontend("info", fmt.Sprintf("La base de données %s existe déjà", database))
+	Log.Info(fmt.Sprintf("Création de la base %s si elle n'existe pas...", database))
+	if err := ensurePostgresDatabase(ctx, env, destHost, destPort, destUser, database); err != nil {
+		return err
 	}
 
-	// Étape 4: Restaurer sur la destination
-	LogToFrontend("info", fmt.Sprintf("Restauration de %s sur le serveur de destination...", database))
-	restoreCmd := exec.Command("psql",
-		"-h", destHost,
-		"-p", destPort,
-		"-U", destUser,
-		"-d", database,
-		"-f", tmpUnzipped.Name())
-	
-	restoreCmd.Env = env
-	restoreCmd.Stdout = os.Stdout
-	restoreCmd.Stderr = os.Stderr
+	// Étape 4: Restaurer sur la destination, le dump est décompressé à la volée
+	Log.Info(fmt.Sprintf("Restauration de %s sur le serveur de destination...", database))
+	dump, err := openFileWithProgress(ctx, dumpFile, PhaseRestore, database)
+	if err != nil {
+		return err
+	}
+	defer dump.Close()
 
-	if err := restoreCmd.Run(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur restauration PostgreSQL: %v", err))
-		return fmt.Errorf("erreur restauration PostgreSQL: %v", err)
+	if err := restorePostgresStream(ctx, dump, env, destHost, destPort, destUser, database); err != nil {
+		return err
 	}
+	dump.Finish()
 
-	LogToFrontend("success", fmt.Sprintf("Transfert de %s terminé avec succès", database))
+	Log.Success(fmt.Sprintf("Transfert de %s terminé avec succès", database))
 	return nil
 }
 
 // ListPostgresDatabases liste les bases de données disponibles sur un serveur PostgreSQL
 func ListPostgresDatabases(ctx context.Context, pgHost, pgPort, pgUser, pgPassword string) ([]string, error) {
 	// Définir PGPASSWORD dans l'environnement
-	env := os.Environ()
-	if pgPassword != "" {
-		env = append(env, fmt.Sprintf("PGPASSWORD=%s", pgPassword))
-	}
+	env := postgresEnv(pgPassword)
 
 	// Utiliser psql pour lister les bases
-	cmd := exec.Command("psql",
+	cmd := exec.CommandContext(ctx, "psql",
 		"-h", pgHost,
 		"-p", pgPort,
 		"-U", pgUser,
@@ -1353,7 +713,7 @@ func ListPostgresDatabases(ctx context.Context, pgHost, pgPort, pgUser, pgPasswo
 	cmd.Env = env
 	output, err := cmd.Output()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur listing databases PostgreSQL: %v", err))
+		Log.Error(fmt.Sprintf("Erreur listing databases PostgreSQL: %v", err))
 		return nil, fmt.Errorf("erreur listing databases PostgreSQL: %v", err)
 	}
 
@@ -7,67 +7,44 @@ import (
 	"os"
 	"path/filepath"
 	"time"
-
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-// BackupToS3 sauvegarde le projet vers S3
+// BackupToS3 sauvegarde le projet vers le dépôt de backups S3 (s3Bucket remplace le bucket configuré)
 func BackupToS3(projectPath string, s3Bucket string) error {
-	// Créer un fichier tar.gz du projet
-	timestamp := time.Now().Format("20060102-150405")
-	archiveName := fmt.Sprintf("backup-%s.tar.gz", timestamp)
-	tempFile := filepath.Join(os.TempDir(), archiveName)
-	defer os.Remove(tempFile)
-
-	// Créer l'archive
-	if err := execCommand("tar", "-czf", tempFile, "-C", projectPath, "."); err != nil {
-		return fmt.Errorf("erreur lors de la création de l'archive: %v", err)
+	compression, err := ParseCompression(CurrentConfig().Backup.Compression)
+	if err != nil {
+		return err
 	}
 
-	// Upload vers S3
+	// Créer une archive compressée du projet
 	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(S3Region),
-	)
+	timestamp := time.Now().Format("20060102-150405")
+	archiveName := "backup-" + timestamp + compression.Extension()
+	workDir, err := getWorkDir()
 	if err != nil {
-		return fmt.Errorf("erreur chargement config AWS: %v", err)
+		return err
 	}
+	tempFile := filepath.Join(workDir, archiveName)
+	defer os.Remove(tempFile)
 
-	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.EndpointResolver = s3.EndpointResolverFromURL("https://" + S3BaseURL)
-		o.UsePathStyle = true
-	})
-
-	file, err := os.Open(tempFile)
-	if err != nil {
-		return fmt.Errorf("erreur ouverture fichier: %v", err)
+	if err := CreateTarArchive(ctx, projectPath, tempFile, compression); err != nil {
+		return fmt.Errorf("erreur lors de la création de l'archive: %v", err)
 	}
-	defer file.Close()
 
-	fileInfo, err := file.Stat()
+	// Upload vers le dépôt (profil dépôt de backups ou section backup.s3 de la configuration)
+	repo, err := openBackupRepository(ctx, s3Bucket)
 	if err != nil {
-		return fmt.Errorf("erreur stat fichier: %v", err)
+		return err
 	}
-
-	key := fmt.Sprintf("cli-backups/%s", archiveName)
-	uploader := manager.NewUploader(client, func(u *manager.Uploader) {
-		u.PartSize = 16 * 1024 * 1024
-	})
-	size := fileInfo.Size()
-	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
-		Bucket:        &s3Bucket,
-		Key:           &key,
-		Body:          file,
-		ContentLength: &size,
-	})
-
-	if err != nil {
-		return fmt.Errorf("erreur upload S3: %v", err)
+	key := archiveName
+	if repo.prefix != "" {
+		key = repo.prefix + "/" + archiveName
+	}
+	if err := repo.upload(ctx, tempFile, key, nil); err != nil {
+		return err
 	}
 
-	LogToFrontend("info", fmt.Sprintf("Backup sauvegardé vers S3: %s/%s", s3Bucket, key))
+	Log.Info(fmt.Sprintf("Backup sauvegardé vers %s/%s", repo.storage, key))
 	return nil
 }
 
@@ -87,17 +64,22 @@ func BackupToLocal(projectPath string, localPath string) error {
 		return fmt.Errorf("erreur création répertoire: %v", err)
 	}
 
-	// Créer un fichier tar.gz du projet
+	compression, err := ParseCompression(CurrentConfig().Backup.Compression)
+	if err != nil {
+		return err
+	}
+
+	// Créer une archive compressée du projet
 	timestamp := time.Now().Format("20060102-150405")
-	archiveName := fmt.Sprintf("backup-%s.tar.gz", timestamp)
+	archiveName := "backup-" + timestamp + compression.Extension()
 	archivePath := filepath.Join(localPath, archiveName)
 
 	// Créer l'archive directement dans le répertoire de destination
-	if err := execCommand("tar", "-czf", archivePath, "-C", projectPath, "."); err != nil {
+	if err := CreateTarArchive(context.Background(), projectPath, archivePath, compression); err != nil {
 		return fmt.Errorf("erreur lors de la création de l'archive: %v", err)
 	}
 
-	LogToFrontend("info", fmt.Sprintf("Backup sauvegardé localement: %s", archivePath))
+	Log.Info(fmt.Sprintf("Backup sauvegardé localement: %s", archivePath))
 	return nil
 }
 
@@ -113,12 +95,12 @@ func RestoreFromLocal(localPath string, projectPath string) error {
 		return fmt.Errorf("erreur création répertoire: %v", err)
 	}
 
-	// Extraire l'archive
-	if err := execCommand("tar", "-xzf", localPath, "-C", projectPath); err != nil {
+	// Extraire l'archive (tar, tar.gz ou tar.zst)
+	if err := ExtractTarArchive(context.Background(), localPath, projectPath); err != nil {
 		return fmt.Errorf("erreur lors de l'extraction de l'archive: %v", err)
 	}
 
-	LogToFrontend("info", fmt.Sprintf("Projet restauré depuis: %s", localPath))
+	Log.Info(fmt.Sprintf("Projet restauré depuis: %s", localPath))
 	return nil
 }
 
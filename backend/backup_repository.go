@@ -0,0 +1,319 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// backupTimeLayout est le format (UTC) de l'horodatage des sauvegardes dans les clés S3
+const backupTimeLayout = "20060102-150405"
+
+// Types de sauvegarde qui ne correspondent pas à un moteur de base de données
+const (
+	BackupKindProject = "project"
+)
+
+// BackupKey est la clé S3 d'une sauvegarde : <prefix>/<engine>/<server>/<database>/<timestamp><ext>.
+// Pour une archive de projet, Server est le nom du projet et Database est vide ;
+// pour un snapshot S3, Server est le profil source et Database le bucket copié.
+type BackupKey struct {
+	Prefix   string
+	Engine   string
+	Server   string
+	Database string
+	Time     time.Time
+	Ext      string
+}
+
+// Dir retourne le dossier qui regroupe toutes les sauvegardes de la même source
+func (k BackupKey) Dir() string {
+	parts := []string{}
+	if prefix := strings.Trim(k.Prefix, "/"); prefix != "" {
+		parts = append(parts, prefix)
+	}
+	for _, part := range []string{k.Engine, k.Server, k.Database} {
+		if part = sanitizeKeyPart(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (k BackupKey) String() string {
+	return path.Join(k.Dir(), k.Time.UTC().Format(backupTimeLayout)+k.Ext)
+}
+
+// sanitizeKeyPart évite qu'un nom de serveur ou de base ne crée des sous-dossiers inattendus
+func sanitizeKeyPart(part string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(strings.TrimSpace(part))
+}
+
+// parseBackupTime extrait l'horodatage du nom de fichier d'une sauvegarde
+func parseBackupTime(name string) (time.Time, bool) {
+	name = path.Base(name)
+	if len(name) < len(backupTimeLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(backupTimeLayout, name[:len(backupTimeLayout)], time.UTC)
+	return t, err == nil
+}
+
+// backupRepository est la destination des sauvegardes : le dossier backup.repository s'il est
+// configuré, sinon S3 avec le profil défini comme dépôt de backups ou, à défaut, la section
+// backup.s3 de la configuration (identifiants AWS standards).
+type backupRepository struct {
+	storage BackupStorage
+	// bucket est le bucket S3 ou le dossier du dépôt, repris dans les BackupResult
+	bucket string
+	prefix string
+}
+
+// openBackupRepository prépare le stockage du dépôt. bucket, s'il n'est pas vide, désigne un
+// bucket S3 qui remplace le dépôt configuré.
+func openBackupRepository(ctx context.Context, bucket string) (*backupRepository, error) {
+	prefix := strings.Trim(CurrentConfig().Backup.S3.Prefix, "/")
+	if root := strings.TrimSpace(CurrentConfig().Backup.Repository); root != "" && bucket == "" {
+		storage, err := newLocalStorage(root)
+		if err != nil {
+			return nil, err
+		}
+		return &backupRepository{storage: storage, bucket: storage.root, prefix: prefix}, nil
+	}
+
+	var creds S3Credentials
+	profile, err := GetBackupRepositoryProfile()
+	switch {
+	case err == nil:
+		creds = profile.S3Credentials()
+	case !errors.Is(err, ErrNoBackupRepository):
+		return nil, err
+	}
+	if bucket != "" {
+		creds.Bucket = bucket
+	}
+
+	storage, err := newS3Storage(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	return &backupRepository{storage: storage, bucket: storage.bucket, prefix: prefix}, nil
+}
+
+// upload envoie le fichier localPath sous key (avec progression)
+func (r *backupRepository) upload(ctx context.Context, localPath, key string, metadata map[string]string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("erreur ouverture fichier: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("erreur stat fichier: %v", err)
+	}
+	return r.storage.Put(ctx, key, f, info.Size(), metadata)
+}
+
+// Métadonnées S3 (x-amz-meta-*) attachées à chaque sauvegarde du dépôt
+const (
+	MetaEngine        = "engine"
+	MetaEngineVersion = "engine-version"
+	MetaToolVersion   = "tool-version"
+	MetaServer        = "server"
+	MetaDatabase      = "database"
+	MetaSize          = "size"
+	MetaSHA256        = "sha256"
+	MetaCompression   = "compression"
+	MetaEncryption    = "encryption"
+	MetaKeyID         = "key-id"
+)
+
+// BackupResult décrit une sauvegarde envoyée vers le dépôt de backups
+type BackupResult struct {
+	Bucket        string `json:"bucket"`
+	Key           string `json:"key"`
+	Engine        string `json:"engine"`
+	EngineVersion string `json:"engineVersion,omitempty"`
+	ToolVersion   string `json:"toolVersion"`
+	Server        string `json:"server"`
+	Database      string `json:"database,omitempty"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	Compression   string `json:"compression"`
+	// Encryption et KeyID décrivent le chiffrement côté client (vides si la sauvegarde est en clair).
+	// Size et SHA256 portent alors sur l'objet chiffré.
+	Encryption string    `json:"encryption,omitempty"`
+	KeyID      string    `json:"keyId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// metadata retourne les métadonnées S3 de la sauvegarde
+func (b BackupResult) metadata() map[string]string {
+	meta := map[string]string{
+		MetaEngine:      b.Engine,
+		MetaToolVersion: b.ToolVersion,
+		MetaServer:      b.Server,
+		MetaSize:        strconv.FormatInt(b.Size, 10),
+		MetaSHA256:      b.SHA256,
+		MetaCompression: b.Compression,
+	}
+	if b.EngineVersion != "" {
+		meta[MetaEngineVersion] = b.EngineVersion
+	}
+	if b.Database != "" {
+		meta[MetaDatabase] = b.Database
+	}
+	if b.Encryption != "" {
+		meta[MetaEncryption] = b.Encryption
+		meta[MetaKeyID] = b.KeyID
+	}
+	return meta
+}
+
+// storeBackup calcule la taille et l'empreinte SHA-256 de localPath, puis l'envoie sous key
+// avec ses métadonnées et son manifeste. Si backup.encryptionKey est configuré, le fichier est
+// chiffré pendant l'envoi.
+func (r *backupRepository) storeBackup(ctx context.Context, localPath string, key BackupKey, engineVersion string) (*BackupResult, error) {
+	enc, err := configuredBackupEncryption()
+	if err != nil {
+		return nil, err
+	}
+	var noncePrefix []byte
+	if enc != nil {
+		if noncePrefix, err = newEncryptionNoncePrefix(); err != nil {
+			return nil, err
+		}
+	}
+	size, sum, err := storedChecksum(localPath, enc, noncePrefix)
+	if err != nil {
+		return nil, err
+	}
+	result := &BackupResult{
+		Bucket:        r.bucket,
+		Key:           key.String(),
+		Engine:        key.Engine,
+		EngineVersion: engineVersion,
+		ToolVersion:   GetCurrentVersion(),
+		Server:        key.Server,
+		Database:      key.Database,
+		Size:          size,
+		SHA256:        sum,
+		Compression:   string(compressionFromName(key.Ext)),
+		CreatedAt:     key.Time.UTC(),
+	}
+	if enc == nil {
+		err = r.upload(ctx, localPath, result.Key, result.metadata())
+	} else {
+		result.Encryption, result.KeyID = EncryptionAESGCM, enc.keyID
+		err = r.uploadEncrypted(ctx, localPath, result, enc, noncePrefix)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Sans manifeste, la sauvegarde reste listée par le catalogue à partir de sa clé
+	if err := r.writeManifest(ctx, result); err != nil {
+		Log.Warn(err.Error())
+	}
+	Log.Success(fmt.Sprintf("Sauvegarde envoyée: %s/%s (%s)", r.storage, result.Key, FormatBytes(size)))
+	return result, nil
+}
+
+// storedChecksum retourne la taille et l'empreinte de l'objet qui sera envoyé : le fichier
+// lui-même, ou son chiffrement (déterministe pour un même préfixe de nonce)
+func storedChecksum(localPath string, enc *backupEncryption, noncePrefix []byte) (int64, string, error) {
+	if enc == nil {
+		return fileChecksum(localPath)
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return 0, "", fmt.Errorf("erreur ouverture fichier: %v", err)
+	}
+	defer f.Close()
+	er, err := newEncryptingReader(f, enc, noncePrefix)
+	if err != nil {
+		return 0, "", err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, er)
+	if err != nil {
+		return 0, "", fmt.Errorf("erreur chiffrement de la sauvegarde: %v", err)
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadEncrypted chiffre localPath à la volée et l'envoie sous result.Key
+func (r *backupRepository) uploadEncrypted(ctx context.Context, localPath string, result *BackupResult, enc *backupEncryption, noncePrefix []byte) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("erreur ouverture fichier: %v", err)
+	}
+	defer f.Close()
+	er, err := newEncryptingReader(f, enc, noncePrefix)
+	if err != nil {
+		return err
+	}
+	Log.Info(fmt.Sprintf("Chiffrement de la sauvegarde avec la clé '%s'", enc.keyID))
+	return r.storage.Put(ctx, result.Key, er, result.Size, result.metadata())
+}
+
+// fileChecksum retourne la taille et l'empreinte SHA-256 (hexadécimale) d'un fichier
+func fileChecksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("erreur ouverture fichier: %v", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("erreur calcul de l'empreinte: %v", err)
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadFileToS3 envoie un fichier local vers S3 en multipart, avec suivi de progression
+func uploadFileToS3(ctx context.Context, client *s3.Client, bucket, key, localPath string, metadata map[string]string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("erreur ouverture fichier: %v", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("erreur stat fichier: %v", err)
+	}
+	return uploadToS3(ctx, client, bucket, key, file, fileInfo.Size(), metadata)
+}
+
+// uploadToS3 envoie size octets lus sur body vers S3 en multipart, avec suivi de progression
+func uploadToS3(ctx context.Context, client *s3.Client, bucket, key string, body io.Reader, size int64, metadata map[string]string) error {
+	uploader := manager.NewUploader(client, func(u *manager.Uploader) {
+		u.PartSize = s3UploadPartSize
+	})
+	progress := NewProgress(ctx, PhaseUpload, key, size)
+	_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		Body:          progress.Reader(body),
+		ContentLength: &size,
+		Metadata:      metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("erreur upload S3: %v", err)
+	}
+	progress.Finish()
+	return nil
+}
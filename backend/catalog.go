@@ -0,0 +1,240 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Chaque sauvegarde du dépôt est accompagnée d'un manifeste JSON <clé>.manifest.json qui
+// reprend son BackupResult. Le catalogue liste les sauvegardes à partir de ces manifestes ;
+// pour les sauvegardes sans manifeste, il se contente de la disposition de la clé.
+
+// manifestSuffix est ajouté à la clé d'une sauvegarde pour obtenir celle de son manifeste
+const manifestSuffix = ".manifest.json"
+
+// verifyReportSuffix est ajouté à la clé d'une sauvegarde pour obtenir celle de son rapport de
+// vérification (voir VerifyBackup)
+const verifyReportSuffix = ".verify.json"
+
+// sidecarSuffixes liste les fichiers annexes stockés à côté de chaque sauvegarde
+var sidecarSuffixes = []string{manifestSuffix, verifyReportSuffix}
+
+// isSidecarKey indique que key est un fichier annexe et non une sauvegarde
+func isSidecarKey(key string) bool {
+	for _, suffix := range sidecarSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestFetchConcurrency limite le nombre de manifestes lus en parallèle
+const manifestFetchConcurrency = 8
+
+// CatalogFilter restreint le catalogue (champs vides = pas de filtre)
+type CatalogFilter struct {
+	Engine   string `json:"engine"`
+	Server   string `json:"server"`
+	Database string `json:"database"`
+}
+
+// engine retourne le moteur filtré sous sa forme canonique (les alias postgresql, Mongo...
+// acceptés par le reste de la CLI désignent le même moteur)
+func (f CatalogFilter) engine() string {
+	if engine := normalizeEngine(f.Engine); engine != "" {
+		return engine
+	}
+	return strings.ToLower(strings.TrimSpace(f.Engine))
+}
+
+func (f CatalogFilter) matches(b BackupResult) bool {
+	engine := f.engine()
+	return (engine == "" || engine == b.Engine) &&
+		(f.Server == "" || sanitizeKeyPart(f.Server) == sanitizeKeyPart(b.Server)) &&
+		(f.Database == "" || f.Database == b.Database)
+}
+
+// CatalogEntry est une sauvegarde du dépôt telle qu'affichée par le catalogue
+type CatalogEntry struct {
+	Backup BackupResult `json:"backup"`
+	// ObjectSize est la taille de l'objet dans le bucket
+	ObjectSize   int64     `json:"objectSize"`
+	LastModified time.Time `json:"lastModified"`
+	StorageClass string    `json:"storageClass"`
+	// Archived indique une sauvegarde à restaurer (ThawBackup) avant de la lire
+	Archived bool `json:"archived"`
+	// HasManifest est faux pour les sauvegardes antérieures au catalogue
+	HasManifest bool `json:"hasManifest"`
+}
+
+// manifestKey retourne la clé du manifeste d'une sauvegarde
+func manifestKey(key string) string {
+	return key + manifestSuffix
+}
+
+// compressionFromName déduit la compression d'une sauvegarde de son extension
+func compressionFromName(name string) Compression {
+	switch {
+	case strings.HasSuffix(name, ".gz"), strings.HasSuffix(name, ".tgz"):
+		return CompressionGzip
+	case strings.HasSuffix(name, ".zst"):
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+// writeManifest enregistre le manifeste d'une sauvegarde à côté de celle-ci
+func (r *backupRepository) writeManifest(ctx context.Context, result *BackupResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := r.storage.Put(ctx, manifestKey(result.Key), bytes.NewReader(data), int64(len(data)), nil); err != nil {
+		return fmt.Errorf("erreur écriture du manifeste de %s: %v", result.Key, err)
+	}
+	return nil
+}
+
+// readManifest lit le manifeste d'une sauvegarde
+func (r *backupRepository) readManifest(ctx context.Context, key string) (*BackupResult, error) {
+	body, _, err := r.storage.Open(ctx, manifestKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture du manifeste de %s: %v", key, err)
+	}
+	defer body.Close()
+	var result BackupResult
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("manifeste de %s invalide: %v", key, err)
+	}
+	result.Bucket = r.bucket
+	result.Key = key
+	return &result, nil
+}
+
+// parseBackupKey retrouve la source d'une sauvegarde à partir de sa clé
+// (<prefix>/<engine>/<server>[/<database>]/<horodatage><ext>)
+func parseBackupKey(prefix, key string) (BackupKey, bool) {
+	rel := key
+	if prefix != "" {
+		if !strings.HasPrefix(key, prefix+"/") {
+			return BackupKey{}, false
+		}
+		rel = strings.TrimPrefix(key, prefix+"/")
+	}
+	parts := strings.Split(rel, "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		return BackupKey{}, false
+	}
+	name := parts[len(parts)-1]
+	t, ok := parseBackupTime(name)
+	if !ok {
+		return BackupKey{}, false
+	}
+	k := BackupKey{Prefix: prefix, Engine: parts[0], Server: parts[1], Time: t, Ext: name[len(backupTimeLayout):]}
+	if len(parts) == 4 {
+		k.Database = parts[2]
+	}
+	return k, true
+}
+
+// ListBackupCatalog liste les sauvegardes du dépôt de backups, les plus récentes en premier
+func ListBackupCatalog(ctx context.Context, filter CatalogFilter) ([]CatalogEntry, error) {
+	repo, err := openBackupRepository(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return repo.catalog(ctx, filter)
+}
+
+func (r *backupRepository) catalog(ctx context.Context, filter CatalogFilter) ([]CatalogEntry, error) {
+	// Le préfixe listé suit la hiérarchie des clés tant que les filtres la précisent
+	listPrefix := BackupKey{Prefix: r.prefix}.Dir()
+	for _, part := range []string{filter.engine(), filter.Server, filter.Database} {
+		if part == "" {
+			break
+		}
+		listPrefix = strings.TrimPrefix(listPrefix+"/"+sanitizeKeyPart(part), "/")
+	}
+	if listPrefix != "" {
+		listPrefix += "/"
+	}
+
+	var entries []CatalogEntry
+	manifests := map[string]bool{}
+	objects, err := r.storage.List(ctx, listPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		key := obj.Key
+		if strings.HasSuffix(key, manifestSuffix) {
+			manifests[strings.TrimSuffix(key, manifestSuffix)] = true
+			continue
+		}
+		if isSidecarKey(key) {
+			continue
+		}
+		parsed, ok := parseBackupKey(r.prefix, key)
+		if !ok {
+			continue
+		}
+		entries = append(entries, CatalogEntry{
+			Backup: BackupResult{
+				Bucket:      r.bucket,
+				Key:         key,
+				Engine:      parsed.Engine,
+				Server:      parsed.Server,
+				Database:    parsed.Database,
+				Size:        obj.Size,
+				Compression: string(compressionFromName(key)),
+				CreatedAt:   parsed.Time,
+			},
+			ObjectSize:   obj.Size,
+			LastModified: obj.LastModified,
+			StorageClass: storageClassLabel(obj.StorageClass),
+			Archived:     isArchivedStorageClass(obj.StorageClass),
+		})
+	}
+
+	// Complète les entrées avec leur manifeste, en parallèle
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, manifestFetchConcurrency)
+	for i := range entries {
+		if !manifests[entries[i].Backup.Key] {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(entry *CatalogEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			manifest, err := r.readManifest(ctx, entry.Backup.Key)
+			if err != nil {
+				Log.Warn(err.Error())
+				return
+			}
+			entry.Backup = *manifest
+			entry.HasManifest = true
+		}(&entries[i])
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filtered := entries[:0]
+	for _, entry := range entries {
+		if filter.matches(entry.Backup) {
+			filtered = append(filtered, entry)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Backup.CreatedAt.After(filtered[j].Backup.CreatedAt) })
+	return filtered, nil
+}
@@ -0,0 +1,30 @@
+package backend
+
+import "testing"
+
+func TestCatalogFilterEngineAliases(t *testing.T) {
+	pg := BackupResult{Engine: EnginePostgres, Server: "prod", Database: "app"}
+	mongo := BackupResult{Engine: EngineMongo, Server: "prod"}
+	project := BackupResult{Engine: BackupKindProject, Server: "site"}
+
+	cases := []struct {
+		filter CatalogFilter
+		backup BackupResult
+		want   bool
+	}{
+		{CatalogFilter{}, pg, true},
+		{CatalogFilter{Engine: "postgres"}, pg, true},
+		{CatalogFilter{Engine: "postgresql"}, pg, true},
+		{CatalogFilter{Engine: " PG "}, pg, true},
+		{CatalogFilter{Engine: "Mongo"}, mongo, true},
+		{CatalogFilter{Engine: "mongodb"}, mongo, true},
+		{CatalogFilter{Engine: "mongodb"}, pg, false},
+		{CatalogFilter{Engine: "Project"}, project, true},
+		{CatalogFilter{Engine: "postgresql", Database: "other"}, pg, false},
+	}
+	for _, c := range cases {
+		if got := c.filter.matches(c.backup); got != c.want {
+			t.Errorf("%+v.matches(%s) = %t, attendu %t", c.filter, c.backup.Engine, got, c.want)
+		}
+	}
+}
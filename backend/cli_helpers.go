@@ -23,13 +23,13 @@ func CleanSubmoduleName(submodule string) (string, error) {
 func NpmUpdateAction(path string) error {
 	initialDir, err := os.Getwd()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur récupération répertoire courant: %v", err))
+		Log.Error(fmt.Sprintf("Erreur récupération répertoire courant: %v", err))
 		return fmt.Errorf("erreur lors de la récupération du répertoire courant: %v", err)
 	}
 
 	if path != "" && path != "." {
 		if err := os.Chdir(path); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur changement de répertoire vers %s: %v", path, err))
+			Log.Error(fmt.Sprintf("Erreur changement de répertoire vers %s: %v", path, err))
 			return fmt.Errorf("erreur lors du changement de répertoire vers %s: %v", path, err)
 		}
 	}
@@ -37,18 +37,18 @@ func NpmUpdateAction(path string) error {
 
 	submodules, err := ListSubmodule(".")
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur lors de la liste des submodules: %v", err))
+		Log.Error(fmt.Sprintf("Erreur lors de la liste des submodules: %v", err))
 		return fmt.Errorf("erreur lors de la liste des submodules: %v", err)
 	}
 
-	LogToFrontend("info", fmt.Sprintf("Mise à jour NPM pour %d submodules", len(submodules)))
+	Log.Info(fmt.Sprintf("Mise à jour NPM pour %d submodules", len(submodules)))
 
 	for _, submodule := range submodules {
 		packageJSONPath := filepath.Join(submodule, "package.json")
 		if _, err := os.Stat(packageJSONPath); !os.IsNotExist(err) {
-			LogToFrontend("info", fmt.Sprintf("Mise à jour NPM dans %s", submodule))
+			Log.Info(fmt.Sprintf("Mise à jour NPM dans %s", submodule))
 			if err := execCommand("npm", "-C", submodule, "update"); err != nil {
-				LogToFrontend("warning", fmt.Sprintf("Échec mise à jour NPM dans %s: %v", submodule, err))
+				Log.Warn(fmt.Sprintf("Échec mise à jour NPM dans %s: %v", submodule, err))
 			}
 		}
 	}
@@ -70,16 +70,16 @@ func GitUpdateAction(path string, submodules []string) error {
 	}
 	defer os.Chdir(initialDir)
 
-	LogToFrontend("info", fmt.Sprintf("Mise à jour git pour %d submodules", len(submodules)))
+	Log.Info(fmt.Sprintf("Mise à jour git pour %d submodules", len(submodules)))
 
 	for _, submodule := range submodules {
 		// Nettoyer le chemin du submodule pour éviter les doubles slashes
 		cleanPath := strings.TrimPrefix(submodule, path+"/")
 		cleanPath = strings.TrimPrefix(cleanPath, "./")
 
-		LogToFrontend("info", fmt.Sprintf("Git pull dans %s", cleanPath))
+		Log.Info(fmt.Sprintf("Git pull dans %s", cleanPath))
 		if err := execCommand("git", "-C", cleanPath, "pull"); err != nil {
-			LogToFrontend("warning", fmt.Sprintf("Échec git pull dans %s: %v", cleanPath, err))
+			Log.Warn(fmt.Sprintf("Échec git pull dans %s: %v", cleanPath, err))
 		}
 	}
 
@@ -0,0 +1,345 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile est le nom du fichier de configuration placé à la racine d'un projet
+const ProjectConfigFile = ".aidalinfo.yaml"
+
+// Config regroupe la configuration effective de l'outil.
+// Ordre de priorité (du plus faible au plus fort) : valeurs par défaut, config utilisateur,
+// fichier .aidalinfo.yaml du projet, variables d'environnement AIDALINFO_*, puis flags CLI.
+type Config struct {
+	Git       GitConfig               `yaml:"git" json:"git"`
+	Npm       NpmConfig               `yaml:"npm" json:"npm"`
+	Backup    BackupConfig            `yaml:"backup" json:"backup"`
+	Servers   map[string]ServerConfig `yaml:"servers,omitempty" json:"servers"`
+	Schedules []ScheduleConfig        `yaml:"schedules,omitempty" json:"schedules"`
+}
+
+// GitConfig contient les options liées aux sous-modules
+type GitConfig struct {
+	// Branches à essayer (dans l'ordre) avant de retomber sur la branche par défaut du remote
+	Branches []string `yaml:"branches,omitempty" json:"branches"`
+	// Sous-modules ignorés (chemin relatif ou motif glob sur le nom)
+	ExcludeSubmodules []string `yaml:"excludeSubmodules,omitempty" json:"excludeSubmodules"`
+}
+
+// NpmConfig contient le comportement de npm lors des installations
+type NpmConfig struct {
+	// Install lance npm install après l'installation des sous-modules (équivalent de --npm)
+	Install *bool `yaml:"install,omitempty" json:"install"`
+	// InstallArgs remplace les arguments passés à npm (par défaut: install --no-save)
+	InstallArgs []string `yaml:"installArgs,omitempty" json:"installArgs"`
+	// SkipDirs liste les dossiers ignorés lors du parcours récursif
+	SkipDirs []string `yaml:"skipDirs,omitempty" json:"skipDirs"`
+}
+
+// BackupConfig décrit les cibles de sauvegarde par défaut
+type BackupConfig struct {
+	S3        S3TargetConfig `yaml:"s3" json:"s3"`
+	LocalPath string         `yaml:"localPath,omitempty" json:"localPath"`
+	// Repository, s'il est renseigné, est un dossier (NAS monté, disque hors ligne) qui remplace
+	// S3 comme dépôt de backups
+	Repository string `yaml:"repository,omitempty" json:"repository"`
+	// WorkDir reçoit, dans son sous-dossier aidalinfo-cli, les dumps, archives et téléchargements
+	// temporaires (par défaut le dossier de cache de l'utilisateur)
+	WorkDir string `yaml:"workDir,omitempty" json:"workDir"`
+	// Compression des archives de projet : gzip (par défaut) ou zstd
+	Compression string `yaml:"compression,omitempty" json:"compression"`
+	// EncryptionKey est l'identifiant de la clé du coffre (backup-key/<id>) qui chiffre les
+	// sauvegardes envoyées vers le dépôt ; vide, elles ne sont pas chiffrées
+	EncryptionKey string `yaml:"encryptionKey,omitempty" json:"encryptionKey"`
+}
+
+// S3TargetConfig remplace les valeurs S3 codées en dur (endpoint Scaleway, bucket backup-global)
+type S3TargetConfig struct {
+	Host     string `yaml:"host,omitempty" json:"host"`
+	Port     string `yaml:"port,omitempty" json:"port"`
+	Region   string `yaml:"region,omitempty" json:"region"`
+	Bucket   string `yaml:"bucket,omitempty" json:"bucket"`
+	Prefix   string `yaml:"prefix,omitempty" json:"prefix"`
+	UseHttps *bool  `yaml:"useHttps,omitempty" json:"useHttps"`
+	// Profile est un profil AWS (~/.aws/config) utilisé quand aucune clé n'est fournie
+	Profile string `yaml:"profile,omitempty" json:"profile"`
+	// VirtualHost adresse les buckets en bucket.host au lieu de host/bucket
+	VirtualHost *bool `yaml:"virtualHost,omitempty" json:"virtualHost"`
+	// CABundle est un fichier PEM d'autorités de certification supplémentaires
+	CABundle string `yaml:"caBundle,omitempty" json:"caBundle"`
+}
+
+// ServerConfig décrit un serveur de base de données nommé.
+// Le mot de passe n'est jamais écrit dans le fichier : SecretRef désigne une entrée du coffre.
+type ServerConfig struct {
+	Engine       string `yaml:"engine" json:"engine"`
+	Host         string `yaml:"host" json:"host"`
+	Port         string `yaml:"port,omitempty" json:"port"`
+	User         string `yaml:"user,omitempty" json:"user"`
+	AuthDatabase string `yaml:"authDatabase,omitempty" json:"authDatabase"`
+	SecretRef    string `yaml:"secretRef,omitempty" json:"secretRef"`
+}
+
+// ScheduleConfig décrit une sauvegarde planifiée exécutée par le démon (aidalinfo-cli daemon)
+type ScheduleConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Cron est une expression à 5 champs ("0 3 * * *") ou un descripteur (@daily, @every 6h)
+	Cron string `yaml:"cron" json:"cron"`
+	// Type est le moteur sauvegardé : mongo, mysql, postgres, project ou s3
+	Type string `yaml:"type" json:"type"`
+	// Server est le profil source (voir 'server list'), requis sauf pour project
+	Server string `yaml:"server,omitempty" json:"server"`
+	// Database est la base à exporter (mongo, mysql, postgres)
+	Database string `yaml:"database,omitempty" json:"database"`
+	// Bucket est le bucket copié par un snapshot S3 (par défaut celui du profil)
+	Bucket string `yaml:"bucket,omitempty" json:"bucket"`
+	// Path est le dossier archivé par une sauvegarde project (par défaut le projet courant)
+	Path      string          `yaml:"path,omitempty" json:"path"`
+	Retention RetentionPolicy `yaml:"retention,omitempty" json:"retention"`
+}
+
+// RetentionPolicy indique combien de sauvegardes conserver par jour, semaine et mois.
+// Une politique vide conserve toutes les sauvegardes.
+type RetentionPolicy struct {
+	Daily   int `yaml:"daily,omitempty" json:"daily"`
+	Weekly  int `yaml:"weekly,omitempty" json:"weekly"`
+	Monthly int `yaml:"monthly,omitempty" json:"monthly"`
+}
+
+var (
+	activeConfig   = DefaultConfig()
+	activeConfigMu sync.RWMutex
+)
+
+// DefaultConfig retourne la configuration par défaut (comportement historique de l'outil)
+func DefaultConfig() *Config {
+	return &Config{
+		Npm: NpmConfig{
+			InstallArgs: []string{"install", "--no-save"},
+			SkipDirs:    []string{"node_modules", ".git"},
+		},
+		Backup: BackupConfig{
+			S3: S3TargetConfig{
+				Host:   S3BaseURL,
+				Region: S3Region,
+				Bucket: "backup-global",
+				Prefix: "cli-backups",
+			},
+		},
+		Servers: map[string]ServerConfig{},
+	}
+}
+
+// CurrentConfig retourne la configuration active
+func CurrentConfig() *Config {
+	activeConfigMu.RLock()
+	defer activeConfigMu.RUnlock()
+	return activeConfig
+}
+
+// SetConfig remplace la configuration active
+func SetConfig(cfg *Config) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	activeConfigMu.Lock()
+	activeConfig = cfg
+	activeConfigMu.Unlock()
+}
+
+// UserConfigDir retourne le dossier de configuration utilisateur de l'application
+func UserConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("erreur récupération dossier de configuration: %v", err)
+	}
+	return filepath.Join(dir, "aidalinfo-cli"), nil
+}
+
+// UserConfigPath retourne le chemin du fichier de configuration utilisateur
+func UserConfigPath() (string, error) {
+	dir, err := UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// LoadConfig charge et fusionne la configuration utilisateur, celle du projet et l'environnement
+func LoadConfig(projectPath string) (*Config, error) {
+	cfg := DefaultConfig()
+
+	if userPath, err := UserConfigPath(); err == nil {
+		if err := mergeConfigFile(cfg, userPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if projectPath == "" {
+		projectPath = "."
+	}
+	if err := mergeConfigFile(cfg, filepath.Join(projectPath, ProjectConfigFile)); err != nil {
+		return nil, err
+	}
+
+	mergeConfigEnv(cfg)
+	return cfg, nil
+}
+
+// mergeConfigFile fusionne un fichier YAML dans cfg, un fichier absent n'est pas une erreur
+func mergeConfigFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erreur lecture de %s: %v", path, err)
+	}
+
+	var fileCfg Config
+	if err := yaml.Unmarshal(data, &fileCfg); err != nil {
+		return fmt.Errorf("erreur de syntaxe dans %s: %v", path, err)
+	}
+	cfg.merge(&fileCfg)
+	return nil
+}
+
+// merge applique les valeurs non vides de other sur c
+func (c *Config) merge(other *Config) {
+	if len(other.Git.Branches) > 0 {
+		c.Git.Branches = other.Git.Branches
+	}
+	if len(other.Git.ExcludeSubmodules) > 0 {
+		c.Git.ExcludeSubmodules = other.Git.ExcludeSubmodules
+	}
+
+	if other.Npm.Install != nil {
+		c.Npm.Install = other.Npm.Install
+	}
+	if len(other.Npm.InstallArgs) > 0 {
+		c.Npm.InstallArgs = other.Npm.InstallArgs
+	}
+	if len(other.Npm.SkipDirs) > 0 {
+		c.Npm.SkipDirs = other.Npm.SkipDirs
+	}
+
+	s3 := &c.Backup.S3
+	mergeString(&s3.Host, other.Backup.S3.Host)
+	mergeString(&s3.Port, other.Backup.S3.Port)
+	mergeString(&s3.Region, other.Backup.S3.Region)
+	mergeString(&s3.Bucket, other.Backup.S3.Bucket)
+	mergeString(&s3.Prefix, other.Backup.S3.Prefix)
+	if other.Backup.S3.UseHttps != nil {
+		s3.UseHttps = other.Backup.S3.UseHttps
+	}
+	mergeString(&s3.Profile, other.Backup.S3.Profile)
+	if other.Backup.S3.VirtualHost != nil {
+		s3.VirtualHost = other.Backup.S3.VirtualHost
+	}
+	mergeString(&s3.CABundle, other.Backup.S3.CABundle)
+	mergeString(&c.Backup.LocalPath, other.Backup.LocalPath)
+	mergeString(&c.Backup.Repository, other.Backup.Repository)
+	mergeString(&c.Backup.WorkDir, other.Backup.WorkDir)
+	mergeString(&c.Backup.Compression, other.Backup.Compression)
+	mergeString(&c.Backup.EncryptionKey, other.Backup.EncryptionKey)
+
+	if c.Servers == nil {
+		c.Servers = map[string]ServerConfig{}
+	}
+	for name, server := range other.Servers {
+		c.Servers[name] = server
+	}
+	if len(other.Schedules) > 0 {
+		c.Schedules = other.Schedules
+	}
+}
+
+func mergeString(dst *string, value string) {
+	if strings.TrimSpace(value) != "" {
+		*dst = strings.TrimSpace(value)
+	}
+}
+
+// mergeConfigEnv applique les variables d'environnement AIDALINFO_*
+func mergeConfigEnv(cfg *Config) {
+	if v := os.Getenv("AIDALINFO_BRANCHES"); v != "" {
+		cfg.Git.Branches = strings.Fields(v)
+	}
+	if v := os.Getenv("AIDALINFO_EXCLUDE_SUBMODULES"); v != "" {
+		cfg.Git.ExcludeSubmodules = splitList(v)
+	}
+	if v := os.Getenv("AIDALINFO_NPM_INSTALL"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.Npm.Install = &b
+		}
+	}
+	mergeString(&cfg.Backup.S3.Host, os.Getenv("AIDALINFO_S3_HOST"))
+	mergeString(&cfg.Backup.S3.Port, os.Getenv("AIDALINFO_S3_PORT"))
+	mergeString(&cfg.Backup.S3.Region, os.Getenv("AIDALINFO_S3_REGION"))
+	mergeString(&cfg.Backup.S3.Bucket, os.Getenv("AIDALINFO_S3_BUCKET"))
+	mergeString(&cfg.Backup.S3.Prefix, os.Getenv("AIDALINFO_S3_PREFIX"))
+	if v := os.Getenv("AIDALINFO_S3_USE_HTTPS"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.Backup.S3.UseHttps = &b
+		}
+	}
+	mergeString(&cfg.Backup.S3.Profile, os.Getenv("AIDALINFO_S3_PROFILE"))
+	if v := os.Getenv("AIDALINFO_S3_VIRTUAL_HOST"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.Backup.S3.VirtualHost = &b
+		}
+	}
+	mergeString(&cfg.Backup.S3.CABundle, os.Getenv("AIDALINFO_S3_CA_BUNDLE"))
+	mergeString(&cfg.Backup.LocalPath, os.Getenv("AIDALINFO_BACKUP_LOCAL_PATH"))
+	mergeString(&cfg.Backup.Repository, os.Getenv("AIDALINFO_BACKUP_REPOSITORY"))
+	mergeString(&cfg.Backup.WorkDir, os.Getenv("AIDALINFO_BACKUP_WORK_DIR"))
+	mergeString(&cfg.Backup.Compression, os.Getenv("AIDALINFO_BACKUP_COMPRESSION"))
+	mergeString(&cfg.Backup.EncryptionKey, os.Getenv("AIDALINFO_BACKUP_ENCRYPTION_KEY"))
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// NpmInstallEnabled indique si npm install doit suivre l'installation des sous-modules
+func (c *Config) NpmInstallEnabled() bool {
+	return c.Npm.Install != nil && *c.Npm.Install
+}
+
+// IsSubmoduleExcluded indique si un sous-module est exclu par la configuration
+func (c *Config) IsSubmoduleExcluded(submodulePath string) bool {
+	clean := filepath.ToSlash(filepath.Clean(submodulePath))
+	base := filepath.Base(clean)
+	for _, pattern := range c.Git.ExcludeSubmodules {
+		pattern = filepath.ToSlash(strings.TrimSuffix(pattern, "/"))
+		if pattern == clean || pattern == base || strings.HasSuffix(clean, "/"+pattern) {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Marshal sérialise la configuration en YAML (utilisé par la commande config)
+func (c *Config) Marshal() (string, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
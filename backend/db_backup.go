@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// BackupDatabase exporte database depuis le serveur profile puis l'envoie vers le dépôt de
+// backups sous <prefix>/<engine>/<serveur>/<database>/<horodatage>.<ext>, avec moteur, version,
+// taille et empreinte SHA-256 en métadonnées.
+func BackupDatabase(ctx context.Context, profile *ServerProfile, database string) (*BackupResult, error) {
+	if database == "" {
+		return nil, fmt.Errorf("la base à sauvegarder est requise")
+	}
+	repo, err := openBackupRepository(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	key := BackupKey{
+		Prefix:   repo.prefix,
+		Engine:   profile.Engine,
+		Server:   profile.Name,
+		Database: database,
+		Time:     time.Now(),
+	}
+	var dumpPath string
+	switch profile.Engine {
+	case EngineMongo:
+		key.Ext = ".bson.gz"
+		dumpPath, err = DumpMongoDatabase(ctx, profile.Host, profile.Port, profile.User, profile.Password, database)
+	case EngineMySQL:
+		key.Ext = ".sql.gz"
+		dumpPath, err = DumpMySQLDatabase(ctx, profile.Host, profile.Port, profile.User, profile.Password, database)
+	case EnginePostgres:
+		key.Ext = ".sql.gz"
+		dumpPath, err = DumpPostgresDatabase(ctx, profile.Host, profile.Port, profile.User, profile.Password, database)
+	default:
+		return nil, fmt.Errorf("le serveur '%s' n'est pas une base de données (%s)", profile.Name, profile.Engine)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(dumpPath)
+
+	// La version du serveur est informative : un échec n'empêche pas la sauvegarde
+	version, err := DatabaseServerVersion(ctx, profile)
+	if err != nil {
+		Log.Warn(fmt.Sprintf("Version du serveur %s indisponible: %v", profile.Name, err))
+	}
+
+	return repo.storeBackup(ctx, dumpPath, key, version)
+}
+
+// DatabaseServerVersion retourne la version du serveur MongoDB, MySQL ou PostgreSQL
+func DatabaseServerVersion(ctx context.Context, profile *ServerProfile) (string, error) {
+	var output []byte
+	var err error
+	switch profile.Engine {
+	case EngineMongo:
+		var version string
+		err = withMongoProfile(ctx, profile, func(client *mongo.Client) error {
+			version, err = mongoServerVersion(ctx, client)
+			return err
+		})
+		return version, err
+	case EngineMySQL:
+		args, cleanup, argsErr := mysqlClientArgs(profile.Host, profile.Port, profile.User, profile.Password)
+		if argsErr != nil {
+			return "", argsErr
+		}
+		defer cleanup()
+		output, err = exec.CommandContext(ctx, "mysql", append(args, "-N", "-B", "-e", "SELECT VERSION();")...).Output()
+	case EnginePostgres:
+		cmd := exec.CommandContext(ctx, "psql",
+			"-h", profile.Host,
+			"-p", profile.Port,
+			"-U", profile.User,
+			"-d", "postgres",
+			"-t", "-A",
+			"-c", "SHOW server_version")
+		cmd.Env = postgresEnv(profile.Password)
+		output, err = cmd.Output()
+	default:
+		return "", fmt.Errorf("moteur sans version: %s", profile.Engine)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
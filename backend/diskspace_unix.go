@@ -0,0 +1,15 @@
+//go:build !windows
+
+package backend
+
+import "golang.org/x/sys/unix"
+
+// diskFreeSpace retourne l'espace disponible (pour un utilisateur non privilégié) sur le
+// système de fichiers de path
+func diskFreeSpace(path string) (uint64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package backend
+
+import "golang.org/x/sys/windows"
+
+// diskFreeSpace retourne l'espace disponible pour l'utilisateur courant sur le volume de path
+func diskFreeSpace(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &available, &total, &free); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
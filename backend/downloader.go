@@ -0,0 +1,408 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Les backups sont téléchargés par plages d'octets (GetObject avec Range) lues en parallèle.
+// Vers un fichier, chaque plage terminée est enregistrée avec son empreinte dans un fichier
+// d'état (<fichier>.download.json) : un téléchargement interrompu, même par l'arrêt de la CLI,
+// reprend aux plages manquantes. En flux (restaurations Postgres/MySQL, vérification), les
+// plages sont lues en avance et rendues dans l'ordre, sans fichier temporaire.
+
+const (
+	// downloadPartSize est la taille d'une plage
+	downloadPartSize = 8 * 1024 * 1024
+	// downloadConcurrency est le nombre de plages lues en parallèle
+	downloadConcurrency = 4
+	// downloadPartAttempts est le nombre de tentatives par plage
+	downloadPartAttempts = 4
+	// downloadStateSuffix est l'extension du fichier d'état d'un téléchargement
+	downloadStateSuffix = ".download.json"
+)
+
+// downloadPart est une plage d'un objet. SHA256 n'est renseigné qu'une fois la plage écrite.
+type downloadPart struct {
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// downloadState est le fichier d'état d'un téléchargement. Il n'est réutilisé que pour le même
+// objet (ETag et taille identiques) et le même découpage.
+type downloadState struct {
+	Bucket   string         `json:"bucket"`
+	Key      string         `json:"key"`
+	ETag     string         `json:"etag"`
+	Size     int64          `json:"size"`
+	PartSize int64          `json:"partSize"`
+	Parts    []downloadPart `json:"parts"`
+}
+
+func (s *downloadState) sameObject(other *downloadState) bool {
+	return s.Bucket == other.Bucket && s.Key == other.Key && s.ETag == other.ETag &&
+		s.Size == other.Size && s.PartSize == other.PartSize && len(s.Parts) == len(other.Parts)
+}
+
+// rangedDownloader lit un objet S3 par plages
+type rangedDownloader struct {
+	client *s3.Client
+	bucket string
+	key    string
+	etag   string
+	size   int64
+	// sha256 est l'empreinte de l'objet complet si elle figure dans ses métadonnées
+	sha256 string
+}
+
+// newRangedDownloader prépare la lecture de key. L'objet doit être lisible (pas archivé).
+func newRangedDownloader(ctx context.Context, client *s3.Client, bucket, key string) (*rangedDownloader, error) {
+	if err := ensureRetrievable(ctx, client, bucket, key); err != nil {
+		return nil, err
+	}
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("objet introuvable %s/%s: %v", bucket, key, err)
+	}
+	return &rangedDownloader{
+		client: client,
+		bucket: bucket,
+		key:    key,
+		etag:   aws.ToString(head.ETag),
+		size:   derefInt64(head.ContentLength),
+		sha256: head.Metadata[MetaSHA256],
+	}, nil
+}
+
+// parts découpe l'objet en plages de downloadPartSize
+func (d *rangedDownloader) parts() []downloadPart {
+	var parts []downloadPart
+	for offset := int64(0); offset < d.size; offset += downloadPartSize {
+		parts = append(parts, downloadPart{Offset: offset, Length: min(downloadPartSize, d.size-offset)})
+	}
+	return parts
+}
+
+// newState retourne l'état d'un téléchargement qui commence
+func (d *rangedDownloader) newState() *downloadState {
+	return &downloadState{Bucket: d.bucket, Key: d.key, ETag: d.etag, Size: d.size, PartSize: downloadPartSize, Parts: d.parts()}
+}
+
+// remainingBytes retourne le nombre d'octets qu'il reste à écrire dans destPath : les plages que
+// le fichier d'état ne marque pas comme terminées. La taille du fichier ne l'indique pas, car il
+// est agrandi à la taille de l'objet dès le début du téléchargement.
+func (d *rangedDownloader) remainingBytes(destPath string) int64 {
+	previous, err := readDownloadState(destPath + downloadStateSuffix)
+	if err != nil || !previous.sameObject(d.newState()) {
+		return d.size
+	}
+	var remaining int64
+	for _, part := range previous.Parts {
+		if part.SHA256 == "" {
+			remaining += part.Length
+		}
+	}
+	return remaining
+}
+
+// getRange lit une plage, avec plusieurs tentatives. If-Match fait échouer la lecture si
+// l'objet a été remplacé pendant le téléchargement.
+func (d *rangedDownloader) getRange(ctx context.Context, part downloadPart) ([]byte, error) {
+	var lastErr error
+	for attempt := 1; attempt <= downloadPartAttempts; attempt++ {
+		if attempt > 1 {
+			Log.Debug(fmt.Sprintf("Nouvelle tentative (%d/%d) pour la plage %d de %s: %v", attempt, downloadPartAttempts, part.Offset, d.key, lastErr))
+			if err := sleepContext(ctx, time.Duration(attempt*attempt)*time.Second); err != nil {
+				return nil, err
+			}
+		}
+		input := &s3.GetObjectInput{
+			Bucket: aws.String(d.bucket),
+			Key:    aws.String(d.key),
+			Range:  aws.String(fmt.Sprintf("bytes=%d-%d", part.Offset, part.Offset+part.Length-1)),
+		}
+		if d.etag != "" {
+			input.IfMatch = aws.String(d.etag)
+		}
+		out, err := d.client.GetObject(ctx, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		data := make([]byte, part.Length)
+		_, err = io.ReadFull(out.Body, data)
+		out.Body.Close()
+		if err == nil {
+			return data, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("erreur téléchargement de %s (octets %d-%d) après %d tentatives: %v",
+		d.key, part.Offset, part.Offset+part.Length-1, downloadPartAttempts, lastErr)
+}
+
+// downloadFile télécharge l'objet dans destPath, en reprenant un téléchargement interrompu
+// si le fichier d'état correspond au même objet
+func (d *rangedDownloader) downloadFile(ctx context.Context, destPath string) error {
+	statePath := destPath + downloadStateSuffix
+	state := d.newState()
+
+	flags := os.O_RDWR | os.O_CREATE
+	if previous, err := readDownloadState(statePath); err == nil && previous.sameObject(state) {
+		state = previous
+	} else {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(destPath, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("erreur création fichier: %v", err)
+	}
+	defer f.Close()
+	if err := f.Truncate(d.size); err != nil {
+		return fmt.Errorf("erreur création fichier: %v", err)
+	}
+
+	// Les plages déjà écrites sont relues pour s'assurer que le fichier n'a pas été modifié
+	var resumed int64
+	for i := range state.Parts {
+		part := &state.Parts[i]
+		if part.SHA256 == "" {
+			continue
+		}
+		if sum, err := hashFileRange(f, part.Offset, part.Length); err != nil || sum != part.SHA256 {
+			part.SHA256 = ""
+			continue
+		}
+		resumed += part.Length
+	}
+	if resumed > 0 {
+		Log.Info(fmt.Sprintf("Reprise du téléchargement de %s: %.2f MB sur %.2f MB déjà présents",
+			d.key, float64(resumed)/(1024*1024), float64(d.size)/(1024*1024)))
+	}
+
+	progress := NewProgress(ctx, PhaseDownload, d.key, d.size)
+	progress.Add(resumed)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+	sem := make(chan struct{}, downloadConcurrency)
+	for i := range state.Parts {
+		if state.Parts[i].SHA256 != "" {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			mu.Lock()
+			part := state.Parts[index]
+			mu.Unlock()
+			data, err := d.getRange(ctx, part)
+			if err != nil {
+				fail(err)
+				return
+			}
+			if _, err := f.WriteAt(data, part.Offset); err != nil {
+				fail(fmt.Errorf("erreur écriture fichier: %v", err))
+				return
+			}
+			sum := sha256.Sum256(data)
+			progress.Add(int64(len(data)))
+
+			mu.Lock()
+			defer mu.Unlock()
+			state.Parts[index].SHA256 = hex.EncodeToString(sum[:])
+			if err := writeDownloadState(statePath, state); err != nil {
+				Log.Warn(fmt.Sprintf("Impossible d'enregistrer l'état du téléchargement: %v", err))
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		Log.Warn(fmt.Sprintf("Téléchargement de %s interrompu, il reprendra au prochain lancement", d.key))
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("erreur écriture fichier: %v", err)
+	}
+
+	if d.sha256 != "" {
+		sum, err := hashFileRange(f, 0, d.size)
+		if err != nil {
+			return fmt.Errorf("erreur lecture de %s: %v", destPath, err)
+		}
+		if sum != d.sha256 {
+			os.Remove(statePath)
+			return fmt.Errorf("empreinte SHA-256 de %s invalide (attendu %s, obtenu %s)", d.key, d.sha256, sum)
+		}
+	}
+	progress.Finish()
+	if err := os.Remove(statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		Log.Warn(fmt.Sprintf("Impossible de supprimer %s: %v", statePath, err))
+	}
+	return nil
+}
+
+// stream retourne l'objet en flux. Jusqu'à downloadConcurrency plages sont lues en avance.
+func (d *rangedDownloader) stream(ctx context.Context) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+
+	type rangeResult struct {
+		data []byte
+		err  error
+	}
+	// pending conserve l'ordre des plages ; sa capacité borne la mémoire utilisée
+	pending := make(chan chan rangeResult, downloadConcurrency)
+	go func() {
+		defer close(pending)
+		for _, part := range d.parts() {
+			result := make(chan rangeResult, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+			go func(part downloadPart) {
+				data, err := d.getRange(ctx, part)
+				result <- rangeResult{data: data, err: err}
+			}(part)
+		}
+	}()
+	go func() {
+		for result := range pending {
+			res := <-result
+			if res.err == nil {
+				_, res.err = pw.Write(res.data)
+			}
+			if res.err != nil {
+				pw.CloseWithError(res.err)
+				cancel()
+				return
+			}
+		}
+		pw.CloseWithError(ctx.Err())
+	}()
+	return &rangedStream{PipeReader: pr, cancel: cancel}
+}
+
+// rangedStream interrompt les lectures en cours à la fermeture
+type rangedStream struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (s *rangedStream) Close() error {
+	s.cancel()
+	return s.PipeReader.Close()
+}
+
+// downloadS3Object télécharge key dans destPath (reprise possible après interruption) et
+// contrôle l'empreinte SHA-256 des métadonnées si elle existe
+func downloadS3Object(ctx context.Context, client *s3.Client, bucket, key, destPath string) error {
+	d, err := newRangedDownloader(ctx, client, bucket, key)
+	if err != nil {
+		return err
+	}
+	Log.Info(fmt.Sprintf("Taille du backup à télécharger: %.2f MB", float64(d.size)/(1024*1024)))
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("erreur création dossier de destination: %v", err)
+	}
+	// Un téléchargement repris occupe déjà la place de ses plages terminées
+	if err := ensureFreeSpace(filepath.Dir(destPath), d.remainingBytes(destPath), "le téléchargement de "+key); err != nil {
+		return err
+	}
+	return d.downloadFile(ctx, destPath)
+}
+
+// openRangedStream ouvre key en flux et retourne sa taille
+func openRangedStream(ctx context.Context, client *s3.Client, bucket, key string) (io.ReadCloser, int64, error) {
+	d, err := newRangedDownloader(ctx, client, bucket, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	return d.stream(ctx), d.size, nil
+}
+
+// resumableDownloadPath retourne le fichier de téléchargement d'un objet dans dir. Le nom
+// dépend uniquement de l'objet, pour qu'une restauration relancée reprenne le même fichier.
+func resumableDownloadPath(dir, bucket, key string) string {
+	sum := sha256.Sum256([]byte(bucket + "/" + key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+"-"+lastPathPart(key))
+}
+
+func readDownloadState(path string) (*downloadState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// writeDownloadState remplace le fichier d'état de façon atomique
+func writeDownloadState(path string, state *downloadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// hashFileRange retourne l'empreinte SHA-256 de length octets de f à partir de offset
+func hashFileRange(f *os.File, offset, length int64) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, offset, length)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
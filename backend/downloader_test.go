@@ -0,0 +1,39 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRangedDownloaderRemainingBytes(t *testing.T) {
+	d := &rangedDownloader{bucket: "b", key: "k.sql.gz", etag: `"abc"`, size: 3*downloadPartSize + 100}
+	dest := filepath.Join(t.TempDir(), "k.sql.gz")
+
+	if got := d.remainingBytes(dest); got != d.size {
+		t.Errorf("sans état = %d, attendu %d", got, d.size)
+	}
+
+	// Fichier agrandi à la taille de l'objet, une seule plage terminée
+	if err := os.WriteFile(dest, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(dest, d.size); err != nil {
+		t.Fatal(err)
+	}
+	state := d.newState()
+	state.Parts[1].SHA256 = "done"
+	if err := writeDownloadState(dest+downloadStateSuffix, state); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.remainingBytes(dest), d.size-downloadPartSize; got != want {
+		t.Errorf("reprise = %d, attendu %d", got, want)
+	}
+
+	// L'état d'un autre objet (ETag différent) n'est pas repris
+	other := *d
+	other.etag = `"def"`
+	if got := other.remainingBytes(dest); got != d.size {
+		t.Errorf("autre objet = %d, attendu %d", got, d.size)
+	}
+}
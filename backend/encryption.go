@@ -0,0 +1,357 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Les sauvegardes peuvent être chiffrées côté client (AES-256-GCM) avant leur envoi : la clé est
+// conservée dans le coffre sous backup-key/<id> et son identifiant est écrit dans l'en-tête du
+// flux chiffré et dans les métadonnées de la sauvegarde. Les restaurations détectent l'en-tête et
+// déchiffrent à la volée.
+//
+// Format : "AIDALENC" | version (1 octet) | taille des blocs (uint32) | préfixe de nonce (7 octets)
+// | longueur de l'identifiant (1 octet) | identifiant, puis des blocs scellés de blockSize octets
+// au plus. Le nonce d'un bloc est préfixe | compteur (uint32) | 1 pour le dernier bloc, 0 sinon ;
+// l'en-tête est authentifié avec chaque bloc. Un flux tronqué ou réordonné est donc refusé.
+
+// EncryptionAESGCM est la valeur de la métadonnée encryption d'une sauvegarde chiffrée
+const EncryptionAESGCM = "aes-256-gcm"
+
+const (
+	encryptionVersion   = 1
+	encryptionBlockSize = 64 * 1024
+	// encryptionNoncePrefixSize laisse 4 octets au compteur et 1 à l'indicateur de dernier bloc
+	encryptionNoncePrefixSize = 7
+	backupKeyRefPrefix        = "backup-key/"
+)
+
+var encryptionMagic = []byte("AIDALENC")
+
+// backupKeyIDPattern limite les identifiants de clé à des noms sûrs dans une référence du coffre
+var backupKeyIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ErrBackupKeyNotFound est retournée quand la clé d'une sauvegarde chiffrée n'est pas dans le coffre
+var ErrBackupKeyNotFound = errors.New("clé de chiffrement introuvable dans le coffre")
+
+func backupKeyRef(id string) string {
+	return backupKeyRefPrefix + id
+}
+
+// CreateBackupKey génère une clé de chiffrement des sauvegardes et l'enregistre dans le coffre.
+// Sans id, un identifiant daté est généré. La clé existante d'un même id n'est jamais remplacée :
+// les sauvegardes qu'elle protège seraient perdues.
+func CreateBackupKey(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		id = time.Now().UTC().Format("20060102") + "-" + hex.EncodeToString(suffix)
+	}
+	if !backupKeyIDPattern.MatchString(id) {
+		return "", fmt.Errorf("identifiant de clé invalide '%s' (lettres, chiffres, '.', '_' et '-')", id)
+	}
+	vault, err := DefaultVault()
+	if err != nil {
+		return "", err
+	}
+	refs, err := vault.List()
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref == backupKeyRef(id) {
+			return "", fmt.Errorf("la clé de chiffrement '%s' existe déjà", id)
+		}
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	if err := vault.Set(backupKeyRef(id), base64.StdEncoding.EncodeToString(key)); err != nil {
+		return "", err
+	}
+	Log.Success(fmt.Sprintf("Clé de chiffrement '%s' créée dans le coffre", id))
+	return id, nil
+}
+
+// ListBackupKeys retourne les identifiants des clés de chiffrement du coffre
+func ListBackupKeys() ([]string, error) {
+	vault, err := DefaultVault()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := vault.List()
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, ref := range refs {
+		if id, ok := strings.CutPrefix(ref, backupKeyRefPrefix); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// ExportBackupKey retourne la clé (base64) pour la conserver hors du poste : sans elle, les
+// sauvegardes chiffrées sont illisibles. Elle se réimporte avec vault set backup-key/<id>.
+func ExportBackupKey(id string) (string, error) {
+	key, err := loadBackupKey(id)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// loadBackupKey lit la clé id dans le coffre
+func loadBackupKey(id string) ([]byte, error) {
+	vault, err := DefaultVault()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := vault.Get(backupKeyRef(id))
+	if err != nil {
+		if errors.Is(err, ErrVaultLocked) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: '%s' (importez-la avec vault set %s)", ErrBackupKeyNotFound, id, backupKeyRef(id))
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("clé de chiffrement '%s' invalide (32 octets en base64 attendus)", id)
+	}
+	return key, nil
+}
+
+// backupEncryption est le chiffrement appliqué aux sauvegardes envoyées vers le dépôt
+type backupEncryption struct {
+	keyID string
+	key   []byte
+}
+
+// configuredBackupEncryption retourne le chiffrement configuré (backup.encryptionKey), ou nil
+// si les sauvegardes ne sont pas chiffrées
+func configuredBackupEncryption() (*backupEncryption, error) {
+	id := strings.TrimSpace(CurrentConfig().Backup.EncryptionKey)
+	if id == "" {
+		return nil, nil
+	}
+	key, err := loadBackupKey(id)
+	if err != nil {
+		return nil, err
+	}
+	return &backupEncryption{keyID: id, key: key}, nil
+}
+
+// encryptionHeader décrit l'en-tête d'un flux chiffré
+type encryptionHeader struct {
+	keyID       string
+	blockSize   int
+	noncePrefix []byte
+	raw         []byte
+}
+
+func (h encryptionHeader) marshal() []byte {
+	buf := append([]byte{}, encryptionMagic...)
+	buf = append(buf, encryptionVersion)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(h.blockSize))
+	buf = append(buf, h.noncePrefix...)
+	buf = append(buf, byte(len(h.keyID)))
+	return append(buf, h.keyID...)
+}
+
+// readEncryptionHeader lit l'en-tête d'un flux chiffré
+func readEncryptionHeader(r io.Reader) (encryptionHeader, error) {
+	fixed := make([]byte, len(encryptionMagic)+1+4+encryptionNoncePrefixSize+1)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return encryptionHeader{}, fmt.Errorf("en-tête de chiffrement invalide: %v", err)
+	}
+	if !bytes.HasPrefix(fixed, encryptionMagic) {
+		return encryptionHeader{}, fmt.Errorf("en-tête de chiffrement invalide")
+	}
+	rest := fixed[len(encryptionMagic):]
+	if rest[0] != encryptionVersion {
+		return encryptionHeader{}, fmt.Errorf("version de chiffrement %d non supportée", rest[0])
+	}
+	h := encryptionHeader{
+		blockSize:   int(binary.BigEndian.Uint32(rest[1:5])),
+		noncePrefix: rest[5 : 5+encryptionNoncePrefixSize],
+	}
+	if h.blockSize <= 0 || h.blockSize > 16*1024*1024 {
+		return encryptionHeader{}, fmt.Errorf("taille de bloc chiffré invalide: %d", h.blockSize)
+	}
+	keyID := make([]byte, rest[len(rest)-1])
+	if _, err := io.ReadFull(r, keyID); err != nil {
+		return encryptionHeader{}, fmt.Errorf("en-tête de chiffrement invalide: %v", err)
+	}
+	h.keyID = string(keyID)
+	h.raw = append(fixed, keyID...)
+	return h, nil
+}
+
+func blockNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := append(append([]byte{}, prefix...), 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(nonce[encryptionNoncePrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// encryptingReader chiffre src à la lecture. Deux lecteurs créés avec le même préfixe de nonce
+// produisent les mêmes octets : storeBackup calcule l'empreinte du flux chiffré avant de l'envoyer.
+type encryptingReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	header  encryptionHeader
+	plain   []byte
+	out     []byte
+	counter uint32
+	done    bool
+}
+
+func newEncryptingReader(src io.Reader, enc *backupEncryption, noncePrefix []byte) (*encryptingReader, error) {
+	aead, err := newGCM(enc.key)
+	if err != nil {
+		return nil, err
+	}
+	h := encryptionHeader{keyID: enc.keyID, blockSize: encryptionBlockSize, noncePrefix: noncePrefix}
+	h.raw = h.marshal()
+	return &encryptingReader{
+		src:    bufio.NewReaderSize(src, encryptionBlockSize),
+		aead:   aead,
+		header: h,
+		plain:  make([]byte, encryptionBlockSize),
+		out:    append([]byte{}, h.raw...),
+	}, nil
+}
+
+func (e *encryptingReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		n, err := io.ReadFull(e.src, e.plain)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		last := err != nil
+		if !last {
+			// Un bloc plein est le dernier si rien ne le suit
+			if _, peekErr := e.src.Peek(1); peekErr == io.EOF {
+				last = true
+			} else if peekErr != nil {
+				return 0, peekErr
+			}
+		}
+		e.out = e.aead.Seal(e.out[:0], blockNonce(e.header.noncePrefix, e.counter, last), e.plain[:n], e.header.raw)
+		e.counter++
+		e.done = last
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// decryptingReader déchiffre un flux produit par encryptingReader
+type decryptingReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	header  encryptionHeader
+	sealed  []byte
+	out     []byte
+	counter uint32
+	done    bool
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		n, err := io.ReadFull(d.src, d.sealed)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		last := err != nil
+		if !last {
+			if _, peekErr := d.src.Peek(1); peekErr == io.EOF {
+				last = true
+			} else if peekErr != nil {
+				return 0, peekErr
+			}
+		}
+		plain, openErr := d.aead.Open(d.out[:0], blockNonce(d.header.noncePrefix, d.counter, last), d.sealed[:n], d.header.raw)
+		if openErr != nil {
+			return 0, fmt.Errorf("sauvegarde chiffrée corrompue ou tronquée (bloc %d)", d.counter)
+		}
+		d.out = plain
+		d.counter++
+		d.done = last
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// isEncryptedStream indique si r commence par l'en-tête d'une sauvegarde chiffrée, sans consommer
+// les octets lus
+func isEncryptedStream(r *bufio.Reader) (bool, error) {
+	head, err := r.Peek(len(encryptionMagic))
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("erreur lecture de la sauvegarde: %v", err)
+	}
+	return bytes.Equal(head, encryptionMagic), nil
+}
+
+// maybeDecrypt déchiffre r à la volée s'il s'agit d'une sauvegarde chiffrée (la clé est lue dans
+// le coffre d'après l'identifiant de l'en-tête) ; sinon r est retourné tel quel.
+func maybeDecrypt(r *bufio.Reader) (*bufio.Reader, error) {
+	encrypted, err := isEncryptedStream(r)
+	if err != nil || !encrypted {
+		return r, err
+	}
+	header, err := readEncryptionHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	key, err := loadBackupKey(header.keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	Log.Info(fmt.Sprintf("Sauvegarde chiffrée avec la clé '%s', déchiffrement à la volée", header.keyID))
+	return bufio.NewReaderSize(&decryptingReader{
+		src:    bufio.NewReaderSize(r, header.blockSize+aead.Overhead()),
+		aead:   aead,
+		header: header,
+		sealed: make([]byte, header.blockSize+aead.Overhead()),
+	}, 64*1024), nil
+}
+
+// newEncryptionNoncePrefix tire le préfixe de nonce d'une nouvelle sauvegarde
+func newEncryptionNoncePrefix() ([]byte, error) {
+	prefix := make([]byte, encryptionNoncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	return prefix, nil
+}
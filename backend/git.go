@@ -43,6 +43,9 @@ func ListSubmodule(path string) ([]string, error) {
 		if strings.Contains(line, "path = ") {
 			// Extrait le chemin du sous-module
 			submodulePath := strings.TrimSpace(strings.Split(line, "=")[1])
+			if CurrentConfig().IsSubmoduleExcluded(submodulePath) {
+				continue
+			}
 			// Construit le chemin complet
 			fullPath := filepath.Join(path, submodulePath)
 			results = append(results, fullPath)
@@ -97,13 +100,13 @@ func GetBranches(path string) []string {
 	// Effectuer un git fetch pour récupérer les branches distantes
 	fetchCmd := exec.Command("git", "-C", path, "fetch", "--all", "--prune")
 	if err := fetchCmd.Run(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur lors du fetch pour %s : %s", path, err.Error()))
+		Log.Error(fmt.Sprintf("Erreur lors du fetch pour %s : %s", path, err.Error()))
 		return []string{fmt.Sprintf("Erreur lors du fetch : %s", err.Error())}
 	}
 	cmd := exec.Command("git", "-C", path, "branch", "-a", "--list")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur lors de la récupération des branches pour %s : %s", path, err.Error()))
+		Log.Error(fmt.Sprintf("Erreur lors de la récupération des branches pour %s : %s", path, err.Error()))
 		return []string{fmt.Sprintf("Erreur : %s", err.Error())}
 	}
 	branches := strings.Split(string(output), "\n")
@@ -237,23 +240,23 @@ func GetDefaultBranch() (string, error) {
 	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
 	output, err := cmd.Output()
 	if err != nil {
-		LogToFrontend("error", "Impossible de déterminer la branche par défaut : "+err.Error())
+		Log.Error("Impossible de déterminer la branche par défaut : "+err.Error())
 		return "", fmt.Errorf("impossible de déterminer la branche par défaut : %v", err)
 	}
 
 	// Extraire la branche par défaut du chemin
 	defaultBranch := strings.TrimSpace(strings.TrimPrefix(string(output), "refs/remotes/origin/"))
-	LogToFrontend("info", "Branche par défaut détectée : "+defaultBranch)
+	Log.Info("Branche par défaut détectée : "+defaultBranch)
 	return defaultBranch, nil
 }
 
 // Fonction qui récupère d'un repos
 func GetLastTags(repoPath string) ([]string, []string, error) {
-	LogToFrontend("info", "Récupération des tags...")
+	Log.Info("Récupération des tags...")
 	cmd := exec.Command("git", "-C", repoPath, "for-each-ref", "--sort=-creatordate", "--format=%(refname:short)", "refs/tags/")
 	output, err := cmd.Output()
 	if err != nil {
-		LogToFrontend("error", "Erreur lors de la récupération des tags : "+err.Error())
+		Log.Error("Erreur lors de la récupération des tags : "+err.Error())
 		return nil, nil, fmt.Errorf("Erreur lors de la récupération des tags : %s\n%s", err.Error(), string(output))
 	}
 
@@ -269,11 +272,11 @@ func GetLastTags(repoPath string) ([]string, []string, error) {
 			rcTags = append(rcTags, tag)
 		}
 	}
-	LogToFrontend("success", "Tags récupérés avec succès.")
-	LogToFrontend("info", fmt.Sprintf("Nombre de tags v* : %d", len(vTags)))
-	LogToFrontend("info", fmt.Sprintf("Nombre de tags rc-* : %d", len(rcTags)))
-	LogToFrontend("debug", "Liste des tags v* : "+strings.Join(vTags, ", "))
-	LogToFrontend("debug", "Liste des tags rc-* : "+strings.Join(rcTags, ", "))
+	Log.Success("Tags récupérés avec succès.")
+	Log.Info(fmt.Sprintf("Nombre de tags v* : %d", len(vTags)))
+	Log.Info(fmt.Sprintf("Nombre de tags rc-* : %d", len(rcTags)))
+	Log.Debug("Liste des tags v* : "+strings.Join(vTags, ", "))
+	Log.Debug("Liste des tags rc-* : "+strings.Join(rcTags, ", "))
 	return vTags, rcTags, nil
 }
 
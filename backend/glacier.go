@@ -0,0 +1,247 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// Les sauvegardes en classe froide (GLACIER, DEEP_ARCHIVE, niveaux archive
+// d'INTELLIGENT_TIERING) ne sont pas lisibles directement : il faut demander leur restauration
+// (RestoreObject), qui rend une copie temporaire disponible après quelques minutes à quelques
+// heures selon le niveau choisi.
+
+// ErrObjectArchived est retourné quand une sauvegarde doit être restaurée avant d'être lue
+var ErrObjectArchived = errors.New("sauvegarde archivée en stockage froid")
+
+// Valeurs par défaut d'une demande de restauration
+const (
+	defaultThawDays         = 7
+	defaultThawTier         = types.TierStandard
+	defaultThawPollInterval = 5 * time.Minute
+)
+
+// ArchiveStatus décrit la disponibilité d'un objet en classe froide
+type ArchiveStatus struct {
+	Key          string `json:"key"`
+	StorageClass string `json:"storageClass"`
+	// Archived indique que l'objet est en classe froide
+	Archived          bool `json:"archived"`
+	RestoreInProgress bool `json:"restoreInProgress"`
+	// RestoredUntil est l'expiration de la copie restaurée, s'il y en a une
+	RestoredUntil *time.Time `json:"restoredUntil,omitempty"`
+	// Retrievable indique que l'objet peut être lu (classe chaude ou copie restaurée)
+	Retrievable bool `json:"retrievable"`
+}
+
+// ThawOptions règle une demande de restauration depuis le stockage froid
+type ThawOptions struct {
+	// Days est la durée de conservation de la copie restaurée (7 par défaut)
+	Days int `json:"days"`
+	// Tier est le niveau de restauration : Expedited, Standard (par défaut) ou Bulk
+	Tier string `json:"tier"`
+	// Wait attend que la copie soit disponible
+	Wait bool `json:"wait"`
+	// PollInterval est l'intervalle entre deux vérifications pendant l'attente (5 min par défaut)
+	PollInterval time.Duration `json:"-"`
+}
+
+func (o ThawOptions) withDefaults() (ThawOptions, error) {
+	if o.Days <= 0 {
+		o.Days = defaultThawDays
+	}
+	if o.Tier == "" {
+		o.Tier = string(defaultThawTier)
+	}
+	valid := false
+	for _, tier := range types.TierStandard.Values() {
+		if strings.EqualFold(o.Tier, string(tier)) {
+			o.Tier, valid = string(tier), true
+		}
+	}
+	if !valid {
+		return o, fmt.Errorf("niveau de restauration inconnu '%s' (Expedited, Standard ou Bulk)", o.Tier)
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultThawPollInterval
+	}
+	return o, nil
+}
+
+// isArchivedStorageClass indique qu'une classe de stockage impose une restauration avant lecture
+func isArchivedStorageClass(class string) bool {
+	switch types.StorageClass(class) {
+	case types.StorageClassGlacier, types.StorageClassDeepArchive:
+		return true
+	}
+	return false
+}
+
+// storageClassLabel retourne la classe de stockage affichée (STANDARD si S3 n'en indique pas)
+func storageClassLabel(class string) string {
+	if class == "" {
+		return string(types.StorageClassStandard)
+	}
+	return class
+}
+
+// parseRestoreHeader lit l'en-tête x-amz-restore : ongoing-request="false", expiry-date="..."
+func parseRestoreHeader(header string) (inProgress bool, expiry *time.Time) {
+	if header == "" {
+		return false, nil
+	}
+	inProgress = strings.Contains(header, `ongoing-request="true"`)
+	if _, rest, ok := strings.Cut(header, `expiry-date="`); ok {
+		if value, _, ok := strings.Cut(rest, `"`); ok {
+			if t, err := time.Parse(time.RFC1123, value); err == nil {
+				expiry = &t
+			}
+		}
+	}
+	return inProgress, expiry
+}
+
+// headArchiveStatus retourne la disponibilité d'un objet
+func headArchiveStatus(ctx context.Context, client *s3.Client, bucket, key string) (*ArchiveStatus, error) {
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("objet introuvable %s/%s: %v", bucket, key, err)
+	}
+	status := &ArchiveStatus{
+		Key:          key,
+		StorageClass: storageClassLabel(string(head.StorageClass)),
+		Archived: isArchivedStorageClass(string(head.StorageClass)) ||
+			head.ArchiveStatus == types.ArchiveStatusArchiveAccess ||
+			head.ArchiveStatus == types.ArchiveStatusDeepArchiveAccess,
+	}
+	status.RestoreInProgress, status.RestoredUntil = parseRestoreHeader(aws.ToString(head.Restore))
+	status.Retrievable = !status.Archived || (status.RestoredUntil != nil && !status.RestoreInProgress)
+	return status, nil
+}
+
+// ensureRetrievable retourne ErrObjectArchived si l'objet doit être restauré avant d'être lu.
+// Une erreur de HeadObject n'est pas bloquante : la lecture qui suit la signalera.
+func ensureRetrievable(ctx context.Context, client *s3.Client, bucket, key string) error {
+	status, err := headArchiveStatus(ctx, client, bucket, key)
+	if err != nil || status.Retrievable {
+		return nil
+	}
+	if status.RestoreInProgress {
+		return fmt.Errorf("%w: restauration de %s (%s) en cours, réessayez quand elle sera terminée", ErrObjectArchived, key, status.StorageClass)
+	}
+	return fmt.Errorf("%w: %s est en %s, demandez sa restauration (aidalinfo-cli backup thaw) avant de le lire", ErrObjectArchived, key, status.StorageClass)
+}
+
+// thawObject demande la restauration d'un objet archivé puis, si opts.Wait, attend qu'elle soit
+// terminée. Sans effet sur un objet déjà lisible.
+func thawObject(ctx context.Context, client *s3.Client, bucket, key string, opts ThawOptions) (*ArchiveStatus, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	status, err := headArchiveStatus(ctx, client, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !status.Archived:
+		Log.Info(fmt.Sprintf("%s est en %s, aucune restauration nécessaire", key, status.StorageClass))
+		return status, nil
+	case status.Retrievable:
+		Log.Info(fmt.Sprintf("%s est déjà restauré jusqu'au %s", key, status.RestoredUntil.Local().Format("2006-01-02 15:04")))
+		return status, nil
+	case status.RestoreInProgress:
+		Log.Info(fmt.Sprintf("Restauration de %s déjà en cours", key))
+	default:
+		_, err := client.RestoreObject(ctx, &s3.RestoreObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			RestoreRequest: &types.RestoreRequest{
+				Days:                 aws.Int32(int32(opts.Days)),
+				GlacierJobParameters: &types.GlacierJobParameters{Tier: types.Tier(opts.Tier)},
+			},
+		})
+		var apiErr smithy.APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress") {
+			return nil, fmt.Errorf("erreur demande de restauration de %s: %v", key, err)
+		}
+		status.RestoreInProgress = true
+		Log.Success(fmt.Sprintf("Restauration de %s demandée (%s, %d jour(s))", key, opts.Tier, opts.Days))
+	}
+	if !opts.Wait {
+		return status, nil
+	}
+	return waitObjectThawed(ctx, client, bucket, key, opts.PollInterval)
+}
+
+// waitObjectThawed interroge S3 toutes les interval jusqu'à ce que l'objet soit lisible
+func waitObjectThawed(ctx context.Context, client *s3.Client, bucket, key string, interval time.Duration) (*ArchiveStatus, error) {
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := headArchiveStatus(ctx, client, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		if status.Retrievable {
+			Log.Success(fmt.Sprintf("%s est disponible", key))
+			return status, nil
+		}
+		if !status.RestoreInProgress {
+			return status, fmt.Errorf("%w: aucune restauration en cours pour %s", ErrObjectArchived, key)
+		}
+		message := fmt.Sprintf("Restauration de %s en cours depuis %s", key, time.Since(start).Round(time.Second))
+		ReportProgress(ctx, -1, message)
+		Log.Info(message)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetArchiveStatusWithCreds retourne la disponibilité d'une sauvegarde
+func GetArchiveStatusWithCreds(ctx context.Context, creds S3Credentials, key string) (*ArchiveStatus, error) {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	return headArchiveStatus(ctx, client, bucket, key)
+}
+
+// ThawBackupWithCreds demande la restauration d'une sauvegarde archivée (et attend qu'elle
+// soit disponible si opts.Wait)
+func ThawBackupWithCreds(ctx context.Context, creds S3Credentials, key string, opts ThawOptions) (*ArchiveStatus, error) {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	return thawObject(ctx, client, bucket, key, opts)
+}
+
+// ThawBackup demande la restauration d'une sauvegarde archivée du dépôt de backups.
+// ref est une clé du dépôt ou une URL s3://bucket/clé.
+func ThawBackup(ctx context.Context, ref string, opts ThawOptions) (*ArchiveStatus, error) {
+	bucket, key := parseBackupRef(ref)
+	if key == "" {
+		return nil, fmt.Errorf("la clé de la sauvegarde est requise")
+	}
+	repo, err := openBackupRepository(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	storage, ok := repo.storage.(*s3Storage)
+	if !ok {
+		return nil, fmt.Errorf("le dépôt %s n'est pas sur S3, aucune restauration depuis le stockage froid n'est nécessaire", repo.storage)
+	}
+	return thawObject(ctx, storage.client, storage.bucket, key, opts)
+}
@@ -0,0 +1,270 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JobState est l'état d'une opération longue
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// JobInfo est l'instantané d'un job envoyé au frontend
+type JobInfo struct {
+	ID    string   `json:"id"`
+	Kind  string   `json:"kind"`
+	Title string   `json:"title"`
+	State JobState `json:"state"`
+	// Progress est un pourcentage entre 0 et 100, -1 si la progression est inconnue
+	Progress   float64   `json:"progress"`
+	Message    string    `json:"message,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+// Done indique si le job est terminé (succès, échec ou annulation)
+func (j JobInfo) Done() bool {
+	return j.State != JobRunning
+}
+
+// JobFunc est le travail exécuté par un job. ctx est annulé par CancelJob.
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	info   JobInfo
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// JobManager suit les opérations longues (restaurations, transferts, installations)
+type JobManager struct {
+	mu       sync.Mutex
+	jobs     map[string]*job
+	nextID   int
+	listener func(JobInfo)
+	// maxFinished limite le nombre de jobs terminés conservés dans l'historique
+	maxFinished int
+}
+
+// NewJobManager crée un gestionnaire de jobs vide
+func NewJobManager() *JobManager {
+	return &JobManager{jobs: map[string]*job{}, maxFinished: 50}
+}
+
+// Jobs est le gestionnaire de jobs partagé par le GUI et la CLI
+var Jobs = NewJobManager()
+
+// ErrJobNotFound est retourné quand l'identifiant de job est inconnu
+var ErrJobNotFound = errors.New("job introuvable")
+
+type jobContextKey struct{}
+
+// SetListener enregistre la fonction appelée à chaque changement d'état ou de progression
+func (m *JobManager) SetListener(listener func(JobInfo)) {
+	m.mu.Lock()
+	m.listener = listener
+	m.mu.Unlock()
+}
+
+// Start lance fn dans une goroutine et retourne l'identifiant du job
+func (m *JobManager) Start(parent context.Context, kind, title string, fn JobFunc) string {
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+
+	m.mu.Lock()
+	m.nextID++
+	id := fmt.Sprintf("%s-%d", kind, m.nextID)
+	j := &job{
+		info: JobInfo{
+			ID:        id,
+			Kind:      kind,
+			Title:     title,
+			State:     JobRunning,
+			Progress:  -1,
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.jobs[id] = j
+	m.mu.Unlock()
+
+	Log.Info(fmt.Sprintf("Job démarré: %s", title), F("job", id))
+	m.notify(j)
+
+	go func() {
+		err := fn(context.WithValue(ctx, jobContextKey{}, id))
+		m.finish(j, ctx, err)
+	}()
+	return id
+}
+
+// Run lance un job et attend sa fin (utilisé par les bindings synchrones du GUI)
+func (m *JobManager) Run(parent context.Context, kind, title string, fn JobFunc) error {
+	id := m.Start(parent, kind, title, fn)
+	return m.Wait(id)
+}
+
+// Wait bloque jusqu'à la fin du job et retourne son erreur
+func (m *JobManager) Wait(id string) error {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	<-j.done
+	return j.err
+}
+
+// Cancel demande l'annulation d'un job en cours
+func (m *JobManager) Cancel(id string) error {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	Log.Warn(fmt.Sprintf("Annulation demandée: %s", j.info.Title), F("job", id))
+	j.cancel()
+	return nil
+}
+
+// List retourne les jobs, les plus récents en premier
+func (m *JobManager) List() []JobInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]JobInfo, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		infos = append(infos, j.info)
+	}
+	sort.Slice(infos, func(i, k int) bool { return infos[i].StartedAt.After(infos[k].StartedAt) })
+	return infos
+}
+
+// Get retourne l'état d'un job
+func (m *JobManager) Get(id string) (JobInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return JobInfo{}, false
+	}
+	return j.info, true
+}
+
+// SetProgress met à jour la progression du job associé à ctx (sans effet hors d'un job)
+func (m *JobManager) SetProgress(ctx context.Context, percent float64, message string) {
+	id, ok := ctx.Value(jobContextKey{}).(string)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok || j.info.Done() {
+		m.mu.Unlock()
+		return
+	}
+	j.info.Progress = percent
+	if message != "" {
+		j.info.Message = message
+	}
+	m.mu.Unlock()
+	m.notify(j)
+}
+
+// ReportProgress met à jour la progression du job courant, s'il y en a un
+func ReportProgress(ctx context.Context, percent float64, message string) {
+	Jobs.SetProgress(ctx, percent, message)
+}
+
+// JobID retourne l'identifiant du job associé à ctx
+func JobID(ctx context.Context) string {
+	id, _ := ctx.Value(jobContextKey{}).(string)
+	return id
+}
+
+func (m *JobManager) finish(j *job, ctx context.Context, err error) {
+	m.mu.Lock()
+	j.info.FinishedAt = time.Now()
+	switch {
+	case err != nil && ctx.Err() == context.Canceled:
+		j.info.State = JobCancelled
+		j.info.Error = "opération annulée"
+		err = fmt.Errorf("opération annulée: %w", context.Canceled)
+	case err != nil:
+		j.info.State = JobFailed
+		j.info.Error = RedactSecrets(err.Error())
+	default:
+		j.info.State = JobSucceeded
+		j.info.Progress = 100
+	}
+	j.err = err
+	j.cancel()
+	close(j.done)
+	m.pruneLocked()
+	m.mu.Unlock()
+
+	switch j.info.State {
+	case JobSucceeded:
+		Log.Success(fmt.Sprintf("Job terminé: %s", j.info.Title), F("job", j.info.ID))
+	case JobCancelled:
+		Log.Warn(fmt.Sprintf("Job annulé: %s", j.info.Title), F("job", j.info.ID))
+	default:
+		Log.Error(fmt.Sprintf("Job en échec: %s", j.info.Title), F("job", j.info.ID), F("error", err))
+	}
+	m.notify(j)
+}
+
+// pruneLocked supprime les jobs terminés les plus anciens au-delà de maxFinished
+func (m *JobManager) pruneLocked() {
+	var finished []*job
+	for _, j := range m.jobs {
+		if j.info.Done() {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) <= m.maxFinished {
+		return
+	}
+	sort.Slice(finished, func(i, k int) bool { return finished[i].info.FinishedAt.Before(finished[k].info.FinishedAt) })
+	for _, j := range finished[:len(finished)-m.maxFinished] {
+		delete(m.jobs, j.info.ID)
+	}
+}
+
+func (m *JobManager) notify(j *job) {
+	m.mu.Lock()
+	listener := m.listener
+	info := j.info
+	m.mu.Unlock()
+	if listener != nil {
+		listener(info)
+	}
+}
+
+// sleepContext attend d, ou moins si ctx est annulé
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
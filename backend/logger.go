@@ -0,0 +1,410 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// LogLevel est le niveau de sévérité d'un message
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelSuccess
+	LevelWarn
+	LevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelSuccess:
+		return "success"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLogLevel convertit un nom de niveau ("warning" est accepté comme alias de "warn")
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "success":
+		return LevelSuccess, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("niveau de log inconnu: %s", name)
+}
+
+// Field est une paire clé/valeur attachée à un message
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F construit un Field
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// LogEntry est un message prêt à être écrit par les sinks (secrets déjà masqués)
+type LogEntry struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+	Fields  []Field
+}
+
+// LogSink reçoit les messages d'un niveau supérieur ou égal à son MinLevel
+type LogSink interface {
+	MinLevel() LogLevel
+	Write(entry LogEntry)
+}
+
+// Logger est l'interface de journalisation utilisée par tout le backend
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Success(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+	// With retourne un logger qui ajoute fields à chaque message
+	With(fields ...Field) Logger
+}
+
+// logHub distribue les messages aux sinks configurés
+type logHub struct {
+	mu    sync.RWMutex
+	sinks []LogSink
+}
+
+var defaultLogHub = &logHub{
+	sinks: []LogSink{NewTerminalSink(os.Stderr, LevelInfo, false)},
+}
+
+// Log est le logger global du backend
+var Log Logger = &logger{hub: defaultLogHub}
+
+// SetLogSinks remplace l'ensemble des sinks du logger global
+func SetLogSinks(sinks ...LogSink) {
+	defaultLogHub.mu.Lock()
+	defaultLogHub.sinks = sinks
+	defaultLogHub.mu.Unlock()
+}
+
+// AddLogSink ajoute un sink au logger global
+func AddLogSink(sink LogSink) {
+	defaultLogHub.mu.Lock()
+	defaultLogHub.sinks = append(defaultLogHub.sinks, sink)
+	defaultLogHub.mu.Unlock()
+}
+
+type logger struct {
+	hub    *logHub
+	fields []Field
+}
+
+func (l *logger) Debug(msg string, fields ...Field)   { l.log(LevelDebug, msg, fields) }
+func (l *logger) Info(msg string, fields ...Field)    { l.log(LevelInfo, msg, fields) }
+func (l *logger) Success(msg string, fields ...Field) { l.log(LevelSuccess, msg, fields) }
+func (l *logger) Warn(msg string, fields ...Field)    { l.log(LevelWarn, msg, fields) }
+func (l *logger) Error(msg string, fields ...Field)   { l.log(LevelError, msg, fields) }
+
+func (l *logger) With(fields ...Field) Logger {
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &logger{hub: l.hub, fields: merged}
+}
+
+func (l *logger) log(level LogLevel, msg string, fields []Field) {
+	entry := LogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: RedactSecrets(msg),
+	}
+	for _, field := range append(append([]Field{}, l.fields...), fields...) {
+		switch v := field.Value.(type) {
+		case string:
+			field.Value = RedactSecrets(v)
+		case error:
+			field.Value = RedactSecrets(v.Error())
+		}
+		entry.Fields = append(entry.Fields, field)
+	}
+
+	l.hub.mu.RLock()
+	defer l.hub.mu.RUnlock()
+	for _, sink := range l.hub.sinks {
+		if level >= sink.MinLevel() {
+			sink.Write(entry)
+		}
+	}
+}
+
+// formatText produit "[LEVEL] message key=value ..." (format historique des logs du GUI)
+func formatText(entry LogEntry) string {
+	var b strings.Builder
+	b.WriteString("[" + strings.ToUpper(entry.Level.String()) + "] ")
+	b.WriteString(entry.Message)
+	for _, field := range entry.Fields {
+		fmt.Fprintf(&b, " %s=%v", field.Key, field.Value)
+	}
+	return b.String()
+}
+
+// formatJSON produit une ligne JSON {"time","level","msg", champs...}
+func formatJSON(entry LogEntry) []byte {
+	obj := map[string]interface{}{
+		"time":  entry.Time.Format(time.RFC3339Nano),
+		"level": entry.Level.String(),
+		"msg":   entry.Message,
+	}
+	for _, field := range entry.Fields {
+		obj[field.Key] = field.Value
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"level": entry.Level.String(), "msg": entry.Message})
+	}
+	return append(data, '\n')
+}
+
+// TerminalSink écrit les messages sur un terminal, en couleur si possible, ou en JSON
+type TerminalSink struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level LogLevel
+	json  bool
+	color bool
+}
+
+// NewTerminalSink crée un sink terminal. Les couleurs sont désactivées si out n'est pas
+// un terminal ou si NO_COLOR est défini.
+func NewTerminalSink(out io.Writer, level LogLevel, jsonFormat bool) *TerminalSink {
+	return &TerminalSink{
+		out:   out,
+		level: level,
+		json:  jsonFormat,
+		color: !jsonFormat && IsTerminal(out) && os.Getenv("NO_COLOR") == "",
+	}
+}
+
+func (s *TerminalSink) MinLevel() LogLevel { return s.level }
+
+func (s *TerminalSink) Write(entry LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.json {
+		s.out.Write(formatJSON(entry))
+		return
+	}
+	line := formatText(entry)
+	if s.color {
+		line = levelColor(entry.Level) + line + "\033[0m"
+	}
+	fmt.Fprintln(s.out, line)
+}
+
+func levelColor(level LogLevel) string {
+	switch level {
+	case LevelDebug:
+		return "\033[90m"
+	case LevelSuccess:
+		return "\033[32m"
+	case LevelWarn:
+		return "\033[33m"
+	case LevelError:
+		return "\033[31m"
+	}
+	return ""
+}
+
+// IsTerminal indique si w est un terminal interactif
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// WailsSink envoie les messages au frontend via l'événement "backend-log"
+type WailsSink struct {
+	ctx   context.Context
+	level LogLevel
+}
+
+// NewWailsSink crée un sink lié au contexte de l'application Wails
+func NewWailsSink(ctx context.Context, level LogLevel) *WailsSink {
+	return &WailsSink{ctx: ctx, level: level}
+}
+
+func (s *WailsSink) MinLevel() LogLevel { return s.level }
+
+func (s *WailsSink) Write(entry LogEntry) {
+	runtime.EventsEmit(s.ctx, "backend-log", formatText(entry))
+}
+
+// RotatingFileSink écrit les messages en JSON dans un fichier qui tourne à MaxSize octets
+// (aidalinfo.log -> aidalinfo.log.1 -> ... -> aidalinfo.log.<MaxBackups>)
+type RotatingFileSink struct {
+	mu         sync.Mutex
+	path       string
+	level      LogLevel
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+const (
+	defaultLogMaxSize    = 5 * 1024 * 1024
+	defaultLogMaxBackups = 3
+)
+
+// DefaultLogFilePath retourne le chemin du fichier de log de l'application
+func DefaultLogFilePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("erreur récupération dossier de cache: %v", err)
+	}
+	return filepath.Join(dir, "aidalinfo-cli", "logs", "aidalinfo.log"), nil
+}
+
+// NewRotatingFileSink ouvre (ou crée) le fichier de log
+func NewRotatingFileSink(path string, level LogLevel) (*RotatingFileSink, error) {
+	s := &RotatingFileSink{
+		path:       path,
+		level:      level,
+		maxSize:    defaultLogMaxSize,
+		maxBackups: defaultLogMaxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("erreur création dossier de logs: %v", err)
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RotatingFileSink) MinLevel() LogLevel { return s.level }
+
+func (s *RotatingFileSink) Write(entry LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return
+	}
+	line := formatJSON(entry)
+	if s.size+int64(len(line)) > s.maxSize && s.size > 0 {
+		if err := s.rotate(); err != nil {
+			return
+		}
+	}
+	n, _ := s.file.Write(line)
+	s.size += int64(n)
+}
+
+// Close ferme le fichier de log
+func (s *RotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *RotatingFileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("erreur ouverture du fichier de log: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("erreur ouverture du fichier de log: %v", err)
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+func (s *RotatingFileSink) rotate() error {
+	s.file.Close()
+	s.file = nil
+
+	// Décale les anciens fichiers, le plus ancien est écrasé
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+	if s.maxBackups > 0 {
+		os.Rename(s.path, s.path+".1")
+	} else {
+		os.Remove(s.path)
+	}
+	return s.open()
+}
+
+// LogOptions décrit les sinks standards de l'application
+type LogOptions struct {
+	// Level est le niveau minimum affiché sur le terminal
+	Level LogLevel
+	// JSON affiche les messages du terminal en JSON (une ligne par message)
+	JSON bool
+	// File active le fichier de log tournant (DefaultLogFilePath), toujours au niveau debug
+	File bool
+	// WailsCtx, s'il est défini, transmet les messages au frontend
+	WailsCtx context.Context
+}
+
+// SetupLogging configure les sinks du logger global. Une erreur d'ouverture du fichier
+// de log est retournée mais n'empêche pas les autres sinks de fonctionner.
+func SetupLogging(opts LogOptions) error {
+	sinks := []LogSink{NewTerminalSink(os.Stderr, opts.Level, opts.JSON)}
+	if opts.WailsCtx != nil {
+		sinks = append(sinks, NewWailsSink(opts.WailsCtx, LevelDebug))
+	}
+
+	var fileErr error
+	if opts.File {
+		path, err := DefaultLogFilePath()
+		if err == nil {
+			var sink *RotatingFileSink
+			if sink, err = NewRotatingFileSink(path, LevelDebug); err == nil {
+				sinks = append(sinks, sink)
+			}
+		}
+		fileErr = err
+	}
+
+	SetLogSinks(sinks...)
+	return fileErr
+}
@@ -0,0 +1,196 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Le listing, les statistiques et les tests de connexion MongoDB passent par le driver Go
+// officiel : mongosh n'est plus nécessaire, seuls mongodump et mongorestore le restent.
+// L'hôte d'un serveur MongoDB peut être une URI complète (mongodb:// ou mongodb+srv://) pour
+// joindre un replica set, un cluster SRV, activer TLS ou choisir un authSource autre que admin.
+
+// mongoServerSelectionTimeout borne l'attente d'un serveur joignable : un hôte injoignable
+// échoue rapidement au lieu des 30 s par défaut du driver (serverSelectionTimeoutMS dans l'URI
+// reste prioritaire)
+const mongoServerSelectionTimeout = 10 * time.Second
+
+// MongoDatabaseStats décrit une base d'un serveur MongoDB
+type MongoDatabaseStats struct {
+	Name        string `json:"name"`
+	SizeOnDisk  int64  `json:"sizeOnDisk"`
+	Collections int64  `json:"collections"`
+	Documents   int64  `json:"documents"`
+	Empty       bool   `json:"empty"`
+}
+
+// isMongoURI indique si host est une URI de connexion plutôt qu'un nom d'hôte
+func isMongoURI(host string) bool {
+	host = strings.TrimSpace(host)
+	return strings.HasPrefix(host, "mongodb://") || strings.HasPrefix(host, "mongodb+srv://")
+}
+
+// mongoURI retourne l'URI de connexion d'un serveur MongoDB. Si host est déjà une URI, port est
+// ignoré et user/password ne sont ajoutés que si elle ne contient pas d'identifiants ; sinon
+// l'URI est construite avec authSource=admin comme pour mongodump.
+func mongoURI(host, port, user, password string) (string, error) {
+	if password != "" {
+		RegisterSecret(password)
+	}
+	host = strings.TrimSpace(host)
+	if !isMongoURI(host) {
+		if user != "" && password != "" {
+			return fmt.Sprintf("mongodb://%s@%s:%s/?authSource=admin",
+				url.UserPassword(user, password).String(), host, port), nil
+		}
+		return fmt.Sprintf("mongodb://%s:%s", host, port), nil
+	}
+
+	// L'erreur de url.Parse reprend l'URI, qui peut contenir un mot de passe
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("URI MongoDB invalide")
+	}
+	if u.User == nil && user != "" {
+		if password != "" {
+			u.User = url.UserPassword(user, password)
+		} else {
+			u.User = url.User(user)
+		}
+	}
+	if u.User != nil {
+		if secret, ok := u.User.Password(); ok && secret != "" {
+			RegisterSecret(secret)
+		}
+	}
+	return u.String(), nil
+}
+
+// withMongoClient ouvre une connexion au serveur, exécute fn puis ferme la connexion
+func withMongoClient(ctx context.Context, host, port, user, password string, fn func(*mongo.Client) error) error {
+	uri, err := mongoURI(host, port, user, password)
+	if err != nil {
+		return err
+	}
+	opts := options.Client().
+		SetAppName("aidalinfo-cli").
+		SetServerSelectionTimeout(mongoServerSelectionTimeout).
+		ApplyURI(uri)
+	client, err := mongo.Connect(opts)
+	if err != nil {
+		return fmt.Errorf("erreur connexion MongoDB: %v", err)
+	}
+	defer func() {
+		disconnectCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		client.Disconnect(disconnectCtx)
+	}()
+	return fn(client)
+}
+
+// withMongoProfile ouvre une connexion au serveur MongoDB d'un profil
+func withMongoProfile(ctx context.Context, profile *ServerProfile, fn func(*mongo.Client) error) error {
+	return withMongoClient(ctx, profile.Host, profile.Port, profile.User, profile.Password, fn)
+}
+
+// TestMongoConnection teste la connexion à un serveur MongoDB (authentification comprise)
+func TestMongoConnection(ctx context.Context, mongoHost, mongoPort, mongoUser, mongoPassword string) error {
+	return withMongoClient(ctx, mongoHost, mongoPort, mongoUser, mongoPassword, func(client *mongo.Client) error {
+		if err := client.Ping(ctx, nil); err != nil {
+			return fmt.Errorf("échec de la connexion MongoDB: %v", err)
+		}
+		return nil
+	})
+}
+
+// ListMongoDatabases liste les bases de données disponibles sur un serveur MongoDB. Un
+// utilisateur sans le droit listDatabases ne voit que les bases sur lesquelles il a des droits.
+func ListMongoDatabases(ctx context.Context, mongoHost, mongoPort, mongoUser, mongoPassword string) ([]string, error) {
+	var databases []string
+	err := withMongoClient(ctx, mongoHost, mongoPort, mongoUser, mongoPassword, func(client *mongo.Client) error {
+		names, err := client.ListDatabaseNames(ctx, bson.D{}, options.ListDatabases().SetAuthorizedDatabases(true))
+		if err != nil {
+			return fmt.Errorf("erreur listing databases: %v", err)
+		}
+		databases = names
+		return nil
+	})
+	if err != nil {
+		Log.Error(err.Error())
+		return nil, err
+	}
+	sort.Strings(databases)
+	return databases, nil
+}
+
+// ListMongoDatabaseStats retourne la taille sur disque, le nombre de collections et le nombre de
+// documents de chaque base d'un serveur MongoDB
+func ListMongoDatabaseStats(ctx context.Context, mongoHost, mongoPort, mongoUser, mongoPassword string) ([]MongoDatabaseStats, error) {
+	var stats []MongoDatabaseStats
+	err := withMongoClient(ctx, mongoHost, mongoPort, mongoUser, mongoPassword, func(client *mongo.Client) error {
+		result, err := client.ListDatabases(ctx, bson.D{}, options.ListDatabases().SetAuthorizedDatabases(true))
+		if err != nil {
+			return fmt.Errorf("erreur listing databases: %v", err)
+		}
+		for _, spec := range result.Databases {
+			var dbStats struct {
+				Collections int64 `bson:"collections"`
+				Objects     int64 `bson:"objects"`
+			}
+			if err := client.Database(spec.Name).RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&dbStats); err != nil {
+				return fmt.Errorf("erreur statistiques de la base %s: %v", spec.Name, err)
+			}
+			stats = append(stats, MongoDatabaseStats{
+				Name:        spec.Name,
+				SizeOnDisk:  spec.SizeOnDisk,
+				Collections: dbStats.Collections,
+				Documents:   dbStats.Objects,
+				Empty:       spec.Empty,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats, nil
+}
+
+// mongoServerVersion retourne la version du serveur MongoDB
+func mongoServerVersion(ctx context.Context, client *mongo.Client) (string, error) {
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", fmt.Errorf("erreur lecture de la version MongoDB: %v", err)
+	}
+	return info.Version, nil
+}
+
+// mongoCollectionCounts retourne le nombre exact de documents de chaque collection de database
+func mongoCollectionCounts(ctx context.Context, client *mongo.Client, database string) ([]TableCount, error) {
+	db := client.Database(database)
+	names, err := db.ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+	if err != nil {
+		return nil, fmt.Errorf("erreur listing des collections de %s: %v", database, err)
+	}
+	sort.Strings(names)
+	counts := make([]TableCount, 0, len(names))
+	for _, name := range names {
+		rows, err := db.Collection(name).CountDocuments(ctx, bson.D{})
+		if err != nil {
+			return nil, fmt.Errorf("erreur comptage de la collection %s: %v", name, err)
+		}
+		counts = append(counts, TableCount{Name: name, Rows: rows})
+	}
+	return counts, nil
+}
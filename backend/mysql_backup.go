@@ -3,37 +3,27 @@ package backend
 import (
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"strings"
-	"time"
-
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
 // ListMySQLDatabases liste les bases de données disponibles sur un serveur MySQL
 func ListMySQLDatabases(ctx context.Context, mysqlHost, mysqlPort, mysqlUser, mysqlPassword string) ([]string, error) {
 	// Construire la commande mysql pour lister les bases
-	args := []string{
-		"-h", mysqlHost,
-		"-P", mysqlPort,
-		"-u", mysqlUser,
-	}
-
-	if mysqlPassword != "" {
-		args = append(args, fmt.Sprintf("-p%s", mysqlPassword))
+	args, cleanup, err := mysqlClientArgs(mysqlHost, mysqlPort, mysqlUser, mysqlPassword)
+	if err != nil {
+		return nil, err
 	}
+	defer cleanup()
 
 	// Exécuter SHOW DATABASES
 	args = append(args, "-e", "SHOW DATABASES;", "--skip-column-names", "--batch")
 
-	cmd := exec.Command("mysql", args...)
+	cmd := exec.CommandContext(ctx, "mysql", args...)
 	output, err := cmd.Output()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur listing databases MySQL: %v", err))
+		Log.Error(fmt.Sprintf("Erreur listing databases MySQL: %v", err))
 		return nil, fmt.Errorf("erreur listing databases MySQL: %v", err)
 	}
 
@@ -53,7 +43,7 @@ func ListMySQLDatabases(ctx context.Context, mysqlHost, mysqlPort, mysqlUser, my
 
 // DumpMySQLDatabase crée un dump d'une base MySQL
 func DumpMySQLDatabase(ctx context.Context, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database string) (string, error) {
-	tmpDir, err := getUserTmpDir()
+	tmpDir, err := getWorkDir()
 	if err != nil {
 		return "", err
 	}
@@ -66,77 +56,51 @@ func DumpMySQLDatabase(ctx context.Context, mysqlHost, mysqlPort, mysqlUser, mys
 	tmpFilePath := tmpFile.Name()
 	tmpFile.Close()
 
-	// Prépare la commande mysqldump avec compression gzip
-	args := []string{
-		"-h", mysqlHost,
-		"-P", mysqlPort,
-		"-u", mysqlUser,
+	// Prépare la commande mysqldump
+	args, cleanup, err := mysqlClientArgs(mysqlHost, mysqlPort, mysqlUser, mysqlPassword)
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return "", err
+	}
+	defer cleanup()
+	args = append(args,
 		"--single-transaction",
 		"--routines",
 		"--triggers",
 		"--events",
 		database,
-	}
-
-	if mysqlPassword != "" {
-		// Réorganiser pour mettre le password avant les autres options
-		args = []string{
-			"-h", mysqlHost,
-			"-P", mysqlPort,
-			"-u", mysqlUser,
-			fmt.Sprintf("-p%s", mysqlPassword),
-			"--single-transaction",
-			"--routines",
-			"--triggers",
-			"--events",
-			database,
-		}
-	}
-
-	LogToFrontend("info", fmt.Sprintf("Création du dump de la base MySQL %s...", database))
-
-	// Utiliser mysqldump avec pipe vers gzip
-	cmdDump := exec.Command("mysqldump", args...)
-	cmdGzip := exec.Command("gzip", "-c")
+	)
 
-	// Connecter la sortie de mysqldump à l'entrée de gzip
-	pipe, err := cmdDump.StdoutPipe()
-	if err != nil {
-		return "", fmt.Errorf("erreur création pipe: %v", err)
-	}
-	cmdGzip.Stdin = pipe
+	Log.Info(fmt.Sprintf("Création du dump de la base MySQL %s...", database))
 
-	// Rediriger la sortie de gzip vers le fichier
+	// La sortie de mysqldump est compressée en gzip directement dans le fichier
 	outFile, err := os.Create(tmpFilePath)
 	if err != nil {
 		return "", fmt.Errorf("erreur création fichier sortie: %v", err)
 	}
 	defer outFile.Close()
-	cmdGzip.Stdout = outFile
-
-	// Démarrer les commandes
-	if err := cmdGzip.Start(); err != nil {
-		return "", fmt.Errorf("erreur démarrage gzip: %v", err)
-	}
-	if err := cmdDump.Start(); err != nil {
-		return "", fmt.Errorf("erreur démarrage mysqldump: %v", err)
+	progress := NewProgress(ctx, PhaseDump, database, 0)
+	gz, err := newCompressWriter(progress.Writer(outFile), CompressionGzip)
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return "", err
 	}
 
-	// Attendre la fin des commandes
-	if err := cmdDump.Wait(); err != nil {
+	cmdDump := exec.CommandContext(ctx, "mysqldump", args...)
+	cmdDump.Stdout = gz
+	cmdDump.Stderr = os.Stderr
+	if err := cmdDump.Run(); err != nil {
 		os.Remove(tmpFilePath)
-		LogToFrontend("error", fmt.Sprintf("Erreur mysqldump: %v", err))
+		Log.Error(fmt.Sprintf("Erreur mysqldump: %v", err))
 		return "", fmt.Errorf("erreur mysqldump: %v", err)
 	}
-	pipe.Close()
-
-	if err := cmdGzip.Wait(); err != nil {
+	if err := gz.Close(); err != nil {
 		os.Remove(tmpFilePath)
-		LogToFrontend("error", fmt.Sprintf("Erreur gzip: %v", err))
-		return "", fmt.Errorf("erreur gzip: %v", err)
+		return "", fmt.Errorf("erreur compression gzip: %v", err)
 	}
+	progress.Finish()
 
-	LogToFrontend("success", fmt.Sprintf("Dump MySQL de %s créé avec succès", database))
+	Log.Success(fmt.Sprintf("Dump MySQL de %s créé avec succès", database))
 	return tmpFilePath, nil
 }
 
@@ -144,7 +108,7 @@ func DumpMySQLDatabase(ctx context.Context, mysqlHost, mysqlPort, mysqlUser, mys
 func TransferMySQLDatabase(ctx context.Context, sourceHost, sourcePort, sourceUser, sourcePassword,
 	destHost, destPort, destUser, destPassword, database string, dropExisting bool) error {
 
-	LogToFrontend("info", fmt.Sprintf("Début du transfert de la base MySQL %s", database))
+	Log.Info(fmt.Sprintf("Début du transfert de la base MySQL %s", database))
 
 	// Étape 1: Créer le dump de la source
 	dumpFile, err := DumpMySQLDatabase(ctx, sourceHost, sourcePort, sourceUser, sourcePassword, database)
@@ -153,247 +117,114 @@ func TransferMySQLDatabase(ctx context.Context, sourceHost, sourcePort, sourceUs
 	}
 	defer os.Remove(dumpFile)
 
+	destArgs, cleanup, err := mysqlClientArgs(destHost, destPort, destUser, destPassword)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
 	// Étape 2: Si dropExisting, supprimer la base de destination si elle existe
 	if dropExisting {
-		LogToFrontend("info", fmt.Sprintf("Suppression de la base %s sur le serveur de destination...", database))
-		dropArgs := []string{
-			"-h", destHost,
-			"-P", destPort,
-			"-u", destUser,
-		}
-		if destPassword != "" {
-			dropArgs = append(dropArgs, fmt.Sprintf("-p%s", destPassword))
-		}
+		Log.Info(fmt.Sprintf("Suppression de la base %s sur le serveur de destination...", database))
+		dropArgs := append([]string{}, destArgs...)
 		dropArgs = append(dropArgs, "-e", fmt.Sprintf("DROP DATABASE IF EXISTS `%s`; CREATE DATABASE `%s`;", database, database))
 
-		cmdDrop := exec.Command("mysql", dropArgs...)
+		cmdDrop := exec.CommandContext(ctx, "mysql", dropArgs...)
 		if err := cmdDrop.Run(); err != nil {
-			LogToFrontend("warn", fmt.Sprintf("Impossible de supprimer la base: %v", err))
+			Log.Warn(fmt.Sprintf("Impossible de supprimer la base: %v", err))
 		}
 	}
 
 	
 	// Toujours créer la base si elle n'existe pas
-	LogToFrontend("info", fmt.Sprintf("Création de la base %s si elle n'existe pas...", database))
-	createDbArgs := []string{
-		"-h", destHost,
-		"-P", destPort,
-		"-u", destUser,
-	}
-	if destPassword != "" {
-		createDbArgs = append(createDbArgs, fmt.Sprintf("-p%s", destPassword))
-	}
-	createArgs := make([]string, len(createDbArgs))
-	copy(createArgs, createDbArgs)
+	Log.Info(fmt.Sprintf("Création de la base %s si elle n'existe pas...", database))
+	createArgs := append([]string{}, destArgs...)
 	createArgs = append(createArgs, "-e", fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`;", database))
-	cmdCreate := exec.Command("mysql", createArgs...)
+	cmdCreate := exec.CommandContext(ctx, "mysql", createArgs...)
 	if output, err := cmdCreate.CombinedOutput(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Impossible de créer la base %s: %v - Output: %s", database, err, string(output)))
+		Log.Error(fmt.Sprintf("Impossible de créer la base %s: %v - Output: %s", database, err, string(output)))
 		return fmt.Errorf("impossible de créer la base de données %s: %v", database, err)
 	}
 	
 	// Étape 3: Restaurer sur la destination
-	LogToFrontend("info", fmt.Sprintf("Restauration de %s sur le serveur MySQL de destination...", database))
-
-	// Décompresser et restaurer
-	cmdGunzip := exec.Command("gunzip", "-c", dumpFile)
-
-	cmdMysql := exec.Command("mysql",
-		"-h", destHost,
-		"-P", destPort,
-		"-u", destUser,
-		database,
-	)
+	Log.Info(fmt.Sprintf("Restauration de %s sur le serveur MySQL de destination...", database))
 
-	// Ajouter le password si fourni
-	if destPassword != "" {
-		cmdMysql = exec.Command("mysql",
-			"-h", destHost,
-			"-P", destPort,
-			"-u", destUser,
-			fmt.Sprintf("-p%s", destPassword),
-			database,
-		)
-	}
-
-	// Connecter gunzip à mysql via pipe
-	pipe, err := cmdGunzip.StdoutPipe()
+	// Le dump est décompressé à la volée vers l'entrée standard de mysql
+	dump, err := openFileWithProgress(ctx, dumpFile, PhaseRestore, database)
 	if err != nil {
-		return fmt.Errorf("erreur création pipe: %v", err)
-	}
-	cmdMysql.Stdin = pipe
-	cmdMysql.Stdout = os.Stdout
-	cmdMysql.Stderr = os.Stderr
-
-	// Démarrer les commandes
-	if err := cmdMysql.Start(); err != nil {
-		return fmt.Errorf("erreur démarrage mysql: %v", err)
-	}
-	if err := cmdGunzip.Start(); err != nil {
-		return fmt.Errorf("erreur démarrage gunzip: %v", err)
-	}
-
-	// Attendre la fin
-	if err := cmdGunzip.Wait(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur gunzip: %v", err))
-		return fmt.Errorf("erreur gunzip: %v", err)
+		return err
 	}
-	pipe.Close()
-
-	if err := cmdMysql.Wait(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur mysql restore: %v", err))
-		return fmt.Errorf("erreur mysql restore: %v", err)
+	defer dump.Close()
+	if err := restoreMySQLStream(ctx, dump, destArgs, database); err != nil {
+		return err
 	}
+	dump.Finish()
 
-	LogToFrontend("success", fmt.Sprintf("Transfert MySQL de %s terminé avec succès", database))
+	Log.Success(fmt.Sprintf("Transfert MySQL de %s terminé avec succès", database))
 	return nil
 }
 
-// RestoreMySQLBackup télécharge un backup S3 et le restaure dans MySQL
+// RestoreMySQLBackup restaure un backup S3 dans MySQL. Le backup est lu en flux
+// depuis S3 et décompressé à la volée, sans fichier temporaire.
 func RestoreMySQLBackup(ctx context.Context, creds S3Credentials, s3Path string, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database string) error {
-	bucket, region, endpoint := resolveS3Config(creds)
-	objectName := s3Path
-	awsCfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKey, creds.SecretKey, "")),
-	)
-	if err != nil {
-		return fmt.Errorf("erreur chargement config AWS: %v", err)
-	}
-	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
-		o.UsePathStyle = true
-	})
-	presignedURL, err := generatePresignedURL(ctx, client, bucket, objectName)
+	storage, err := newS3Storage(ctx, creds)
 	if err != nil {
 		return err
 	}
+	return restoreMySQLFromStorage(ctx, storage, s3Path, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database)
+}
 
-	// Récupère la taille du fichier pour la progression
-	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: &bucket, Key: &objectName})
-	var totalSize int64 = 0
-	if err == nil && head.ContentLength != nil {
-		totalSize = *head.ContentLength
-		LogToFrontend("info", fmt.Sprintf("Taille du backup MySQL à télécharger: %.2f MB", float64(totalSize)/(1024*1024)))
-	}
-
-	respBody, err := downloadWithRetry(presignedURL, 3, 30*time.Minute)
-	if err != nil {
-		return fmt.Errorf("erreur téléchargement HTTP: %v", err)
-	}
-	defer respBody.Close()
-
-	tmpDir, err := getUserTmpDir()
+// restoreMySQLFromStorage restaure la sauvegarde key de storage dans la base MySQL database
+func restoreMySQLFromStorage(ctx context.Context, storage BackupStorage, key string, mysqlHost, mysqlPort, mysqlUser, mysqlPassword, database string) error {
+	mysqlArgs, cleanup, err := mysqlClientArgs(mysqlHost, mysqlPort, mysqlUser, mysqlPassword)
 	if err != nil {
 		return err
 	}
-	tmpFile, err := os.CreateTemp(tmpDir, "mysql-backup-*.sql.gz")
-	if err != nil {
-		return fmt.Errorf("erreur création fichier temporaire: %v", err)
-	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
-
-	// Progression du téléchargement
-	progressReader := &progressReaderWithLog{
-		r:     respBody,
-		total: totalSize,
-	}
-	LogToFrontend("info", "Début du téléchargement du backup MySQL...")
-	_, err = io.Copy(tmpFile, progressReader)
-	if err != nil {
-		return fmt.Errorf("erreur écriture fichier: %v", err)
-	}
-	LogToFrontend("success", "Téléchargement du backup MySQL terminé.")
+	defer cleanup()
 
 	// Créer la base de données si elle n'existe pas
-	LogToFrontend("info", fmt.Sprintf("Création de la base de données %s si nécessaire...", database))
-	createDbArgs := []string{
-		"-h", mysqlHost,
-		"-P", mysqlPort,
-		"-u", mysqlUser,
-	}
-	if mysqlPassword != "" {
-		createDbArgs = append(createDbArgs, fmt.Sprintf("-p%s", mysqlPassword))
-	}
+	Log.Info(fmt.Sprintf("Création de la base de données %s si nécessaire...", database))
+	createDbArgs := append([]string{}, mysqlArgs...)
 	createDbArgs = append(createDbArgs, "-e", fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`;", database))
 
-	cmdCreateDb := exec.Command("mysql", createDbArgs...)
+	cmdCreateDb := exec.CommandContext(ctx, "mysql", createDbArgs...)
 	if err := cmdCreateDb.Run(); err != nil {
-		LogToFrontend("warn", fmt.Sprintf("Impossible de créer la base: %v", err))
-	}
-
-	// Restaurer le backup
-	LogToFrontend("info", "Début de la restauration MySQL...")
-
-	// Décompresser et restaurer
-	cmdGunzip := exec.Command("gunzip", "-c", tmpFile.Name())
-	cmdMysql := exec.Command("mysql",
-		"-h", mysqlHost,
-		"-P", mysqlPort,
-		"-u", mysqlUser,
-		database,
-	)
-
-	if mysqlPassword != "" {
-		cmdMysql = exec.Command("mysql",
-			"-h", mysqlHost,
-			"-P", mysqlPort,
-			"-u", mysqlUser,
-			fmt.Sprintf("-p%s", mysqlPassword),
-			database,
-		)
+		Log.Warn(fmt.Sprintf("Impossible de créer la base: %v", err))
 	}
 
-	// Connecter gunzip à mysql via pipe
-	pipe, err := cmdGunzip.StdoutPipe()
+	body, totalSize, err := storage.Open(ctx, key)
 	if err != nil {
-		return fmt.Errorf("erreur création pipe: %v", err)
-	}
-	cmdMysql.Stdin = pipe
-	cmdMysql.Stdout = os.Stdout
-	cmdMysql.Stderr = os.Stderr
-
-	// Démarrer les commandes
-	if err := cmdMysql.Start(); err != nil {
-		return fmt.Errorf("erreur démarrage mysql: %v", err)
+		return err
 	}
-	if err := cmdGunzip.Start(); err != nil {
-		return fmt.Errorf("erreur démarrage gunzip: %v", err)
+	defer body.Close()
+	if totalSize > 0 {
+		Log.Info(fmt.Sprintf("Taille du backup MySQL à restaurer: %.2f MB", float64(totalSize)/(1024*1024)))
 	}
 
-	// Attendre la fin
-	if err := cmdGunzip.Wait(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur gunzip: %v", err))
-		return fmt.Errorf("erreur gunzip: %v", err)
-	}
-	pipe.Close()
-
-	if err := cmdMysql.Wait(); err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur mysql restore: %v", err))
-		return fmt.Errorf("erreur mysql restore: %v", err)
+	// La progression suit les octets compressés lus depuis le stockage
+	Log.Info("Début de la restauration MySQL...")
+	progress := NewProgress(ctx, PhaseRestore, database, totalSize)
+	if err := restoreMySQLStream(ctx, progress.Reader(body), mysqlArgs, database); err != nil {
+		return err
 	}
+	progress.Finish()
 
-	LogToFrontend("success", "Restauration MySQL terminée avec succès.")
+	Log.Success("Restauration MySQL terminée avec succès.")
 	return nil
 }
 
 // TestMySQLConnection teste la connexion à un serveur MySQL
 func TestMySQLConnection(ctx context.Context, mysqlHost, mysqlPort, mysqlUser, mysqlPassword string) error {
-	args := []string{
-		"-h", mysqlHost,
-		"-P", mysqlPort,
-		"-u", mysqlUser,
-	}
-
-	if mysqlPassword != "" {
-		args = append(args, fmt.Sprintf("-p%s", mysqlPassword))
+	args, cleanup, err := mysqlClientArgs(mysqlHost, mysqlPort, mysqlUser, mysqlPassword)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
 
 	// Tester avec une simple requête SELECT 1
 	args = append(args, "-e", "SELECT 1;", "--batch")
 
-	cmd := exec.Command("mysql", args...)
+	cmd := exec.CommandContext(ctx, "mysql", args...)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("échec de la connexion MySQL: %v", err)
 	}
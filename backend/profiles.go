@@ -0,0 +1,564 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Moteurs de serveurs gérés par le store de profils
+const (
+	EngineMongo    = "mongo"
+	EngineMySQL    = "mysql"
+	EnginePostgres = "postgres"
+	EngineS3       = "s3"
+)
+
+// ServerProfile décrit un serveur enregistré (MongoDB, MySQL, PostgreSQL ou S3).
+// Les champs suivent ceux des anciens stores localStorage du frontend pour permettre la migration.
+// Sur disque, Password et SecretKey sont vides : le secret est conservé dans le coffre sous SecretRef.
+type ServerProfile struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Engine       string `json:"engine"`
+	Host         string `json:"host"`
+	Port         string `json:"port"`
+	User         string `json:"user,omitempty"`
+	Password     string `json:"password,omitempty"`
+	AuthDatabase string `json:"authDatabase,omitempty"`
+	Database     string `json:"database,omitempty"`
+	AccessKey    string `json:"accessKey,omitempty"`
+	SecretKey    string `json:"secretKey,omitempty"`
+	Region       string `json:"region,omitempty"`
+	UseHttps     bool   `json:"useHttps,omitempty"`
+	Bucket       string `json:"bucket,omitempty"`
+	AWSProfile   string `json:"awsProfile,omitempty"`
+	VirtualHost  bool   `json:"virtualHost,omitempty"`
+	CABundle     string `json:"caBundle,omitempty"`
+	SecretRef    string `json:"secretRef,omitempty"`
+	IsDefault    bool   `json:"isDefault,omitempty"`
+	CreatedAt    string `json:"createdAt,omitempty"`
+	UpdatedAt    string `json:"updatedAt,omitempty"`
+}
+
+// S3Credentials convertit un profil S3 en credentials utilisables par les fonctions S3
+func (p ServerProfile) S3Credentials() S3Credentials {
+	return S3Credentials{
+		AccessKey:   p.AccessKey,
+		SecretKey:   p.SecretKey,
+		Host:        p.Host,
+		Port:        p.Port,
+		Region:      p.Region,
+		UseHttps:    p.UseHttps,
+		Bucket:      p.Bucket,
+		Profile:     p.AWSProfile,
+		VirtualHost: p.VirtualHost,
+		CABundle:    p.CABundle,
+	}
+}
+
+// Address retourne l'adresse affichable du serveur : hôte:port, ou l'URI de connexion MongoDB
+// sans son mot de passe
+func (p ServerProfile) Address() string {
+	if !isMongoURI(p.Host) {
+		return p.Host + ":" + p.Port
+	}
+	u, err := url.Parse(strings.TrimSpace(p.Host))
+	if err != nil {
+		return "(URI MongoDB invalide)"
+	}
+	return u.Redacted()
+}
+
+// profileFile est le format du fichier servers.json
+type profileFile struct {
+	Version          int             `json:"version"`
+	Servers          []ServerProfile `json:"servers"`
+	BackupRepository string          `json:"backupRepository,omitempty"`
+}
+
+var profilesMu sync.Mutex
+
+// profilesPath retourne le chemin du fichier de profils (dossier de config utilisateur)
+func profilesPath() (string, error) {
+	dir, err := UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "servers.json"), nil
+}
+
+func loadProfileFile() (*profileFile, error) {
+	path, err := profilesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &profileFile{Version: 1}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture des profils serveurs: %v", err)
+	}
+	var pf profileFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("fichier de profils serveurs invalide (%s): %v", path, err)
+	}
+	return &pf, nil
+}
+
+func saveProfileFile(pf *profileFile) error {
+	path, err := profilesPath()
+	if err != nil {
+		return err
+	}
+	if err := sealProfileSecrets(pf.Servers); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("erreur création dossier de configuration: %v", err)
+	}
+	pf.Version = 1
+	data, err := json.MarshalIndent(pf, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Écriture atomique : fichier temporaire puis renommage
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("erreur écriture des profils serveurs: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func normalizeEngine(engine string) string {
+	switch strings.ToLower(strings.TrimSpace(engine)) {
+	case "mongo", "mongodb":
+		return EngineMongo
+	case "mysql", "mariadb":
+		return EngineMySQL
+	case "postgres", "postgresql", "pg":
+		return EnginePostgres
+	case "s3", "minio":
+		return EngineS3
+	}
+	return ""
+}
+
+// sealProfileSecrets déplace les mots de passe et secret keys en clair vers le coffre
+func sealProfileSecrets(profiles []ServerProfile) error {
+	var vault *Vault
+	for i := range profiles {
+		p := &profiles[i]
+		secret := p.Password
+		if p.Engine == EngineS3 {
+			secret = p.SecretKey
+		}
+		if secret == "" {
+			continue
+		}
+		if vault == nil {
+			v, err := DefaultVault()
+			if err != nil {
+				return err
+			}
+			vault = v
+		}
+		ref := "server/" + p.ID
+		if err := vault.Set(ref, secret); err != nil {
+			return fmt.Errorf("impossible d'enregistrer le secret du serveur '%s' dans le coffre: %w", p.Name, err)
+		}
+		p.SecretRef = ref
+		p.Password = ""
+		p.SecretKey = ""
+	}
+	return nil
+}
+
+// ResolveServerSecrets complète le mot de passe (ou la secret key S3) d'un profil depuis le coffre
+func ResolveServerSecrets(p *ServerProfile) error {
+	if p.SecretRef == "" || p.Password != "" || p.SecretKey != "" {
+		return nil
+	}
+	vault, err := DefaultVault()
+	if err != nil {
+		return err
+	}
+	secret, err := vault.Get(p.SecretRef)
+	if err != nil {
+		return fmt.Errorf("secret du serveur '%s' indisponible: %w", p.Name, err)
+	}
+	if p.Engine == EngineS3 {
+		p.SecretKey = secret
+	} else {
+		p.Password = secret
+	}
+	return nil
+}
+
+// deleteProfileSecret supprime le secret d'un profil supprimé
+func deleteProfileSecret(p ServerProfile) {
+	if p.SecretRef == "" {
+		return
+	}
+	vault, err := DefaultVault()
+	if err == nil {
+		err = vault.Delete(p.SecretRef)
+	}
+	if err != nil {
+		Log.Warn(fmt.Sprintf("Impossible de supprimer le secret %s: %v", p.SecretRef, err))
+	}
+}
+
+// ListServerProfiles liste les profils enregistrés, filtrés par moteur si engine n'est pas vide.
+// Les secrets ne sont pas résolus (voir ResolveServerSecrets).
+func ListServerProfiles(engine string) ([]ServerProfile, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	pf, err := loadProfileFile()
+	if err != nil {
+		return nil, err
+	}
+	engine = normalizeEngine(engine)
+	profiles := []ServerProfile{}
+	for _, p := range pf.Servers {
+		if engine == "" || p.Engine == engine {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles, nil
+}
+
+// GetServerProfile retrouve un profil par ID ou par nom et résout son secret depuis le coffre
+func GetServerProfile(ref string) (*ServerProfile, error) {
+	p, err := findServerProfile(ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := ResolveServerSecrets(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// findServerProfile retrouve un profil par ID ou par nom (insensible à la casse).
+// Les serveurs nommés de la configuration (.aidalinfo.yaml) sont utilisés en dernier recours.
+func findServerProfile(ref string) (*ServerProfile, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("nom de serveur vide")
+	}
+
+	profiles, err := ListServerProfiles("")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		if p.ID == ref {
+			return &p, nil
+		}
+	}
+	for _, p := range profiles {
+		if strings.EqualFold(p.Name, ref) {
+			return &p, nil
+		}
+	}
+
+	if server, ok := CurrentConfig().Servers[ref]; ok {
+		return &ServerProfile{
+			ID:           ref,
+			Name:         ref,
+			Engine:       normalizeEngine(server.Engine),
+			Host:         server.Host,
+			Port:         server.Port,
+			User:         server.User,
+			AuthDatabase: server.AuthDatabase,
+			SecretRef:    server.SecretRef,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("serveur '%s' introuvable", ref)
+}
+
+// SaveServerProfile crée ou met à jour un profil (un seul profil par défaut par moteur)
+func SaveServerProfile(profile ServerProfile) (ServerProfile, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	profile.Engine = normalizeEngine(profile.Engine)
+	if profile.Engine == "" {
+		return profile, fmt.Errorf("moteur de serveur invalide (mongo, mysql, postgres ou s3)")
+	}
+	if strings.TrimSpace(profile.Name) == "" {
+		return profile, fmt.Errorf("le nom du serveur est requis")
+	}
+
+	pf, err := loadProfileFile()
+	if err != nil {
+		return profile, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	profile.UpdatedAt = now
+	index := -1
+	for i, p := range pf.Servers {
+		if profile.ID != "" && p.ID == profile.ID {
+			index = i
+		} else if p.Engine == profile.Engine && strings.EqualFold(p.Name, profile.Name) {
+			return profile, fmt.Errorf("un serveur %s nommé '%s' existe déjà", profile.Engine, profile.Name)
+		}
+	}
+
+	if index == -1 {
+		if profile.ID == "" {
+			profile.ID = generateProfileID(profile.Engine)
+		}
+		if profile.CreatedAt == "" {
+			profile.CreatedAt = now
+		}
+		if countEngine(pf.Servers, profile.Engine) == 0 {
+			profile.IsDefault = true
+		}
+		pf.Servers = append(pf.Servers, profile)
+		index = len(pf.Servers) - 1
+	} else {
+		profile.CreatedAt = pf.Servers[index].CreatedAt
+		if profile.SecretRef == "" {
+			profile.SecretRef = pf.Servers[index].SecretRef
+		}
+		pf.Servers[index] = profile
+	}
+
+	if profile.IsDefault {
+		setDefaultProfile(pf.Servers, profile.Engine, profile.ID)
+	}
+
+	if err := saveProfileFile(pf); err != nil {
+		return profile, err
+	}
+	return pf.Servers[index], nil
+}
+
+// DeleteServerProfile supprime un profil par ID ou nom
+func DeleteServerProfile(ref string) error {
+	target, err := findServerProfile(ref)
+	if err != nil {
+		return err
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	pf, err := loadProfileFile()
+	if err != nil {
+		return err
+	}
+	filtered := pf.Servers[:0]
+	for _, p := range pf.Servers {
+		if p.ID != target.ID {
+			filtered = append(filtered, p)
+		}
+	}
+	if len(filtered) == len(pf.Servers) {
+		return fmt.Errorf("le serveur '%s' n'est pas un profil enregistré", ref)
+	}
+	pf.Servers = filtered
+
+	// Si on supprime le serveur par défaut, le premier du même moteur devient le défaut
+	if target.IsDefault {
+		for _, p := range pf.Servers {
+			if p.Engine == target.Engine {
+				setDefaultProfile(pf.Servers, p.Engine, p.ID)
+				break
+			}
+		}
+	}
+	if pf.BackupRepository == target.ID {
+		pf.BackupRepository = ""
+	}
+	if err := saveProfileFile(pf); err != nil {
+		return err
+	}
+	deleteProfileSecret(*target)
+	return nil
+}
+
+// ReplaceServerProfiles remplace tous les profils d'un moteur (synchronisation depuis le GUI)
+func ReplaceServerProfiles(engine string, profiles []ServerProfile) error {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	engine = normalizeEngine(engine)
+	if engine == "" {
+		return fmt.Errorf("moteur de serveur invalide")
+	}
+	pf, err := loadProfileFile()
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]ServerProfile{}
+	kept := []ServerProfile{}
+	for _, p := range pf.Servers {
+		if p.Engine != engine {
+			kept = append(kept, p)
+		} else {
+			previous[p.ID] = p
+		}
+	}
+	for _, p := range profiles {
+		p.Engine = engine
+		if p.ID == "" {
+			p.ID = generateProfileID(engine)
+		}
+		if old, ok := previous[p.ID]; ok {
+			if p.SecretRef == "" {
+				p.SecretRef = old.SecretRef
+			}
+			delete(previous, p.ID)
+		}
+		kept = append(kept, p)
+	}
+	pf.Servers = kept
+	if err := saveProfileFile(pf); err != nil {
+		return err
+	}
+	for _, removed := range previous {
+		deleteProfileSecret(removed)
+	}
+	return nil
+}
+
+// ImportLocalStorageProfiles importe le contenu JSON d'une ancienne clé localStorage
+// (mongoServers, mysqlServers, postgresServers, s3Servers). Les profils déjà connus (même ID) sont ignorés.
+func ImportLocalStorageProfiles(engine string, raw string) (int, error) {
+	engine = normalizeEngine(engine)
+	if engine == "" {
+		return 0, fmt.Errorf("moteur de serveur invalide")
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+
+	var imported []ServerProfile
+	if err := json.Unmarshal([]byte(raw), &imported); err != nil {
+		return 0, fmt.Errorf("format invalide: un tableau de serveurs est attendu: %v", err)
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	pf, err := loadProfileFile()
+	if err != nil {
+		return 0, err
+	}
+	known := map[string]bool{}
+	for _, p := range pf.Servers {
+		known[p.ID] = true
+	}
+
+	count := 0
+	for _, p := range imported {
+		if p.ID != "" && known[p.ID] {
+			continue
+		}
+		if strings.TrimSpace(p.Name) == "" || strings.TrimSpace(p.Host) == "" {
+			continue
+		}
+		p.Engine = engine
+		if p.ID == "" {
+			p.ID = generateProfileID(engine)
+		}
+		if p.IsDefault && countEngine(pf.Servers, engine) > 0 {
+			p.IsDefault = false
+		}
+		pf.Servers = append(pf.Servers, p)
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := saveProfileFile(pf); err != nil {
+		return 0, err
+	}
+	Log.Info(fmt.Sprintf("%d serveur(s) %s migré(s) vers le store de profils", count, engine))
+	return count, nil
+}
+
+// ErrNoBackupRepository est retourné quand aucun profil S3 n'est défini comme dépôt de backups
+var ErrNoBackupRepository = errors.New("aucun dépôt de backups configuré")
+
+// GetBackupRepositoryProfile retourne le profil S3 utilisé comme dépôt de backups
+func GetBackupRepositoryProfile() (*ServerProfile, error) {
+	profilesMu.Lock()
+	pf, err := loadProfileFile()
+	profilesMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if pf.BackupRepository == "" {
+		return nil, ErrNoBackupRepository
+	}
+	return GetServerProfile(pf.BackupRepository)
+}
+
+// SetBackupRepositoryProfile définit le profil S3 utilisé comme dépôt de backups (vide pour le retirer)
+func SetBackupRepositoryProfile(ref string) error {
+	id := ""
+	if ref != "" {
+		profile, err := findServerProfile(ref)
+		if err != nil {
+			return err
+		}
+		if profile.Engine != EngineS3 {
+			return fmt.Errorf("le dépôt de backups doit être un serveur S3")
+		}
+		id = profile.ID
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	pf, err := loadProfileFile()
+	if err != nil {
+		return err
+	}
+	pf.BackupRepository = id
+	return saveProfileFile(pf)
+}
+
+func countEngine(profiles []ServerProfile, engine string) int {
+	count := 0
+	for _, p := range profiles {
+		if p.Engine == engine {
+			count++
+		}
+	}
+	return count
+}
+
+func setDefaultProfile(profiles []ServerProfile, engine, id string) {
+	for i := range profiles {
+		if profiles[i].Engine == engine {
+			profiles[i].IsDefault = profiles[i].ID == id
+		}
+	}
+}
+
+// generateProfileID reprend le format d'ID du frontend (<moteur>_<timestamp>_<aléatoire>)
+func generateProfileID(engine string) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	suffix := make([]byte, 9)
+	for i := range suffix {
+		suffix[i] = letters[rand.Intn(len(letters))]
+	}
+	return fmt.Sprintf("%s_%d_%s", engine, time.Now().UnixMilli(), suffix)
+}
@@ -0,0 +1,261 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Phases d'une opération suivie
+const (
+	PhaseDownload = "download"
+	PhaseUpload   = "upload"
+	PhaseDump     = "dump"
+	PhaseRestore  = "restore"
+	PhaseVerify   = "verify"
+)
+
+// ProgressEvent décrit l'avancement d'une phase d'une opération.
+// Il est envoyé au frontend sur l'événement Wails "progress" et affiché en barre dans la CLI.
+type ProgressEvent struct {
+	OperationID string `json:"operationId"`
+	Phase       string `json:"phase"`
+	Label       string `json:"label,omitempty"`
+	BytesDone   int64  `json:"bytesDone"`
+	// BytesTotal vaut 0 quand la taille finale est inconnue (dumps)
+	BytesTotal int64 `json:"bytesTotal"`
+	// Percent vaut -1 quand la taille finale est inconnue
+	Percent float64 `json:"percent"`
+	// Rate est le débit moyen en octets par seconde
+	Rate float64 `json:"rate"`
+	// ETASeconds vaut -1 quand l'estimation est impossible
+	ETASeconds float64 `json:"etaSeconds"`
+	Done       bool    `json:"done"`
+}
+
+var (
+	progressListener   func(ProgressEvent)
+	progressListenerMu sync.RWMutex
+	operationCounter   int64
+)
+
+// SetProgressListener enregistre la fonction qui reçoit les événements de progression
+func SetProgressListener(listener func(ProgressEvent)) {
+	progressListenerMu.Lock()
+	progressListener = listener
+	progressListenerMu.Unlock()
+}
+
+// progressEmitInterval limite la fréquence des événements envoyés
+const progressEmitInterval = 250 * time.Millisecond
+
+// Progress suit l'avancement (en octets) d'une phase. Il est sûr pour un usage concurrent.
+type Progress struct {
+	ctx       context.Context
+	event     ProgressEvent
+	done      int64
+	start     time.Time
+	mu        sync.Mutex
+	lastEmit  time.Time
+	lastLog   int
+	finalized bool
+}
+
+// NewProgress démarre le suivi d'une phase. L'identifiant d'opération est celui du job
+// associé à ctx, ou un identifiant généré hors d'un job (CLI).
+func NewProgress(ctx context.Context, phase, label string, total int64) *Progress {
+	id := JobID(ctx)
+	if id == "" {
+		id = fmt.Sprintf("op-%d", atomic.AddInt64(&operationCounter, 1))
+	}
+	p := &Progress{
+		ctx:   ctx,
+		start: time.Now(),
+		event: ProgressEvent{
+			OperationID: id,
+			Phase:       phase,
+			Label:       label,
+			BytesTotal:  total,
+		},
+		lastLog: -1,
+	}
+	p.emit(true)
+	return p
+}
+
+// Add ajoute n octets traités
+func (p *Progress) Add(n int64) {
+	atomic.AddInt64(&p.done, n)
+	p.emit(false)
+}
+
+// Finish envoie l'événement final de la phase
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	if p.finalized {
+		p.mu.Unlock()
+		return
+	}
+	p.finalized = true
+	p.mu.Unlock()
+	p.emitEvent(true)
+}
+
+// Reader retourne un io.Reader qui comptabilise les octets lus
+func (p *Progress) Reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, p: p}
+}
+
+// Writer retourne un io.Writer qui comptabilise les octets écrits
+func (p *Progress) Writer(w io.Writer) io.Writer {
+	return &progressWriter{w: w, p: p}
+}
+
+func (p *Progress) emit(force bool) {
+	p.mu.Lock()
+	if p.finalized {
+		p.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	if !force && now.Sub(p.lastEmit) < progressEmitInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastEmit = now
+	p.mu.Unlock()
+	p.emitEvent(false)
+}
+
+func (p *Progress) snapshot(final bool) ProgressEvent {
+	ev := p.event
+	ev.BytesDone = atomic.LoadInt64(&p.done)
+	ev.Done = final
+
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed > 0 {
+		ev.Rate = float64(ev.BytesDone) / elapsed
+	}
+	ev.Percent = -1
+	ev.ETASeconds = -1
+	if ev.BytesTotal > 0 {
+		ev.Percent = float64(ev.BytesDone) * 100 / float64(ev.BytesTotal)
+		if ev.Percent > 100 {
+			ev.Percent = 100
+		}
+		if ev.Rate > 0 && ev.BytesDone < ev.BytesTotal {
+			ev.ETASeconds = float64(ev.BytesTotal-ev.BytesDone) / ev.Rate
+		}
+	}
+	if final {
+		ev.ETASeconds = 0
+		if ev.BytesTotal > 0 {
+			ev.Percent = 100
+		}
+	}
+	return ev
+}
+
+func (p *Progress) emitEvent(final bool) {
+	ev := p.snapshot(final)
+
+	// Trace dans les logs tous les 10%, pour garder un historique sans inonder la console
+	if ev.Percent >= 0 {
+		step := int(ev.Percent) / 10
+		p.mu.Lock()
+		shouldLog := step > p.lastLog
+		if shouldLog {
+			p.lastLog = step
+		}
+		p.mu.Unlock()
+		if shouldLog {
+			Log.Debug(fmt.Sprintf("%s: %d%% (%s/%s)", ev.Phase, int(ev.Percent), FormatBytes(ev.BytesDone), FormatBytes(ev.BytesTotal)),
+				F("operation", ev.OperationID))
+		}
+	}
+
+	if ev.Percent >= 0 {
+		ReportProgress(p.ctx, ev.Percent, fmt.Sprintf("%s %s/%s", ev.Phase, FormatBytes(ev.BytesDone), FormatBytes(ev.BytesTotal)))
+	} else {
+		ReportProgress(p.ctx, -1, fmt.Sprintf("%s %s", ev.Phase, FormatBytes(ev.BytesDone)))
+	}
+
+	progressListenerMu.RLock()
+	listener := progressListener
+	progressListenerMu.RUnlock()
+	if listener != nil {
+		listener(ev)
+	}
+}
+
+type progressReader struct {
+	r io.Reader
+	p *Progress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.p.Add(int64(n))
+	}
+	return n, err
+}
+
+type progressWriter struct {
+	w io.Writer
+	p *Progress
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	if n > 0 {
+		w.p.Add(int64(n))
+	}
+	return n, err
+}
+
+// FormatBytes formate une taille en unités binaires (Ko, Mo, Go)
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d o", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %co", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// trackedFile est un fichier ouvert en lecture dont la consommation alimente une progression
+type trackedFile struct {
+	f        *os.File
+	r        io.Reader
+	progress *Progress
+}
+
+// openFileWithProgress ouvre path en lecture et suit sa lecture (taille connue d'avance)
+func openFileWithProgress(ctx context.Context, path, phase, label string) (*trackedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erreur ouverture fichier: %v", err)
+	}
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	progress := NewProgress(ctx, phase, label, size)
+	return &trackedFile{f: f, r: progress.Reader(f), progress: progress}, nil
+}
+
+func (t *trackedFile) Read(b []byte) (int, error) { return t.r.Read(b) }
+
+// Finish marque la phase comme terminée
+func (t *trackedFile) Finish() { t.progress.Finish() }
+
+func (t *trackedFile) Close() error { return t.f.Close() }
@@ -0,0 +1,61 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RestoreOptions règle la restauration d'une sauvegarde du dépôt
+type RestoreOptions struct {
+	// Server est le serveur enregistré (ID ou nom) qui reçoit la sauvegarde
+	Server string `json:"server"`
+	// Database est la base de destination (par défaut, la base d'origine de la sauvegarde)
+	Database string `json:"database"`
+}
+
+// RestoreRepositoryBackup restaure une sauvegarde du dépôt de backups (S3 ou dossier local) sur
+// un serveur enregistré. ref est une clé du dépôt ou une URL s3://bucket/clé.
+func RestoreRepositoryBackup(ctx context.Context, ref string, opts RestoreOptions) error {
+	bucket, key := parseBackupRef(ref)
+	if key == "" {
+		return fmt.Errorf("la clé de la sauvegarde est requise")
+	}
+	repo, err := openBackupRepository(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	backup, err := repo.describeBackup(ctx, key)
+	if err != nil {
+		return err
+	}
+	profile, err := GetServerProfile(opts.Server)
+	if err != nil {
+		return err
+	}
+	if profile.Engine != backup.Engine {
+		return fmt.Errorf("le serveur '%s' est de type %s (attendu: %s)", profile.Name, profile.Engine, backup.Engine)
+	}
+	database := strings.TrimSpace(opts.Database)
+	if database == "" {
+		database = backup.Database
+	}
+	if database == "" && backup.Engine != EngineMongo {
+		return fmt.Errorf("base de destination inconnue pour %s, précisez-la", key)
+	}
+
+	Log.Info(fmt.Sprintf("Restauration de %s/%s sur %s", repo.storage, key, profile.Name))
+	switch backup.Engine {
+	case EnginePostgres:
+		return restorePostgresFromStorage(ctx, repo.storage, key, profile.Host, profile.Port, profile.User, profile.Password, database)
+	case EngineMySQL:
+		return restoreMySQLFromStorage(ctx, repo.storage, key, profile.Host, profile.Port, profile.User, profile.Password, database)
+	case EngineMongo:
+		var rename []string
+		if backup.Database != "" && database != backup.Database {
+			rename = []string{"--nsFrom", backup.Database + ".*", "--nsTo", database + ".*"}
+		}
+		return restoreMongoFromStorage(ctx, repo.storage, key, profile.Host, profile.Port, profile.User, profile.Password, rename...)
+	}
+	return fmt.Errorf("restauration non prise en charge pour le type %s", backup.Engine)
+}
@@ -0,0 +1,164 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Les restaurations Postgres et MySQL lisent le backup en flux :
+// S3 -> décompression gzip/zstd (en Go) -> entrée standard de psql/pg_restore/mysql.
+// Aucun fichier temporaire n'est écrit et le dump n'est jamais chargé en mémoire.
+
+// dumpFormat est le format d'un dump détecté à partir de ses premiers octets
+type dumpFormat string
+
+const (
+	dumpFormatPlain  dumpFormat = "plain"  // SQL texte (psql, mysql)
+	dumpFormatCustom dumpFormat = "custom" // pg_dump -Fc, en-tête "PGDMP"
+	dumpFormatTar    dumpFormat = "tar"    // pg_dump -Ft, en-tête tar "ustar"
+)
+
+// dumpSniffSize couvre l'en-tête tar, dont la signature "ustar" est à l'offset 257
+const dumpSniffSize = 512
+
+// maxCompressionLayers limite le nombre de couches de compression retirées (les anciens
+// dumps Postgres étaient compressés deux fois : pg_dump -Z 9 puis gzip)
+const maxCompressionLayers = 3
+
+// openDumpStream déchiffre (sauvegarde chiffrée) et décompresse r à la volée (gzip ou zstd,
+// éventuellement imbriqués, ou aucune compression) et détecte le format du dump sans consommer
+// les octets lus.
+func openDumpStream(r io.Reader) (io.Reader, dumpFormat, error) {
+	br, err := maybeDecrypt(bufio.NewReaderSize(r, 64*1024))
+	if err != nil {
+		return nil, "", err
+	}
+	for layer := 0; layer < maxCompressionLayers; layer++ {
+		c, err := detectCompression(br)
+		if err != nil {
+			return nil, "", err
+		}
+		if c == CompressionNone {
+			break
+		}
+		dr, _, err := newDecompressReader(br)
+		if err != nil {
+			return nil, "", err
+		}
+		br = bufio.NewReaderSize(dr, 64*1024)
+	}
+
+	head, err := br.Peek(dumpSniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", fmt.Errorf("erreur lecture du backup: %v", err)
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("PGDMP")):
+		return br, dumpFormatCustom, nil
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return br, dumpFormatTar, nil
+	}
+	return br, dumpFormatPlain, nil
+}
+
+// ensurePostgresDatabase crée la base si elle n'existe pas encore
+func ensurePostgresDatabase(ctx context.Context, env []string, host, port, user, database string) error {
+	checkCmd := exec.CommandContext(ctx, "psql",
+		"-h", host,
+		"-p", port,
+		"-U", user,
+		"-d", "postgres",
+		"-t",
+		"-c", fmt.Sprintf("SELECT 1 FROM pg_database WHERE datname = '%s'", database))
+	checkCmd.Env = env
+	checkOutput, _ := checkCmd.Output()
+
+	if strings.TrimSpace(string(checkOutput)) != "" {
+		Log.Info(fmt.Sprintf("La base de données %s existe déjà", database))
+		return nil
+	}
+
+	createCmd := exec.CommandContext(ctx, "psql",
+		"-h", host,
+		"-p", port,
+		"-U", user,
+		"-d", "postgres",
+		"-c", fmt.Sprintf("CREATE DATABASE %s", database))
+	createCmd.Env = env
+	if err := createCmd.Run(); err != nil {
+		Log.Error(fmt.Sprintf("Impossible de créer la base: %v", err))
+		return fmt.Errorf("impossible de créer la base de données: %v", err)
+	}
+	Log.Success(fmt.Sprintf("Base de données %s créée avec succès", database))
+	return nil
+}
+
+// restorePostgresStream restaure un dump (compressé ou non) lu sur r.
+// Les formats custom et tar passent par pg_restore, le SQL texte par psql.
+func restorePostgresStream(ctx context.Context, r io.Reader, env []string, host, port, user, database string) error {
+	dump, format, err := openDumpStream(r)
+	if err != nil {
+		return err
+	}
+
+	var restoreCmd *exec.Cmd
+	switch format {
+	case dumpFormatCustom, dumpFormatTar:
+		Log.Info(fmt.Sprintf("Dump PostgreSQL au format %s, restauration avec pg_restore", format))
+		formatFlag := "c"
+		if format == dumpFormatTar {
+			formatFlag = "t"
+		}
+		restoreCmd = exec.CommandContext(ctx, "pg_restore",
+			"-h", host,
+			"-p", port,
+			"-U", user,
+			"-d", database,
+			"-F", formatFlag,
+			"--clean",
+			"--if-exists",
+			"--no-owner",
+			"--no-privileges")
+	default:
+		Log.Info("Dump PostgreSQL au format SQL, restauration avec psql")
+		restoreCmd = exec.CommandContext(ctx, "psql",
+			"-h", host,
+			"-p", port,
+			"-U", user,
+			"-d", database)
+	}
+
+	restoreCmd.Env = env
+	restoreCmd.Stdin = dump
+	restoreCmd.Stdout = os.Stdout
+	restoreCmd.Stderr = os.Stderr
+	if err := restoreCmd.Run(); err != nil {
+		Log.Error(fmt.Sprintf("Erreur restauration PostgreSQL: %v", err))
+		return fmt.Errorf("erreur restauration PostgreSQL: %v", err)
+	}
+	return nil
+}
+
+// restoreMySQLStream restaure un dump SQL (compressé ou non) lu sur r dans database
+func restoreMySQLStream(ctx context.Context, r io.Reader, mysqlArgs []string, database string) error {
+	dump, _, err := openDumpStream(r)
+	if err != nil {
+		return err
+	}
+
+	cmdMysql := exec.CommandContext(ctx, "mysql", append(append([]string{}, mysqlArgs...), database)...)
+	cmdMysql.Stdin = dump
+	cmdMysql.Stdout = os.Stdout
+	cmdMysql.Stderr = os.Stderr
+	if err := cmdMysql.Run(); err != nil {
+		Log.Error(fmt.Sprintf("Erreur mysql restore: %v", err))
+		return fmt.Errorf("erreur mysql restore: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IsZero indique que la politique ne supprime rien
+func (p RetentionPolicy) IsZero() bool {
+	return p.Daily <= 0 && p.Weekly <= 0 && p.Monthly <= 0
+}
+
+func (p RetentionPolicy) String() string {
+	if p.IsZero() {
+		return "tout conserver"
+	}
+	return fmt.Sprintf("%d jour(s), %d semaine(s), %d mois", p.Daily, p.Weekly, p.Monthly)
+}
+
+// datedBackup est une sauvegarde du dépôt identifiée par l'horodatage de sa clé
+type datedBackup struct {
+	Key  string
+	Time time.Time
+}
+
+// selectExpiredBackups applique la politique grand-père/père/fils : la sauvegarde la plus récente
+// de chacun des Daily derniers jours, Weekly dernières semaines (ISO) et Monthly derniers mois
+// est conservée. La plus récente de toutes est toujours conservée.
+func selectExpiredBackups(backups []datedBackup, policy RetentionPolicy) []datedBackup {
+	if policy.IsZero() || len(backups) == 0 {
+		return nil
+	}
+	sorted := append([]datedBackup{}, backups...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Time.After(sorted[j].Time) })
+
+	keep := map[string]bool{sorted[0].Key: true}
+	rules := []struct {
+		limit  int
+		period func(time.Time) string
+	}{
+		{policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{policy.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, rule := range rules {
+		seen := map[string]bool{}
+		for _, backup := range sorted {
+			if len(seen) >= rule.limit {
+				break
+			}
+			period := rule.period(backup.Time.UTC())
+			if !seen[period] {
+				seen[period] = true
+				keep[backup.Key] = true
+			}
+		}
+	}
+
+	var expired []datedBackup
+	for _, backup := range sorted {
+		if !keep[backup.Key] {
+			expired = append(expired, backup)
+		}
+	}
+	return expired
+}
+
+// listDatedBackups liste les sauvegardes horodatées placées directement dans dir
+func (r *backupRepository) listDatedBackups(ctx context.Context, dir string) ([]datedBackup, error) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	objects, err := r.storage.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var backups []datedBackup
+	for _, obj := range objects {
+		// Les sous-dossiers appartiennent à d'autres sources
+		if isSidecarKey(obj.Key) || strings.Contains(strings.TrimPrefix(obj.Key, prefix), "/") {
+			continue
+		}
+		if t, ok := parseBackupTime(obj.Key); ok {
+			backups = append(backups, datedBackup{Key: obj.Key, Time: t})
+		}
+	}
+	return backups, nil
+}
+
+// applyRetention supprime du dossier dir les sauvegardes que policy ne conserve pas.
+// Retourne le nombre de sauvegardes supprimées.
+func (r *backupRepository) applyRetention(ctx context.Context, dir string, policy RetentionPolicy) (int, error) {
+	if policy.IsZero() {
+		return 0, nil
+	}
+	backups, err := r.listDatedBackups(ctx, dir)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, backup := range selectExpiredBackups(backups, policy) {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		if err := r.storage.Delete(ctx, backup.Key); err != nil {
+			return deleted, err
+		}
+		// Supprimer un fichier annexe absent n'est pas une erreur
+		for _, suffix := range sidecarSuffixes {
+			if err := r.storage.Delete(ctx, backup.Key+suffix); err != nil {
+				Log.Warn(err.Error())
+			}
+		}
+		Log.Info(fmt.Sprintf("Sauvegarde expirée supprimée: %s", backup.Key))
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,141 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Navigation objet par objet dans n'importe quel bucket des identifiants S3Credentials : le
+// listing passe par ListBackupsPage, le téléchargement par DownloadBackupWithCreds.
+
+// Durée de validité des liens présignés (SigV4 n'accepte pas plus de 7 jours)
+const (
+	defaultPresignExpiry = 20 * time.Minute
+	maxPresignExpiry     = 7 * 24 * time.Hour
+)
+
+// ObjectInfo décrit un objet S3 (HeadObject)
+type ObjectInfo struct {
+	Bucket       string            `json:"bucket"`
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	LastModified time.Time         `json:"lastModified"`
+	ETag         string            `json:"etag"`
+	ContentType  string            `json:"contentType"`
+	StorageClass string            `json:"storageClass"`
+	Metadata     map[string]string `json:"metadata"`
+	// Archive est la disponibilité de l'objet s'il est en stockage froid
+	Archive *ArchiveStatus `json:"archive,omitempty"`
+}
+
+// PresignedURL est un lien temporaire vers un objet
+type PresignedURL struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// presignObjectURL génère un lien GET ou PUT valable expires
+func presignObjectURL(ctx context.Context, client *s3.Client, bucket, key, method string, expires time.Duration) (*PresignedURL, error) {
+	if expires <= 0 {
+		expires = defaultPresignExpiry
+	}
+	if expires > maxPresignExpiry {
+		return nil, fmt.Errorf("durée de validité trop longue (%s, 7 jours au plus)", expires)
+	}
+	presigner := s3.NewPresignClient(client)
+	withExpiry := func(opts *s3.PresignOptions) { opts.Expires = expires }
+
+	var (
+		presigned *v4.PresignedHTTPRequest
+		err       error
+	)
+	method = strings.ToUpper(method)
+	switch method {
+	case "", http.MethodGet:
+		method = http.MethodGet
+		presigned, err = presigner.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}, withExpiry)
+	case http.MethodPut:
+		presigned, err = presigner.PresignPutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}, withExpiry)
+	default:
+		return nil, fmt.Errorf("méthode '%s' non prise en charge (GET ou PUT)", method)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erreur génération presigned URL: %v", err)
+	}
+	return &PresignedURL{URL: presigned.URL, Method: method, ExpiresAt: time.Now().Add(expires)}, nil
+}
+
+// PresignS3Object génère un lien GET (téléchargement) ou PUT (envoi) vers key, valable expires
+// (20 minutes par défaut, 7 jours au plus)
+func PresignS3Object(ctx context.Context, creds S3Credentials, key, method string, expires time.Duration) (*PresignedURL, error) {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	return presignObjectURL(ctx, client, bucket, key, method, expires)
+}
+
+// StatS3Object retourne les métadonnées d'un objet
+func StatS3Object(ctx context.Context, creds S3Credentials, key string) (*ObjectInfo, error) {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("objet introuvable %s/%s: %v", bucket, key, err)
+	}
+	info := &ObjectInfo{
+		Bucket:       bucket,
+		Key:          key,
+		Size:         derefInt64(head.ContentLength),
+		LastModified: aws.ToTime(head.LastModified),
+		ETag:         strings.Trim(aws.ToString(head.ETag), `"`),
+		ContentType:  aws.ToString(head.ContentType),
+		StorageClass: storageClassLabel(string(head.StorageClass)),
+		Metadata:     head.Metadata,
+	}
+	if isArchivedStorageClass(string(head.StorageClass)) || head.ArchiveStatus != "" {
+		if info.Archive, err = headArchiveStatus(ctx, client, bucket, key); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// UploadS3Object envoie un fichier local sous key
+func UploadS3Object(ctx context.Context, creds S3Credentials, localPath, key string) error {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return err
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return fmt.Errorf("clé de destination invalide '%s'", key)
+	}
+	if err := uploadFileToS3(ctx, client, bucket, key, localPath, nil); err != nil {
+		return err
+	}
+	Log.Success(fmt.Sprintf("%s envoyé sous %s/%s", localPath, bucket, key))
+	return nil
+}
+
+// DeleteS3Object supprime un objet
+func DeleteS3Object(ctx context.Context, creds S3Credentials, key string) error {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return err
+	}
+	if _, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}); err != nil {
+		return fmt.Errorf("erreur suppression de %s/%s: %v", bucket, key, err)
+	}
+	Log.Info(fmt.Sprintf("%s/%s supprimé", bucket, key))
+	return nil
+}
@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// newS3Client crée le client S3 de creds et retourne le bucket résolu. C'est le seul point de
+// construction des clients S3 de l'application.
+//
+// Les identifiants sont choisis dans cet ordre :
+//   - AccessKey/SecretKey (et SessionToken pour des clés temporaires) ;
+//   - le profil AWS Profile (~/.aws/config, y compris SSO et assume-role) ;
+//   - la chaîne AWS standard : variables AWS_*, AWS_PROFILE, rôle d'instance ou de conteneur.
+//
+// Pour un hôte AWS (*.amazonaws.com), le point d'accès de la région est résolu par le SDK. Les champs absents
+// (profil, adressage, autorités) reprennent la section backup.s3 de la configuration.
+func newS3Client(ctx context.Context, creds S3Credentials) (*s3.Client, string, error) {
+	defaults := CurrentConfig().Backup.S3
+	bucket, region, endpoint := resolveS3Config(creds)
+
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	switch {
+	case creds.AccessKey != "":
+		RegisterSecret(creds.SessionToken)
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(creds.AccessKey, creds.SecretKey, creds.SessionToken)))
+	case creds.Profile != "":
+		opts = append(opts, config.WithSharedConfigProfile(creds.Profile))
+	case defaults.Profile != "":
+		opts = append(opts, config.WithSharedConfigProfile(defaults.Profile))
+	}
+
+	caBundle := strings.TrimSpace(creds.CABundle)
+	if caBundle == "" {
+		caBundle = defaults.CABundle
+	}
+	if caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, "", fmt.Errorf("erreur lecture du bundle CA %s: %v", caBundle, err)
+		}
+		opts = append(opts, config.WithCustomCABundle(bytes.NewReader(pem)))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("erreur chargement config AWS: %v", err)
+	}
+
+	virtualHost := creds.VirtualHost || (strings.TrimSpace(creds.Host) == "" && defaults.VirtualHost != nil && *defaults.VirtualHost)
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		// Les stockages compatibles (MinIO, Scaleway...) sont adressés par chemin par défaut
+		o.UsePathStyle = endpoint != "" && !virtualHost
+	})
+	return client, bucket, nil
+}
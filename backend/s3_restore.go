@@ -0,0 +1,295 @@
+package backend
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Une archive de restauration S3 (snapshot) contient un dossier par bucket à sa racine :
+// "<bucket>/<clé>". Chaque fichier est envoyé sous sa clé complète, sous-dossiers compris.
+// Les objets déjà présents avec la même taille et le même ETag sont ignorés, ce qui permet de
+// relancer une restauration interrompue.
+
+// s3UploadPartSize est la taille des parties des uploads multipart. Elle sert aussi à recalculer
+// l'ETag d'un objet envoyé en plusieurs parties.
+const s3UploadPartSize = 16 * 1024 * 1024
+
+// s3RestoreConcurrency est le nombre de fichiers envoyés en parallèle
+const s3RestoreConcurrency = 4
+
+// restoreObject est un fichier extrait à envoyer
+type restoreObject struct {
+	bucket string
+	key    string
+	path   string
+	size   int64
+}
+
+// remoteObject décrit un objet déjà présent dans le bucket de destination
+type remoteObject struct {
+	size int64
+	etag string
+}
+
+func restoreS3FromArchive(ctx context.Context, localCreds S3Credentials, archivePath, tmpDir, s3Host, s3Port, s3Region string, s3UseHttps bool) error {
+	if s3Host == "" {
+		return fmt.Errorf("l'hôte du S3 de destination est requis")
+	}
+	Log.Debug("Début de la décompression...")
+	// Les fichiers extraits occupent au moins la taille de l'archive
+	if info, err := os.Stat(archivePath); err == nil {
+		if err := ensureFreeSpace(tmpDir, info.Size(), "l'extraction de l'archive"); err != nil {
+			return err
+		}
+	}
+
+	// Décompresse l'archive (tar.gz ou tar.zst) dans un dossier temporaire
+	extractDir, err := os.MkdirTemp(tmpDir, "s3-restore-*")
+	if err != nil {
+		Log.Error(fmt.Sprintf("Erreur création dossier temporaire: %v", err))
+		return fmt.Errorf("erreur création dossier temporaire: %v", err)
+	}
+	defer os.RemoveAll(extractDir)
+	Log.Debug(fmt.Sprintf("Extraction de l'archive dans: %s", extractDir))
+	if err := ExtractTarArchive(ctx, archivePath, extractDir); err != nil {
+		Log.Error(fmt.Sprintf("Erreur extraction archive: %v", err))
+		return err
+	}
+
+	buckets, objects, err := collectRestoreObjects(extractDir)
+	if err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		Log.Error("Aucun dossier de bucket trouvé dans l'archive")
+		return fmt.Errorf("aucun dossier de bucket trouvé dans l'archive")
+	}
+	Log.Info(fmt.Sprintf("%d objet(s) à restaurer dans %d bucket(s): %s", len(objects), len(buckets), strings.Join(buckets, ", ")))
+
+	// Utilise les credentials locaux pour uploader dans le S3 local
+	localClient, _, err := newS3Client(ctx, S3Credentials{
+		AccessKey: localCreds.AccessKey,
+		SecretKey: localCreds.SecretKey,
+		Host:      s3Host,
+		Port:      s3Port,
+		Region:    s3Region,
+		UseHttps:  s3UseHttps,
+	})
+	if err != nil {
+		Log.Error(fmt.Sprintf("Erreur config S3 local: %v", err))
+		return fmt.Errorf("erreur config S3 local: %v", err)
+	}
+
+	existing := make(map[string]map[string]remoteObject, len(buckets))
+	for _, bucket := range buckets {
+		if err := ensureBucket(ctx, localClient, bucket); err != nil {
+			return err
+		}
+		if existing[bucket], err = listRemoteObjects(ctx, localClient, bucket); err != nil {
+			return err
+		}
+	}
+
+	var totalBytes int64
+	for _, obj := range objects {
+		totalBytes += obj.size
+	}
+	progress := NewProgress(ctx, PhaseUpload, filepath.Base(archivePath), totalBytes)
+	uploader := manager.NewUploader(localClient, func(u *manager.Uploader) {
+		u.PartSize = s3UploadPartSize
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg                sync.WaitGroup
+		errOnce           sync.Once
+		firstErr          error
+		uploaded, skipped int64
+	)
+	sem := make(chan struct{}, s3RestoreConcurrency)
+	for _, obj := range objects {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(obj restoreObject) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if remote, ok := existing[obj.bucket][obj.key]; ok && sameObject(obj, remote) {
+				Log.Debug(fmt.Sprintf("%s/%s déjà présent, ignoré", obj.bucket, obj.key))
+				atomic.AddInt64(&skipped, 1)
+				progress.Add(obj.size)
+				return
+			}
+			if err := uploadRestoreObject(ctx, uploader, obj, progress); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			atomic.AddInt64(&uploaded, 1)
+		}(obj)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		Log.Error(firstErr.Error())
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	progress.Finish()
+	Log.Info(fmt.Sprintf("%d objet(s) envoyé(s), %d déjà présent(s) ignoré(s)", uploaded, skipped))
+	return nil
+}
+
+// collectRestoreObjects parcourt le dossier extrait : chaque sous-dossier de premier niveau est
+// un bucket, chaque fichier en dessous un objet dont la clé est le chemin relatif au bucket
+func collectRestoreObjects(extractDir string) ([]string, []restoreObject, error) {
+	entries, err := os.ReadDir(extractDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erreur lecture du dossier extrait: %v", err)
+	}
+	var buckets []string
+	var objects []restoreObject
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			Log.Warn(fmt.Sprintf("%s est à la racine de l'archive, hors de tout bucket : ignoré", entry.Name()))
+			continue
+		}
+		bucket := entry.Name()
+		bucketPath := filepath.Join(extractDir, bucket)
+		err := filepath.WalkDir(bucketPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(bucketPath, path)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, restoreObject{bucket: bucket, key: filepath.ToSlash(rel), path: path, size: info.Size()})
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("erreur lecture du bucket extrait %s: %v", bucket, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, objects, nil
+}
+
+// ensureBucket crée le bucket s'il n'existe pas
+func ensureBucket(ctx context.Context, client *s3.Client, bucket string) error {
+	if _, err := client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)}); err == nil {
+		return nil
+	}
+	Log.Debug(fmt.Sprintf("Bucket %s n'existe pas, création...", bucket))
+	if _, err := client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		Log.Error(fmt.Sprintf("Erreur création bucket local: %v", err))
+		return fmt.Errorf("erreur création bucket local %s: %v", bucket, err)
+	}
+	return nil
+}
+
+// listRemoteObjects retourne la taille et l'ETag des objets du bucket
+func listRemoteObjects(ctx context.Context, client *s3.Client, bucket string) (map[string]remoteObject, error) {
+	objects := make(map[string]remoteObject)
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{Bucket: aws.String(bucket)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("erreur listing du bucket %s: %v", bucket, err)
+		}
+		for _, obj := range page.Contents {
+			objects[aws.ToString(obj.Key)] = remoteObject{
+				size: derefInt64(obj.Size),
+				etag: strings.Trim(aws.ToString(obj.ETag), `"`),
+			}
+		}
+	}
+	return objects, nil
+}
+
+// sameObject compare un fichier local à un objet existant (taille puis ETag)
+func sameObject(obj restoreObject, remote remoteObject) bool {
+	if obj.size != remote.size || remote.etag == "" {
+		return false
+	}
+	etag, err := localETag(obj.path, obj.size, strings.Contains(remote.etag, "-"))
+	return err == nil && strings.EqualFold(etag, remote.etag)
+}
+
+// localETag calcule l'ETag S3 d'un fichier : MD5 du contenu pour un upload simple, MD5 des MD5
+// des parties suivi de "-<nombre de parties>" pour un upload multipart de s3UploadPartSize
+func localETag(path string, size int64, multipart bool) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if !multipart {
+		h := md5.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	var sums []byte
+	parts := 0
+	for offset := int64(0); offset < size; offset += s3UploadPartSize {
+		h := md5.New()
+		if _, err := io.Copy(h, io.NewSectionReader(f, offset, min(s3UploadPartSize, size-offset))); err != nil {
+			return "", err
+		}
+		sums = h.Sum(sums)
+		parts++
+	}
+	total := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(total[:]), parts), nil
+}
+
+// uploadRestoreObject envoie un fichier extrait sous sa clé
+func uploadRestoreObject(ctx context.Context, uploader *manager.Uploader, obj restoreObject, progress *Progress) error {
+	f, err := os.Open(obj.path)
+	if err != nil {
+		return fmt.Errorf("erreur ouverture fichier à restaurer: %v", err)
+	}
+	defer f.Close()
+	Log.Debug(fmt.Sprintf("Upload de %s/%s (%.2f MB)", obj.bucket, obj.key, float64(obj.size)/(1024*1024)))
+	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(obj.bucket),
+		Key:           aws.String(obj.key),
+		Body:          progress.Reader(f),
+		ContentLength: aws.Int64(obj.size),
+	})
+	if err != nil {
+		return fmt.Errorf("erreur upload objet S3 local %s/%s: %v", obj.bucket, obj.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package backend
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type snapshotObject struct {
+	key  string
+	size int64
+	mod  time.Time
+}
+
+// SnapshotS3Bucket copie les objets de bucket (ceux sous prefix si non vide) dans une archive tar
+// compressée destPath. Les objets sont placés sous "<bucket>/<clé>", la disposition attendue par
+// RestoreS3BackupFromLocal. Les objets archivés en stockage froid sont ignorés.
+func SnapshotS3Bucket(ctx context.Context, creds S3Credentials, bucket, prefix, destPath string, c Compression) (err error) {
+	if bucket == "" {
+		bucket = creds.Bucket
+	}
+	if bucket == "" {
+		return fmt.Errorf("le bucket à copier est requis")
+	}
+	creds.Bucket = bucket
+	client, _, err := newS3Client(ctx, creds)
+	if err != nil {
+		return err
+	}
+
+	// Liste complète d'abord, pour connaître le volume total à copier
+	var objects []snapshotObject
+	var total int64
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bucket)}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	paginator := s3.NewListObjectsV2Paginator(client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("erreur listing du bucket %s: %v", bucket, err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			// Les marqueurs de dossier ("photos/") n'ont pas de contenu
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			if isArchivedStorageClass(string(obj.StorageClass)) {
+				Log.Warn(fmt.Sprintf("%s est en %s, ignoré par le snapshot", key, obj.StorageClass))
+				continue
+			}
+			objects = append(objects, snapshotObject{key, derefInt64(obj.Size), aws.ToTime(obj.LastModified)})
+			total += derefInt64(obj.Size)
+		}
+	}
+	Log.Info(fmt.Sprintf("Snapshot du bucket %s: %d objet(s), %s", bucket, len(objects), FormatBytes(total)))
+	// L'archive compressée ne dépasse pas le volume copié
+	if err := ensureFreeSpace(filepath.Dir(destPath), total, "le snapshot de "+bucket); err != nil {
+		return err
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("erreur création de l'archive: %v", err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("erreur écriture de l'archive: %v", closeErr)
+		}
+		if err != nil {
+			os.Remove(destPath)
+		}
+	}()
+	cw, err := newCompressWriter(out, c)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(cw)
+
+	progress := NewProgress(ctx, PhaseDownload, bucket, total)
+	for _, obj := range objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		resp, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(obj.key)})
+		if err != nil {
+			return fmt.Errorf("erreur lecture de %s: %v", obj.key, err)
+		}
+		// L'objet a pu être réécrit depuis le listing : l'entrée suit la version effectivement lue
+		size, mod := obj.size, obj.mod
+		if resp.ContentLength != nil {
+			size = *resp.ContentLength
+		}
+		if resp.LastModified != nil {
+			mod = *resp.LastModified
+		}
+		header := &tar.Header{
+			Name:     bucket + "/" + obj.key,
+			Mode:     0o644,
+			Size:     size,
+			ModTime:  mod,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("erreur écriture de l'archive: %v", err)
+		}
+		_, err = io.Copy(tw, progress.Reader(resp.Body))
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("erreur copie de %s: %v", obj.key, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("erreur écriture de l'archive: %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("erreur écriture de l'archive: %v", err)
+	}
+	progress.Finish()
+
+	Log.Success(fmt.Sprintf("Snapshot du bucket %s créé", bucket))
+	return nil
+}
+
+// SnapshotS3BucketWithCreds crée le snapshot d'un bucket dans destPath (relatif au dossier
+// Downloads) et retourne le chemin de l'archive. La compression suit l'extension de destPath
+// (.tar.gz par défaut).
+func SnapshotS3BucketWithCreds(ctx context.Context, creds S3Credentials, bucket, prefix, destPath string) (string, error) {
+	if bucket == "" {
+		bucket = creds.Bucket
+	}
+	if destPath == "" {
+		destPath = fmt.Sprintf("%s-%s%s", sanitizeKeyPart(bucket), time.Now().Format(backupTimeLayout), CompressionGzip.Extension())
+	}
+	archivePath, err := resolveDownloadPath(destPath)
+	if err != nil {
+		return "", err
+	}
+	c := compressionFromName(archivePath)
+	if c == CompressionNone && !strings.HasSuffix(archivePath, ".tar") {
+		c = CompressionGzip
+	}
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0o755); err != nil {
+		return "", fmt.Errorf("erreur création dossier de destination: %v", err)
+	}
+	return archivePath, SnapshotS3Bucket(ctx, creds, bucket, prefix, archivePath, c)
+}
+
+// BackupS3Bucket crée le snapshot d'un bucket du profil S3 (objets sous prefix si non vide) et
+// l'envoie vers le dépôt de backups sous <prefix>/s3/<profil>/<bucket>/<horodatage>.tar.gz
+func BackupS3Bucket(ctx context.Context, profile *ServerProfile, bucket, prefix string) (*BackupResult, error) {
+	if profile.Engine != EngineS3 {
+		return nil, fmt.Errorf("le serveur '%s' n'est pas un serveur S3 (%s)", profile.Name, profile.Engine)
+	}
+	if bucket == "" {
+		bucket = profile.Bucket
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("le bucket à copier est requis")
+	}
+	repo, err := openBackupRepository(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	tmpDir, err := getWorkDir()
+	if err != nil {
+		return nil, err
+	}
+
+	key := BackupKey{
+		Prefix:   repo.prefix,
+		Engine:   EngineS3,
+		Server:   profile.Name,
+		Database: bucket,
+		Ext:      CompressionGzip.Extension(),
+		Time:     time.Now(),
+	}
+	archivePath := filepath.Join(tmpDir, fmt.Sprintf("snapshot-%s-%d%s", sanitizeKeyPart(bucket), key.Time.UnixNano(), key.Ext))
+	if err := SnapshotS3Bucket(ctx, profile.S3Credentials(), bucket, prefix, archivePath, CompressionGzip); err != nil {
+		return nil, err
+	}
+	defer os.Remove(archivePath)
+	return repo.storeBackup(ctx, archivePath, key, "")
+}
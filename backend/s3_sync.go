@@ -0,0 +1,521 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// La synchronisation compare deux emplacements (bucket S3 d'un serveur enregistré, éventuellement
+// limité à un préfixe, ou dossier local) et copie vers la destination les objets absents ou
+// différents. Deux objets sont identiques s'ils ont la même taille et, quand il est comparable,
+// le même ETag ; sinon la date de modification départage.
+
+// syncConcurrency est le nombre d'objets copiés en parallèle
+const syncConcurrency = 4
+
+// Actions d'une synchronisation
+const (
+	SyncCopy   = "copy"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// SyncEndpoint est un côté d'une synchronisation : un bucket d'un serveur S3 enregistré, ou un
+// dossier local si LocalPath est renseigné
+type SyncEndpoint struct {
+	Server    string `json:"server"`
+	Bucket    string `json:"bucket"`
+	Prefix    string `json:"prefix"`
+	LocalPath string `json:"localPath"`
+}
+
+// String retourne l'emplacement au format accepté par ParseSyncEndpoint
+func (e SyncEndpoint) String() string {
+	if e.LocalPath != "" {
+		return e.LocalPath
+	}
+	return e.Server + ":" + path.Join(e.Bucket, e.Prefix)
+}
+
+// ParseSyncEndpoint lit un emplacement "<serveur>:<bucket>[/<préfixe>]" ou un chemin local
+func ParseSyncEndpoint(spec string) (SyncEndpoint, error) {
+	server, rest, ok := strings.Cut(spec, ":")
+	// "C:\..." est un chemin Windows, pas un serveur
+	if !ok || len(server) < 2 || strings.ContainsAny(server, `/\`) {
+		if spec == "" {
+			return SyncEndpoint{}, fmt.Errorf("emplacement vide")
+		}
+		return SyncEndpoint{LocalPath: spec}, nil
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	return SyncEndpoint{Server: server, Bucket: bucket, Prefix: prefix}, nil
+}
+
+// SyncOptions règle une synchronisation
+type SyncOptions struct {
+	// Delete supprime de la destination les objets absents de la source
+	Delete bool `json:"delete"`
+	// DryRun calcule les changements sans les appliquer
+	DryRun bool `json:"dryRun"`
+	// Include et Exclude sont des motifs glob (path.Match) appliqués à la clé relative, ou au
+	// nom seul si le motif ne contient pas de "/"
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// matches indique si la clé relative est retenue par les filtres
+func (o SyncOptions) matches(key string) bool {
+	match := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			subject := key
+			if !strings.Contains(pattern, "/") {
+				subject = path.Base(key)
+			}
+			if ok, _ := path.Match(pattern, subject); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(o.Include) > 0 && !match(o.Include) {
+		return false
+	}
+	return !match(o.Exclude)
+}
+
+// SyncChange est une différence entre la source et la destination
+type SyncChange struct {
+	Action string `json:"action"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SyncReport résume une synchronisation (ou ce qu'elle ferait en dry-run)
+type SyncReport struct {
+	Source      string       `json:"source"`
+	Destination string       `json:"destination"`
+	DryRun      bool         `json:"dryRun"`
+	Changes     []SyncChange `json:"changes"`
+	// Skipped liste les objets source ignorés, dont la clé sortirait du dossier de destination
+	Skipped     []SyncChange `json:"skipped,omitempty"`
+	Unchanged   int          `json:"unchanged"`
+	BytesCopied int64        `json:"bytesCopied"`
+}
+
+// syncEntry décrit un objet d'un emplacement
+type syncEntry struct {
+	size  int64
+	etag  string
+	mtime time.Time
+	// path est le fichier d'un emplacement local (ETag calculé à la demande)
+	path string
+}
+
+// syncStore est un emplacement lu ou écrit par la synchronisation. Les clés sont relatives au
+// préfixe ou au dossier de l'emplacement.
+type syncStore interface {
+	list(ctx context.Context) (map[string]syncEntry, error)
+	open(ctx context.Context, key string) (io.ReadCloser, error)
+	put(ctx context.Context, key string, r io.Reader, entry syncEntry) error
+	remove(ctx context.Context, key string) error
+}
+
+// s3SyncStore est un bucket S3, limité à un préfixe
+type s3SyncStore struct {
+	client   *s3.Client
+	uploader *manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func (s *s3SyncStore) list(ctx context.Context) (map[string]syncEntry, error) {
+	entries := make(map[string]syncEntry)
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(s.bucket)}
+	if s.prefix != "" {
+		input.Prefix = aws.String(s.prefix)
+	}
+	paginator := s3.NewListObjectsV2Paginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("erreur listing du bucket %s: %v", s.bucket, err)
+		}
+		for _, obj := range page.Contents {
+			key := strings.TrimPrefix(aws.ToString(obj.Key), s.prefix)
+			if key == "" || strings.HasSuffix(key, "/") {
+				continue
+			}
+			if isArchivedStorageClass(string(obj.StorageClass)) {
+				Log.Warn(fmt.Sprintf("%s est en %s, ignoré par la synchronisation", aws.ToString(obj.Key), obj.StorageClass))
+				continue
+			}
+			entries[key] = syncEntry{
+				size:  derefInt64(obj.Size),
+				etag:  strings.Trim(aws.ToString(obj.ETag), `"`),
+				mtime: aws.ToTime(obj.LastModified),
+			}
+		}
+	}
+	return entries, nil
+}
+
+func (s *s3SyncStore) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(s.prefix + key)})
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture de %s/%s: %v", s.bucket, s.prefix+key, err)
+	}
+	return out.Body, nil
+}
+
+func (s *s3SyncStore) put(ctx context.Context, key string, r io.Reader, entry syncEntry) error {
+	_, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(s.prefix + key),
+		Body:          r,
+		ContentLength: aws.Int64(entry.size),
+	})
+	if err != nil {
+		return fmt.Errorf("erreur upload de %s/%s: %v", s.bucket, s.prefix+key, err)
+	}
+	return nil
+}
+
+func (s *s3SyncStore) remove(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(s.prefix + key)})
+	if err != nil {
+		return fmt.Errorf("erreur suppression de %s/%s: %v", s.bucket, s.prefix+key, err)
+	}
+	return nil
+}
+
+// localSyncStore est un dossier local
+type localSyncStore struct {
+	root string
+}
+
+func (s *localSyncStore) list(ctx context.Context) (map[string]syncEntry, error) {
+	entries := make(map[string]syncEntry)
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Un dossier de destination absent est une destination vide
+			if p == s.root && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || strings.HasSuffix(p, syncPartSuffix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		entries[filepath.ToSlash(rel)] = syncEntry{size: info.Size(), mtime: info.ModTime(), path: p}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture du dossier %s: %v", s.root, err)
+	}
+	return entries, nil
+}
+
+// path retourne le fichier de key, qui ne peut pas sortir du dossier (une clé S3 peut contenir "..")
+func (s *localSyncStore) path(key string) (string, error) {
+	return safeArchivePath(s.root, key)
+}
+
+func (s *localSyncStore) open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("erreur ouverture fichier: %v", err)
+	}
+	return f, nil
+}
+
+// syncPartSuffix marque un fichier local en cours d'écriture
+const syncPartSuffix = ".sync-part"
+
+func (s *localSyncStore) put(ctx context.Context, key string, r io.Reader, entry syncEntry) error {
+	dest, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("erreur création dossier: %v", err)
+	}
+	tmp := dest + syncPartSuffix
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("erreur création fichier: %v", err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erreur écriture de %s: %v", dest, err)
+	}
+	// La date de la source est conservée pour les comparaisons suivantes
+	if !entry.mtime.IsZero() {
+		os.Chtimes(tmp, entry.mtime, entry.mtime)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erreur écriture de %s: %v", dest, err)
+	}
+	return nil
+}
+
+func (s *localSyncStore) remove(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erreur suppression de %s: %v", key, err)
+	}
+	return nil
+}
+
+// openSyncStore prépare un emplacement
+func openSyncStore(ctx context.Context, e SyncEndpoint) (syncStore, error) {
+	if e.LocalPath != "" {
+		root, err := filepath.Abs(e.LocalPath)
+		if err != nil {
+			return nil, fmt.Errorf("chemin invalide %s: %v", e.LocalPath, err)
+		}
+		return &localSyncStore{root: root}, nil
+	}
+	profile, err := GetServerProfile(e.Server)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Engine != EngineS3 {
+		return nil, fmt.Errorf("le serveur '%s' n'est pas un serveur S3 (%s)", profile.Name, profile.Engine)
+	}
+	creds := profile.S3Credentials()
+	if e.Bucket != "" {
+		creds.Bucket = e.Bucket
+	}
+	if creds.Bucket == "" {
+		return nil, fmt.Errorf("le bucket de %s est requis (<serveur>:<bucket>)", e.Server)
+	}
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.Trim(e.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	uploader := manager.NewUploader(client, func(u *manager.Uploader) {
+		u.PartSize = s3UploadPartSize
+	})
+	return &s3SyncStore{client: client, uploader: uploader, bucket: bucket, prefix: prefix}, nil
+}
+
+// skipUnsafeSyncKeys retire de entries les clés qui sortiraient du dossier local dst (par exemple
+// un objet S3 nommé "../x") et les retourne
+func skipUnsafeSyncKeys(entries map[string]syncEntry, dst syncStore) []SyncChange {
+	local, ok := dst.(*localSyncStore)
+	if !ok {
+		return nil
+	}
+	var skipped []SyncChange
+	for key, entry := range entries {
+		if _, err := local.path(key); err != nil {
+			Log.Warn(fmt.Sprintf("Objet ignoré: %v", err))
+			skipped = append(skipped, SyncChange{Action: SyncCopy, Key: key, Size: entry.size, Reason: "chemin hors de la destination"})
+			delete(entries, key)
+		}
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Key < skipped[j].Key })
+	return skipped
+}
+
+// compareSyncEntries indique pourquoi dst doit être remplacé par src ("" s'ils sont identiques)
+func compareSyncEntries(src, dst syncEntry) string {
+	if src.size != dst.size {
+		return "taille différente"
+	}
+	srcETag, dstETag := src.etag, dst.etag
+	// L'ETag d'un fichier local est calculé seulement si l'autre côté en a un
+	if srcETag == "" && dstETag != "" && src.path != "" {
+		srcETag, _ = localETag(src.path, src.size, strings.Contains(dstETag, "-"))
+	}
+	if dstETag == "" && srcETag != "" && dst.path != "" {
+		dstETag, _ = localETag(dst.path, dst.size, strings.Contains(srcETag, "-"))
+	}
+	// Les ETag ne sont comparables que pour le même type d'upload (simple ou multipart)
+	if srcETag != "" && dstETag != "" && strings.Contains(srcETag, "-") == strings.Contains(dstETag, "-") {
+		if !strings.EqualFold(srcETag, dstETag) {
+			return "contenu différent"
+		}
+		return ""
+	}
+	if src.mtime.Truncate(time.Second).After(dst.mtime.Truncate(time.Second)) {
+		return "source plus récente"
+	}
+	return ""
+}
+
+// planSync calcule les changements à appliquer à la destination
+func planSync(srcEntries, dstEntries map[string]syncEntry, opts SyncOptions) ([]SyncChange, int) {
+	var changes []SyncChange
+	unchanged := 0
+	for key, src := range srcEntries {
+		if !opts.matches(key) {
+			continue
+		}
+		dst, ok := dstEntries[key]
+		switch {
+		case !ok:
+			changes = append(changes, SyncChange{Action: SyncCopy, Key: key, Size: src.size})
+		default:
+			if reason := compareSyncEntries(src, dst); reason != "" {
+				changes = append(changes, SyncChange{Action: SyncUpdate, Key: key, Size: src.size, Reason: reason})
+			} else {
+				unchanged++
+			}
+		}
+	}
+	if opts.Delete {
+		for key, dst := range dstEntries {
+			if _, ok := srcEntries[key]; !ok && opts.matches(key) {
+				changes = append(changes, SyncChange{Action: SyncDelete, Key: key, Size: dst.size})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, unchanged
+}
+
+// SyncS3 synchronise dst sur src : copie des objets absents ou différents et, avec opts.Delete,
+// suppression des objets en trop. En dry-run, le rapport liste les changements sans les appliquer.
+func SyncS3(ctx context.Context, src, dst SyncEndpoint, opts SyncOptions) (*SyncReport, error) {
+	if src.LocalPath != "" {
+		if _, err := os.Stat(src.LocalPath); err != nil {
+			return nil, fmt.Errorf("dossier source introuvable: %v", err)
+		}
+	}
+	srcStore, err := openSyncStore(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	dstStore, err := openSyncStore(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+	srcEntries, err := srcStore.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dstEntries, err := dstStore.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := skipUnsafeSyncKeys(srcEntries, dstStore)
+	changes, unchanged := planSync(srcEntries, dstEntries, opts)
+	report := &SyncReport{Source: src.String(), Destination: dst.String(), DryRun: opts.DryRun, Changes: changes, Skipped: skipped, Unchanged: unchanged}
+	Log.Info(fmt.Sprintf("Synchronisation %s -> %s: %d changement(s), %d objet(s) identique(s)", report.Source, report.Destination, len(changes), unchanged))
+	if opts.DryRun || len(changes) == 0 {
+		return report, nil
+	}
+
+	var total int64
+	for _, change := range changes {
+		if change.Action != SyncDelete {
+			total += change.Size
+		}
+	}
+	progress := NewProgress(ctx, PhaseUpload, report.Destination, total)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		copied   int64
+	)
+	sem := make(chan struct{}, syncConcurrency)
+	for _, change := range changes {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(change SyncChange) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := applySyncChange(ctx, srcStore, dstStore, change, srcEntries[change.Key], progress)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			if change.Action != SyncDelete {
+				atomic.AddInt64(&copied, change.Size)
+			}
+		}(change)
+	}
+	wg.Wait()
+	report.BytesCopied = copied
+	if firstErr != nil {
+		return report, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	progress.Finish()
+	Log.Success(fmt.Sprintf("Synchronisation terminée: %s copié(s)", FormatBytes(copied)))
+	return report, nil
+}
+
+// applySyncChange copie ou supprime un objet de la destination
+func applySyncChange(ctx context.Context, src, dst syncStore, change SyncChange, entry syncEntry, progress *Progress) error {
+	if change.Action == SyncDelete {
+		Log.Debug(fmt.Sprintf("Suppression de %s", change.Key))
+		return dst.remove(ctx, change.Key)
+	}
+	Log.Debug(fmt.Sprintf("Copie de %s (%s)", change.Key, FormatBytes(change.Size)))
+	body, err := src.open(ctx, change.Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return dst.put(ctx, change.Key, progress.Reader(body), entry)
+}
@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalSyncStoreRejectsKeysOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	store := &localSyncStore{root: filepath.Join(parent, "dest")}
+	ctx := context.Background()
+
+	for _, key := range []string{"../evil", "a/../../evil", "/etc/evil"} {
+		err := store.put(ctx, key, strings.NewReader("x"), syncEntry{size: 1})
+		if !errors.Is(err, ErrUnsafeArchivePath) {
+			t.Errorf("put(%q): %v, attendu ErrUnsafeArchivePath", key, err)
+		}
+		if err := store.remove(ctx, key); !errors.Is(err, ErrUnsafeArchivePath) {
+			t.Errorf("remove(%q): %v, attendu ErrUnsafeArchivePath", key, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(parent, "evil")); err == nil {
+		t.Error("fichier écrit hors du dossier de destination")
+	}
+
+	if err := store.put(ctx, "dir/ok.txt", strings.NewReader("ok"), syncEntry{size: 2}); err != nil {
+		t.Fatalf("put(dir/ok.txt): %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(store.root, "dir", "ok.txt")); err != nil || string(data) != "ok" {
+		t.Errorf("dir/ok.txt = %q, %v", data, err)
+	}
+}
+
+func TestSkipUnsafeSyncKeys(t *testing.T) {
+	entries := map[string]syncEntry{
+		"ok.txt":         {size: 1},
+		"../../.bashrc":  {size: 2},
+		"a/../../escape": {size: 3},
+	}
+	skipped := skipUnsafeSyncKeys(entries, &localSyncStore{root: t.TempDir()})
+	if len(skipped) != 2 || skipped[0].Key != "../../.bashrc" || skipped[1].Key != "a/../../escape" {
+		t.Fatalf("skipped = %+v", skipped)
+	}
+	if _, ok := entries["ok.txt"]; !ok || len(entries) != 1 {
+		t.Errorf("entries = %v, attendu seulement ok.txt", entries)
+	}
+
+	// Vers S3, les clés ne désignent pas de fichiers : rien n'est ignoré
+	entries = map[string]syncEntry{"../x": {}}
+	if skipped := skipUnsafeSyncKeys(entries, &s3SyncStore{}); skipped != nil || len(entries) != 1 {
+		t.Errorf("skipped = %+v vers S3", skipped)
+	}
+}
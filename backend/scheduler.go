@@ -0,0 +1,225 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Types de sauvegardes planifiées (en plus des moteurs EngineMongo, EngineMySQL, EnginePostgres et EngineS3)
+var scheduleTypes = []string{EngineMongo, EngineMySQL, EnginePostgres, BackupKindProject, EngineS3}
+
+// cronParser accepte les expressions standard à 5 champs et les descripteurs (@daily, @every 1h)
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidateSchedule vérifie qu'une sauvegarde planifiée est complète
+func ValidateSchedule(sc ScheduleConfig) error {
+	if sc.Name == "" {
+		return fmt.Errorf("une sauvegarde planifiée n'a pas de nom")
+	}
+	if _, err := cronParser.Parse(sc.Cron); err != nil {
+		return fmt.Errorf("sauvegarde '%s': expression cron invalide '%s': %v", sc.Name, sc.Cron, err)
+	}
+	switch sc.Type {
+	case EngineMongo, EngineMySQL, EnginePostgres:
+		if sc.Server == "" || sc.Database == "" {
+			return fmt.Errorf("sauvegarde '%s': server et database sont requis", sc.Name)
+		}
+	case EngineS3:
+		if sc.Server == "" {
+			return fmt.Errorf("sauvegarde '%s': server est requis", sc.Name)
+		}
+	case BackupKindProject:
+	default:
+		return fmt.Errorf("sauvegarde '%s': type inconnu '%s' (%s)", sc.Name, sc.Type, strings.Join(scheduleTypes, ", "))
+	}
+	if sc.Retention.Daily < 0 || sc.Retention.Weekly < 0 || sc.Retention.Monthly < 0 {
+		return fmt.Errorf("sauvegarde '%s': la rétention ne peut pas être négative", sc.Name)
+	}
+	return nil
+}
+
+// NextScheduleRun retourne la prochaine exécution de sc après from
+func NextScheduleRun(sc ScheduleConfig, from time.Time) (time.Time, error) {
+	schedule, err := cronParser.Parse(sc.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(from), nil
+}
+
+// FindSchedule retrouve une sauvegarde planifiée de la configuration active par son nom
+func FindSchedule(name string) (ScheduleConfig, error) {
+	for _, sc := range CurrentConfig().Schedules {
+		if sc.Name == name {
+			return sc, nil
+		}
+	}
+	return ScheduleConfig{}, fmt.Errorf("sauvegarde planifiée introuvable: %s", name)
+}
+
+// Scheduler exécute les sauvegardes planifiées selon leurs expressions cron
+type Scheduler struct {
+	schedules []ScheduleConfig
+}
+
+// NewScheduler valide les sauvegardes planifiées (noms uniques, cron et champs requis)
+func NewScheduler(schedules []ScheduleConfig) (*Scheduler, error) {
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("aucune sauvegarde planifiée (section schedules de la configuration)")
+	}
+	names := map[string]bool{}
+	for _, sc := range schedules {
+		if err := ValidateSchedule(sc); err != nil {
+			return nil, err
+		}
+		if names[sc.Name] {
+			return nil, fmt.Errorf("sauvegarde planifiée en double: %s", sc.Name)
+		}
+		names[sc.Name] = true
+	}
+	return &Scheduler{schedules: schedules}, nil
+}
+
+// Run exécute les sauvegardes jusqu'à l'annulation de ctx. Les sauvegardes en cours sont alors
+// annulées et Run attend leur arrêt. Une sauvegarde encore en cours à l'échéance suivante
+// n'est pas relancée.
+func (s *Scheduler) Run(ctx context.Context) error {
+	c := cron.New(cron.WithParser(cronParser), cron.WithChain(cron.SkipIfStillRunning(cronLogger{})))
+	for _, sc := range s.schedules {
+		sc := sc
+		if _, err := c.AddFunc(sc.Cron, func() {
+			// L'erreur est déjà journalisée par le gestionnaire de jobs
+			RunScheduledBackup(ctx, sc)
+		}); err != nil {
+			return fmt.Errorf("sauvegarde '%s': %v", sc.Name, err)
+		}
+		next, _ := NextScheduleRun(sc, time.Now())
+		Log.Info(fmt.Sprintf("Sauvegarde planifiée %s (%s), prochaine exécution %s", sc.Name, sc.Cron, next.Format(time.RFC3339)))
+	}
+
+	c.Start()
+	<-ctx.Done()
+	Log.Info("Arrêt du planificateur, annulation des sauvegardes en cours...")
+	<-c.Stop().Done()
+	return nil
+}
+
+// RunScheduledBackup exécute une sauvegarde : export local, upload vers le dépôt de backups
+// puis application de la rétention. L'exécution est suivie comme un job.
+func RunScheduledBackup(ctx context.Context, sc ScheduleConfig) error {
+	return Jobs.Run(ctx, "schedule", fmt.Sprintf("Sauvegarde planifiée %s", sc.Name), func(ctx context.Context) error {
+		return runScheduledBackup(ctx, sc)
+	})
+}
+
+func runScheduledBackup(ctx context.Context, sc ScheduleConfig) error {
+	if err := ValidateSchedule(sc); err != nil {
+		return err
+	}
+	repo, err := openBackupRepository(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	var result *BackupResult
+	switch sc.Type {
+	case EngineMongo, EngineMySQL, EnginePostgres:
+		profile, err := scheduleProfile(sc)
+		if err != nil {
+			return err
+		}
+		if result, err = BackupDatabase(ctx, profile, sc.Database); err != nil {
+			return err
+		}
+	case EngineS3:
+		profile, err := scheduleProfile(sc)
+		if err != nil {
+			return err
+		}
+		if result, err = BackupS3Bucket(ctx, profile, sc.Bucket, ""); err != nil {
+			return err
+		}
+	default:
+		key := BackupKey{Prefix: repo.prefix, Engine: sc.Type, Time: time.Now()}
+		localPath, err := createScheduledArchive(ctx, sc, &key)
+		if err != nil {
+			return err
+		}
+		result, err = repo.storeBackup(ctx, localPath, key, "")
+		os.Remove(localPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	deleted, err := repo.applyRetention(ctx, path.Dir(result.Key), sc.Retention)
+	if err != nil {
+		return fmt.Errorf("sauvegarde envoyée mais rétention en échec: %w", err)
+	}
+	if deleted > 0 {
+		Log.Info(fmt.Sprintf("Rétention %s: %d sauvegarde(s) supprimée(s)", sc.Name, deleted))
+	}
+	return nil
+}
+
+// scheduleProfile retourne le profil source d'une sauvegarde, en vérifiant son moteur
+func scheduleProfile(sc ScheduleConfig) (*ServerProfile, error) {
+	profile, err := GetServerProfile(sc.Server)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Engine != sc.Type {
+		return nil, fmt.Errorf("sauvegarde '%s': le serveur '%s' est de type %s (attendu: %s)", sc.Name, sc.Server, profile.Engine, sc.Type)
+	}
+	return profile, nil
+}
+
+// createScheduledArchive produit l'archive locale d'une sauvegarde project et complète
+// key (source, extension). Le fichier retourné doit être supprimé par l'appelant.
+func createScheduledArchive(ctx context.Context, sc ScheduleConfig, key *BackupKey) (string, error) {
+	tmpDir, err := getWorkDir()
+	if err != nil {
+		return "", err
+	}
+	compression, err := ParseCompression(CurrentConfig().Backup.Compression)
+	if err != nil {
+		return "", err
+	}
+	projectDir := sc.Path
+	if projectDir == "" {
+		projectDir = "."
+	}
+	if projectDir, err = filepath.Abs(projectDir); err != nil {
+		return "", fmt.Errorf("chemin de projet invalide: %v", err)
+	}
+	key.Server = filepath.Base(projectDir)
+	key.Ext = compression.Extension()
+	archivePath := filepath.Join(tmpDir, fmt.Sprintf("schedule-%s-%d%s", sanitizeKeyPart(sc.Name), time.Now().UnixNano(), key.Ext))
+	return archivePath, CreateTarArchive(ctx, projectDir, archivePath, compression)
+}
+
+// cronLogger relaie les messages de robfig/cron vers le logger du backend
+type cronLogger struct{}
+
+func (cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	Log.Debug("cron: "+msg, cronFields(keysAndValues)...)
+}
+
+func (cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	Log.Error("cron: "+msg, append(cronFields(keysAndValues), F("error", err))...)
+}
+
+func cronFields(keysAndValues []interface{}) []Field {
+	var fields []Field
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields = append(fields, F(fmt.Sprint(keysAndValues[i]), keysAndValues[i+1]))
+	}
+	return fields
+}
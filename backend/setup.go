@@ -2,6 +2,7 @@ package backend
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,59 +11,59 @@ import (
 )
 
 // SubmoduleAction effectue le checkout des submodules dans le chemin donné
-func SubmoduleAction(path string, branches ...string) error {
+func SubmoduleAction(ctx context.Context, path string, branches ...string) error {
 	initialDir, err := os.Getwd()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur récupération répertoire courant: %v", err))
+		Log.Error(fmt.Sprintf("Erreur récupération répertoire courant: %v", err))
 		return fmt.Errorf("erreur lors de la récupération du répertoire courant: %v", err)
 	}
 	if path != "" && path != "." {
 		if err := os.Chdir(path); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur changement de répertoire vers %s: %v", path, err))
+			Log.Error(fmt.Sprintf("Erreur changement de répertoire vers %s: %v", path, err))
 			return fmt.Errorf("erreur lors du changement de répertoire vers %s: %v", path, err)
 		}
 	}
 	defer os.Chdir(initialDir)
 
-	LogToFrontend("info", fmt.Sprintf("On est dans le répertoire %s", path))
+	Log.Info(fmt.Sprintf("On est dans le répertoire %s", path))
 
-	LogToFrontend("info", "On initialise et update les submodules")
-	if err := execCommand("git", "submodule", "init"); err != nil {
-		LogToFrontend("error", "Erreur git submodule init")
+	Log.Info("On initialise et update les submodules")
+	if err := execCommandContext(ctx, "git", "submodule", "init"); err != nil {
+		Log.Error("Erreur git submodule init")
 		return err
 	}
-	if err := execCommand("git", "submodule", "update"); err != nil {
-		LogToFrontend("error", "Erreur git submodule update")
+	if err := execCommandContext(ctx, "git", "submodule", "update"); err != nil {
+		Log.Error("Erreur git submodule update")
 		return err
 	}
 
 	defaultBranch, err := GetDefaultBranch()
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur récupération branche par défaut: %v", err))
+		Log.Error(fmt.Sprintf("Erreur récupération branche par défaut: %v", err))
 		return fmt.Errorf("erreur lors de la récupération de la branche par défaut : %v", err)
 	}
 	branches = append(branches, defaultBranch)
 
-	LogToFrontend("info", fmt.Sprintf("Branches à essayer : %v", branches))
+	Log.Info(fmt.Sprintf("Branches à essayer : %v", branches))
 
 	for _, branch := range branches {
-		LogToFrontend("info", fmt.Sprintf("Tentative de checkout de la branche '%s'", branch))
-		if err := execCommand("git", "checkout", branch); err == nil {
-			LogToFrontend("success", fmt.Sprintf("Branche '%s' checkoutée avec succès", branch))
+		Log.Info(fmt.Sprintf("Tentative de checkout de la branche '%s'", branch))
+		if err := execCommandContext(ctx, "git", "checkout", branch); err == nil {
+			Log.Success(fmt.Sprintf("Branche '%s' checkoutée avec succès", branch))
 			break
 		}
-		LogToFrontend("warn", fmt.Sprintf("Impossible de checkout '%s'", branch))
+		Log.Warn(fmt.Sprintf("Impossible de checkout '%s'", branch))
 	}
 
-	LogToFrontend("info", "On pull")
-	if err := execCommand("git", "pull"); err != nil {
-		LogToFrontend("error", "Erreur git pull")
+	Log.Info("On pull")
+	if err := execCommandContext(ctx, "git", "pull"); err != nil {
+		Log.Error("Erreur git pull")
 		return err
 	}
 
 	content, err := os.ReadFile(".gitmodules")
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur lecture .gitmodules: %v", err))
+		Log.Error(fmt.Sprintf("Erreur lecture .gitmodules: %v", err))
 		return fmt.Errorf("erreur lors de la lecture de .gitmodules: %v", err)
 	}
 
@@ -79,42 +80,51 @@ func SubmoduleAction(path string, branches ...string) error {
 		}
 	}
 
-	LogToFrontend("info", fmt.Sprintf("Submodules trouvés : %v", submodules))
+	Log.Info(fmt.Sprintf("Submodules trouvés : %v", submodules))
 
 	for _, submodule := range submodules {
-		LogToFrontend("info", fmt.Sprintf("On entre dans le submodule: %s", submodule))
+		if CurrentConfig().IsSubmoduleExcluded(submodule) {
+			Log.Info(fmt.Sprintf("Submodule %s exclu par la configuration, ignoré", submodule))
+			continue
+		}
+		Log.Info(fmt.Sprintf("On entre dans le submodule: %s", submodule))
 		absSubmodulePath := filepath.Join(path, submodule)
-		LogToFrontend("info", fmt.Sprintf("On va dans le répertoire %s", absSubmodulePath))
+		Log.Info(fmt.Sprintf("On va dans le répertoire %s", absSubmodulePath))
 
 		if err := os.Chdir(absSubmodulePath); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur changement de répertoire: chdir %s: %v", absSubmodulePath, err))
+			Log.Error(fmt.Sprintf("Erreur changement de répertoire: chdir %s: %v", absSubmodulePath, err))
 			return fmt.Errorf("erreur lors du changement de répertoire: chdir %s: %v", absSubmodulePath, err)
 		}
 
+		if err := ctx.Err(); err != nil {
+			os.Chdir(initialDir)
+			return err
+		}
+
 		for _, branch := range branches {
-			LogToFrontend("info", fmt.Sprintf("Tentative de checkout de la branche '%s' pour le submodule", branch))
-			if err := execCommand("git", "checkout", branch); err == nil {
-				LogToFrontend("success", fmt.Sprintf("Submodule sur branche '%s' checkouté avec succès", branch))
+			Log.Info(fmt.Sprintf("Tentative de checkout de la branche '%s' pour le submodule", branch))
+			if err := execCommandContext(ctx, "git", "checkout", branch); err == nil {
+				Log.Success(fmt.Sprintf("Submodule sur branche '%s' checkouté avec succès", branch))
 				break
 			}
-			LogToFrontend("warn", fmt.Sprintf("Submodule : Impossible de checkout '%s'", branch))
+			Log.Warn(fmt.Sprintf("Submodule : Impossible de checkout '%s'", branch))
 		}
 
-		LogToFrontend("info", "On pull (submodule)")
-		if err := execCommand("git", "pull"); err != nil {
-			LogToFrontend("error", "Erreur git pull (submodule)")
+		Log.Info("On pull (submodule)")
+		if err := execCommandContext(ctx, "git", "pull"); err != nil {
+			Log.Error("Erreur git pull (submodule)")
 			return err
 		}
 
 		if _, err := os.Stat(".gitmodules"); err == nil {
-			LogToFrontend("info", "Submodule contient un .gitmodules, récursivité !")
-			if err := SubmoduleAction(absSubmodulePath, branches...); err != nil {
+			Log.Info("Submodule contient un .gitmodules, récursivité !")
+			if err := SubmoduleAction(ctx, absSubmodulePath, branches...); err != nil {
 				return err
 			}
 		}
 
 		if err := os.Chdir(initialDir); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur retour répertoire parent: %v", err))
+			Log.Error(fmt.Sprintf("Erreur retour répertoire parent: %v", err))
 			return fmt.Errorf("erreur lors du retour au répertoire parent: %v", err)
 		}
 	}
@@ -123,52 +133,70 @@ func SubmoduleAction(path string, branches ...string) error {
 }
 
 // NpmAction lance npm install récursif à partir du path donné si all == true
-func NpmAction(path string, all bool) error {
+func NpmAction(ctx context.Context, path string, all bool) error {
 	if !all {
 		return nil
 	}
-	return npmInstallRecursive(path)
+	return npmInstallRecursive(ctx, path)
 }
 
-func npmInstallRecursive(path string) error {
+func npmInstallRecursive(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	entries, err := os.ReadDir(path)
 	if err != nil {
-		LogToFrontend("warn", fmt.Sprintf("Impossible de lire le répertoire %s (permissions?): %v - on continue", path, err))
+		Log.Warn(fmt.Sprintf("Impossible de lire le répertoire %s (permissions?): %v - on continue", path, err))
 		return nil // On continue même si on ne peut pas lire le répertoire
 	}
 
 	// Si package.json existe dans ce dossier, on fait npm install
 	packageJsonPath := filepath.Join(path, "package.json")
 	if _, err := os.Stat(packageJsonPath); err == nil {
-		LogToFrontend("info", fmt.Sprintf("%s : package.json existe, lancement de 'npm install'...", path))
-		cmd := exec.Command("npm", "install", "--no-save")
+		npmArgs := CurrentConfig().Npm.InstallArgs
+		Log.Info(fmt.Sprintf("%s : package.json existe, lancement de 'npm %s'...", path, strings.Join(npmArgs, " ")))
+		cmd := exec.CommandContext(ctx, "npm", npmArgs...)
 		cmd.Dir = path
 		stdoutStderr, err := cmd.CombinedOutput()
-		LogToFrontend("info", string(stdoutStderr))
+		Log.Info(string(stdoutStderr))
 		if err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur npm install dans %s: %v", path, err))
+			Log.Error(fmt.Sprintf("Erreur npm install dans %s: %v", path, err))
 			return err
 		}
-		LogToFrontend("success", fmt.Sprintf("npm install terminé avec succès dans %s.", path))
+		Log.Success(fmt.Sprintf("npm install terminé avec succès dans %s.", path))
 	}
 
 	// Parcours récursif des sous-dossiers
 	for _, entry := range entries {
-		if entry.IsDir() && entry.Name() != "node_modules" && entry.Name() != ".git" {
+		if entry.IsDir() && !isSkippedNpmDir(entry.Name()) {
 			subPath := filepath.Join(path, entry.Name())
-			if err := npmInstallRecursive(subPath); err != nil {
+			if err := npmInstallRecursive(ctx, subPath); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
 				// On log l'erreur mais on continue avec les autres dossiers
-				LogToFrontend("warn", fmt.Sprintf("Erreur dans le sous-dossier %s: %v - on continue", subPath, err))
+				Log.Warn(fmt.Sprintf("Erreur dans le sous-dossier %s: %v - on continue", subPath, err))
 			}
 		}
 	}
 	return nil
 }
 
+// isSkippedNpmDir indique si un dossier doit être ignoré lors du parcours npm
+func isSkippedNpmDir(name string) bool {
+	for _, skipped := range CurrentConfig().Npm.SkipDirs {
+		if name == skipped {
+			return true
+		}
+	}
+	return false
+}
+
 func TagAction(version, message string) error {
 	entries, err := os.ReadDir(".")
 	if err != nil {
-		LogToFrontend("error", fmt.Sprintf("Erreur lecture répertoire: %v", err))
+		Log.Error(fmt.Sprintf("Erreur lecture répertoire: %v", err))
 		return fmt.Errorf("erreur lors de la lecture du répertoire: %v", err)
 	}
 
@@ -177,26 +205,26 @@ func TagAction(version, message string) error {
 			continue
 		}
 
-		LogToFrontend("info", fmt.Sprintf("TagAction: %s", entry.Name()))
+		Log.Info(fmt.Sprintf("TagAction: %s", entry.Name()))
 		if err := os.Chdir(entry.Name()); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur changement de répertoire: %v", err))
+			Log.Error(fmt.Sprintf("Erreur changement de répertoire: %v", err))
 			return fmt.Errorf("erreur lors du changement de répertoire: %v", err)
 		}
 
 		if _, err := os.Stat("package.json"); err == nil {
-			LogToFrontend("info", "package.json existe, on tag")
+			Log.Info("package.json existe, on tag")
 			if err := execCommand("git", "tag", "-a", version, "-m", message); err != nil {
-				LogToFrontend("error", "Erreur git tag")
+				Log.Error("Erreur git tag")
 				return err
 			}
 			if err := execCommand("git", "push", "--tags"); err != nil {
-				LogToFrontend("error", "Erreur git push --tags")
+				Log.Error("Erreur git push --tags")
 				return err
 			}
 		}
 
 		if err := os.Chdir(".."); err != nil {
-			LogToFrontend("error", fmt.Sprintf("Erreur retour répertoire parent: %v", err))
+			Log.Error(fmt.Sprintf("Erreur retour répertoire parent: %v", err))
 			return fmt.Errorf("erreur lors du retour au répertoire parent: %v", err)
 		}
 	}
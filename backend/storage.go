@@ -0,0 +1,334 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// BackupStorage est l'emplacement où sont rangées les sauvegardes : un bucket S3 ou un dossier
+// local (NAS monté, disque hors ligne). Les clés sont des chemins relatifs séparés par "/" ;
+// le catalogue, la vérification, la rétention et les restaurations ne dépendent que de cette
+// interface.
+type BackupStorage interface {
+	// String décrit l'emplacement (s3://bucket ou chemin du dossier)
+	String() string
+	// List retourne les objets dont la clé commence par prefix, sous-dossiers compris, triés par clé
+	List(ctx context.Context, prefix string) ([]StorageObject, error)
+	// Stat décrit un objet ; les métadonnées ne sont conservées que sur S3
+	Stat(ctx context.Context, key string) (*StorageObject, error)
+	// Open ouvre un objet en lecture et retourne sa taille
+	Open(ctx context.Context, key string) (io.ReadCloser, int64, error)
+	// Download copie un objet dans le fichier destPath
+	Download(ctx context.Context, key, destPath string) error
+	// Put enregistre size octets lus sur body sous key
+	Put(ctx context.Context, key string, body io.Reader, size int64, metadata map[string]string) error
+	// Delete supprime un objet (sans erreur s'il n'existe pas)
+	Delete(ctx context.Context, key string) error
+}
+
+// StorageObject décrit un objet d'un BackupStorage
+type StorageObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	StorageClass string
+	Metadata     map[string]string
+}
+
+// ErrStorageObjectNotFound est retournée quand une clé n'existe pas dans le stockage
+var ErrStorageObjectNotFound = errors.New("objet introuvable")
+
+// smallObjectSize est la taille sous laquelle un objet S3 est envoyé en une requête, sans
+// suivi de progression (manifestes, rapports)
+const smallObjectSize = 5 * 1024 * 1024
+
+// s3Storage range les sauvegardes dans un bucket S3
+type s3Storage struct {
+	client *s3.Client
+	bucket string
+}
+
+// newS3Storage ouvre le bucket de creds
+func newS3Storage(ctx context.Context, creds S3Credentials) (*s3Storage, error) {
+	client, bucket, err := newS3Client(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Storage{client: client, bucket: bucket}, nil
+}
+
+func (s *s3Storage) String() string { return "s3://" + s.bucket }
+
+func (s *s3Storage) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	var objects []StorageObject
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("erreur listing S3: %v", err)
+		}
+		for _, obj := range page.Contents {
+			objects = append(objects, StorageObject{
+				Key:          aws.ToString(obj.Key),
+				Size:         derefInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+				StorageClass: string(obj.StorageClass),
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (s *s3Storage) Stat(ctx context.Context, key string) (*StorageObject, error) {
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s/%s: %v", ErrStorageObjectNotFound, s.bucket, key, err)
+	}
+	return &StorageObject{
+		Key:          key,
+		Size:         derefInt64(head.ContentLength),
+		LastModified: aws.ToTime(head.LastModified),
+		StorageClass: string(head.StorageClass),
+		Metadata:     head.Metadata,
+	}, nil
+}
+
+func (s *s3Storage) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	return openRangedStream(ctx, s.client, s.bucket, key)
+}
+
+func (s *s3Storage) Download(ctx context.Context, key, destPath string) error {
+	return downloadS3Object(ctx, s.client, s.bucket, key, destPath)
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, metadata map[string]string) error {
+	if size > smallObjectSize {
+		return uploadToS3(ctx, s.client, s.bucket, key, body, size, metadata)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("erreur lecture de %s: %v", key, err)
+	}
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		Body:     bytes.NewReader(data),
+		Metadata: metadata,
+	}
+	if strings.HasSuffix(key, ".json") {
+		input.ContentType = aws.String("application/json")
+	}
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("erreur upload S3 de %s: %v", key, err)
+	}
+	return nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)}); err != nil {
+		return fmt.Errorf("erreur suppression de %s: %v", key, err)
+	}
+	return nil
+}
+
+// localStorageTmpSuffix marque un fichier en cours d'écriture, ignoré par List
+const localStorageTmpSuffix = ".part"
+
+// localStorage range les sauvegardes dans un dossier local. Les métadonnées ne sont pas
+// conservées : les manifestes écrits à côté de chaque sauvegarde en tiennent lieu.
+type localStorage struct {
+	root string
+}
+
+// newLocalStorage ouvre le dossier root, qui doit exister (un disque non monté ne doit pas être
+// confondu avec un dépôt vide)
+func newLocalStorage(root string) (*localStorage, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("chemin du dépôt local invalide: %v", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("dépôt local inaccessible: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("le dépôt local %s n'est pas un dossier", abs)
+	}
+	return &localStorage{root: abs}, nil
+}
+
+func (s *localStorage) String() string { return s.root }
+
+// path retourne le fichier de key, qui ne peut pas sortir du dossier du dépôt
+func (s *localStorage) path(key string) (string, error) {
+	return safeArchivePath(s.root, strings.TrimPrefix(key, "/"))
+}
+
+func (s *localStorage) List(ctx context.Context, prefix string) ([]StorageObject, error) {
+	var objects []StorageObject
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if d.IsDir() {
+			// Inutile de parcourir les dossiers qui ne peuvent pas contenir le préfixe
+			if key != "." && !strings.HasPrefix(key+"/", prefix) && !strings.HasPrefix(prefix, key+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !strings.HasPrefix(key, prefix) || strings.HasSuffix(key, localStorageTmpSuffix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, StorageObject{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erreur listing de %s: %v", s.root, err)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (s *localStorage) Stat(ctx context.Context, key string) (*StorageObject, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(p)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s", ErrStorageObjectNotFound, p)
+	}
+	return &StorageObject{Key: key, Size: info.Size(), LastModified: info.ModTime()}, nil
+}
+
+func (s *localStorage) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, fmt.Errorf("%w: %s", ErrStorageObjectNotFound, p)
+		}
+		return nil, 0, fmt.Errorf("erreur ouverture de %s: %v", p, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("erreur stat de %s: %v", p, err)
+	}
+	return f, info.Size(), nil
+}
+
+func (s *localStorage) Download(ctx context.Context, key, destPath string) error {
+	src, size, err := s.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("erreur création dossier de destination: %v", err)
+	}
+	if err := ensureFreeSpace(filepath.Dir(destPath), size, "la copie de "+key); err != nil {
+		return err
+	}
+	progress := NewProgress(ctx, PhaseDownload, key, size)
+	if err := writeFileAtomic(ctx, destPath, progress.Reader(src)); err != nil {
+		return err
+	}
+	progress.Finish()
+	return nil
+}
+
+func (s *localStorage) Put(ctx context.Context, key string, body io.Reader, size int64, metadata map[string]string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if size > smallObjectSize {
+		progress := NewProgress(ctx, PhaseUpload, key, size)
+		defer progress.Finish()
+		body = progress.Reader(body)
+	}
+	return writeFileAtomic(ctx, p, body)
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erreur suppression de %s: %v", p, err)
+	}
+	return nil
+}
+
+// writeFileAtomic écrit r dans destPath via un fichier .part renommé une fois complet : une
+// copie interrompue ne laisse jamais un fichier tronqué sous le nom final
+func writeFileAtomic(ctx context.Context, destPath string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("erreur création dossier de destination: %v", err)
+	}
+	tmp := destPath + localStorageTmpSuffix
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("erreur création de %s: %v", tmp, err)
+	}
+	_, err = io.Copy(f, readerWithContext(ctx, r))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erreur écriture de %s: %v", destPath, err)
+	}
+	if err := os.Rename(tmp, destPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erreur écriture de %s: %v", destPath, err)
+	}
+	return nil
+}
+
+// readerWithContext interrompt la lecture de r dès que ctx est annulé
+func readerWithContext(ctx context.Context, r io.Reader) io.Reader {
+	return readerFunc(func(p []byte) (int, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return r.Read(p)
+	})
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
@@ -0,0 +1,178 @@
+package backend
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeGzipFile écrit content compressé en gzip dans un fichier temporaire
+func writeGzipFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dump.sql.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// useLocalRepository configure un dépôt de backups local vide et retourne son dossier
+func useLocalRepository(t *testing.T, encryptionKey string) string {
+	t.Helper()
+	root := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Backup.Repository = root
+	cfg.Backup.S3.Prefix = "backups"
+	cfg.Backup.WorkDir = t.TempDir()
+	cfg.Backup.EncryptionKey = encryptionKey
+	withConfig(t, cfg)
+	return root
+}
+
+func TestLocalRepositoryLifecycle(t *testing.T) {
+	for _, encrypted := range []bool{false, true} {
+		name := "clair"
+		if encrypted {
+			name = "chiffré"
+		}
+		t.Run(name, func(t *testing.T) {
+			useTestVault(t)
+			keyID := ""
+			if encrypted {
+				id, err := CreateBackupKey("test-key")
+				if err != nil {
+					t.Fatal(err)
+				}
+				keyID = id
+			}
+			root := useLocalRepository(t, keyID)
+			ctx := context.Background()
+
+			repo, err := openBackupRepository(ctx, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := repo.storage.(*localStorage); !ok {
+				t.Fatalf("stockage = %T, attendu *localStorage", repo.storage)
+			}
+
+			dump := writeGzipFile(t, strings.Repeat("INSERT INTO t VALUES (1);\n", 1000))
+			key := BackupKey{Prefix: "backups", Engine: EnginePostgres, Server: "prod", Database: "app",
+				Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Ext: ".sql.gz"}
+			result, err := repo.storeBackup(ctx, dump, key, "16.2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Key != "backups/postgres/prod/app/20250102-030405.sql.gz" || result.Bucket != root {
+				t.Errorf("résultat = %s dans %s", result.Key, result.Bucket)
+			}
+			if (result.Encryption != "") != encrypted || result.KeyID != keyID {
+				t.Errorf("chiffrement = %q (clé %q)", result.Encryption, result.KeyID)
+			}
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(result.Key))); err != nil {
+				t.Errorf("sauvegarde absente du dossier: %v", err)
+			}
+
+			// Catalogue, avec un alias de moteur
+			entries, err := ListBackupCatalog(ctx, CatalogFilter{Engine: "postgresql", Server: "prod"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Backup.Key != result.Key || !entries[0].HasManifest {
+				t.Fatalf("catalogue = %+v", entries)
+			}
+			if got := entries[0].Backup; got.SHA256 != result.SHA256 || got.EngineVersion != "16.2" || got.KeyID != keyID {
+				t.Errorf("manifeste = %+v", got)
+			}
+
+			// Vérification : empreinte et décompression, rapport écrit à côté de la sauvegarde
+			report, err := VerifyBackup(ctx, result.Key, VerifyOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !report.Passed {
+				t.Errorf("vérification en échec: %+v", report.Checks)
+			}
+			if _, err := repo.storage.Stat(ctx, result.Key+verifyReportSuffix); err != nil {
+				t.Errorf("rapport de vérification absent: %v", err)
+			}
+
+			// Suppression de la sauvegarde et de ses fichiers annexes via BackupStorage
+			var storage BackupStorage = repo.storage
+			for _, k := range append([]string{result.Key}, result.Key+manifestSuffix, result.Key+verifyReportSuffix) {
+				if err := storage.Delete(ctx, k); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := storage.Stat(ctx, result.Key); !errors.Is(err, ErrStorageObjectNotFound) {
+				t.Errorf("Stat après suppression: %v", err)
+			}
+			if objects, err := storage.List(ctx, ""); err != nil || len(objects) != 0 {
+				t.Errorf("dépôt non vide: %+v, %v", objects, err)
+			}
+			if entries, err := ListBackupCatalog(ctx, CatalogFilter{}); err != nil || len(entries) != 0 {
+				t.Errorf("catalogue après suppression = %+v, %v", entries, err)
+			}
+		})
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	if _, err := newLocalStorage(filepath.Join(t.TempDir(), "absent")); err == nil {
+		t.Error("dépôt absent accepté")
+	}
+	storage, err := newLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.Put(ctx, "a/b/obj.txt", strings.NewReader("contenu"), 7, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Un fichier en cours d'écriture n'est pas listé
+	if err := os.WriteFile(filepath.Join(storage.root, "a", "tmp"+localStorageTmpSuffix), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	objects, err := storage.List(ctx, "a/")
+	if err != nil || len(objects) != 1 || objects[0].Key != "a/b/obj.txt" || objects[0].Size != 7 {
+		t.Fatalf("List = %+v, %v", objects, err)
+	}
+	if objects, _ := storage.List(ctx, "z/"); len(objects) != 0 {
+		t.Errorf("List(z/) = %+v", objects)
+	}
+
+	dest := filepath.Join(t.TempDir(), "copie.txt")
+	if err := storage.Download(ctx, "a/b/obj.txt", dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "contenu" {
+		t.Errorf("Download = %q, %v", data, err)
+	}
+
+	if _, _, err := storage.Open(ctx, "absent.txt"); !errors.Is(err, ErrStorageObjectNotFound) {
+		t.Errorf("Open d'un objet absent: %v", err)
+	}
+	if _, err := storage.Stat(ctx, "../escape"); !errors.Is(err, ErrUnsafeArchivePath) {
+		t.Errorf("Stat hors du dépôt: %v", err)
+	}
+	if err := storage.Put(ctx, "../escape", strings.NewReader("x"), 1, nil); !errors.Is(err, ErrUnsafeArchivePath) {
+		t.Errorf("Put hors du dépôt: %v", err)
+	}
+}
@@ -3,40 +3,18 @@ package backend
 import (
 	"bufio"
 	"context"
-	"fmt"
 	"os/exec"
 	"strings"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-var AppCtxForLogToFrontend context.Context // à setter dans app.go
-
-func LogToFrontend(level, msg string) {
-	if AppCtxForLogToFrontend == nil {
-		return
-	}
-	prefix := ""
-	switch level {
-	case "debug":
-		prefix = "[DEBUG]"
-	case "info":
-		prefix = "[INFO]"
-	case "warn":
-		prefix = "[WARN]"
-	case "error":
-		prefix = "[ERROR]"
-	case "success":
-		prefix = "[SUCCESS]"
-	}
-	fullMsg := prefix + " " + msg
-	println(fullMsg) // Affiche dans la console du backend
-	runtime.EventsEmit(AppCtxForLogToFrontend, "backend-log", fullMsg)
-}
-
 // execCommand exécute une commande et retourne une erreur si elle échoue
 func execCommand(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
+	return execCommandContext(context.Background(), name, args...)
+}
+
+// execCommandContext exécute une commande, tuée si ctx est annulé
+func execCommandContext(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
 	
 	// Créer un pipe pour capturer stdout et stderr
 	stdout, err := cmd.StdoutPipe()
@@ -57,8 +35,7 @@ func execCommand(name string, args ...string) error {
 	go func() {
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
-			LogToFrontend("info", scanner.Text())
-			fmt.Println(scanner.Text()) // Afficher aussi dans le terminal
+			Log.Info(scanner.Text(), F("cmd", name))
 		}
 	}()
 
@@ -66,8 +43,7 @@ func execCommand(name string, args ...string) error {
 	go func() {
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
-			LogToFrontend("warning", scanner.Text())
-			fmt.Println(scanner.Text()) // Afficher aussi dans le terminal
+			Log.Warn(scanner.Text(), F("cmd", name))
 		}
 	}()
 
@@ -0,0 +1,469 @@
+package backend
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Le coffre (vault) stocke les mots de passe des bases et les secret keys S3 dans un fichier chiffré
+// (AES-256-GCM). La clé maître est soit dérivée d'une passphrase (scrypt), soit générée aléatoirement
+// et conservée dans le trousseau du système (Keychain, Secret Service, Credential Manager).
+
+const (
+	vaultKeyringService = "aidalinfo-cli"
+	vaultKeyringUser    = "vault-master-key"
+
+	vaultProtectionKeyring    = "keyring"
+	vaultProtectionPassphrase = "passphrase"
+)
+
+// ErrVaultLocked est retournée quand aucune passphrase ni aucun trousseau ne permet d'ouvrir le coffre
+var ErrVaultLocked = errors.New("coffre verrouillé: définissez AIDALINFO_VAULT_PASSPHRASE ou déverrouillez-le depuis le GUI")
+
+// Keyring abstrait le stockage de la clé maître du coffre
+type Keyring interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// osKeyring utilise le trousseau du système d'exploitation
+type osKeyring struct{}
+
+func (osKeyring) Get(key string) (string, error) { return keyring.Get(vaultKeyringService, key) }
+func (osKeyring) Set(key, value string) error    { return keyring.Set(vaultKeyringService, key, value) }
+func (osKeyring) Delete(key string) error        { return keyring.Delete(vaultKeyringService, key) }
+
+// FileKeyring est un trousseau stocké dans un fichier JSON (0600).
+// Il sert aux tests et aux machines sans trousseau système (AIDALINFO_KEYRING=file).
+type FileKeyring struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileKeyring) load() (map[string]string, error) {
+	values := map[string]string{}
+	data, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("trousseau fichier invalide (%s): %v", f.Path, err)
+	}
+	return values, nil
+}
+
+func (f *FileKeyring) save(values map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, data, 0o600)
+}
+
+func (f *FileKeyring) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *FileKeyring) Set(key, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values, err := f.load()
+	if err != nil {
+		return err
+	}
+	values[key] = value
+	return f.save(values)
+}
+
+func (f *FileKeyring) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values, err := f.load()
+	if err != nil {
+		return err
+	}
+	delete(values, key)
+	return f.save(values)
+}
+
+// vaultFile est le format chiffré enregistré sur disque
+type vaultFile struct {
+	Version    int    `json:"version"`
+	Protection string `json:"protection"`
+	Salt       string `json:"salt,omitempty"`
+	Nonce      string `json:"nonce"`
+	Data       string `json:"data"`
+}
+
+// Vault donne accès aux secrets chiffrés
+type Vault struct {
+	path       string
+	keyring    Keyring
+	passphrase string
+	// pendingSalt conserve le sel généré pour un nouveau coffre jusqu'à sa première écriture
+	pendingSalt []byte
+}
+
+// vaultLocks associe à chaque fichier de coffre le verrou qui sérialise ses accès dans le
+// processus : DefaultVault crée une instance par appel, et deux mises à jour concurrentes
+// (enregistrement d'un profil, binding Wails, sauvegarde planifiée) ne doivent pas s'écraser
+var vaultLocks sync.Map
+
+// lock prend le verrou du fichier du coffre et retourne la fonction qui le libère
+func (v *Vault) lock() func() {
+	path, err := filepath.Abs(v.path)
+	if err != nil {
+		path = v.path
+	}
+	value, _ := vaultLocks.LoadOrStore(path, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// NewVault crée un accès au coffre stocké dans path. Si passphrase est vide, la clé maître est
+// lue (ou créée) dans keyring.
+func NewVault(path string, kr Keyring, passphrase string) *Vault {
+	return &Vault{path: path, keyring: kr, passphrase: passphrase}
+}
+
+var (
+	vaultPassphrase   string
+	vaultPassphraseMu sync.RWMutex
+)
+
+// UnlockVault mémorise la passphrase du coffre pour la session (GUI) et vérifie qu'elle est correcte
+func UnlockVault(passphrase string) error {
+	vaultPassphraseMu.Lock()
+	vaultPassphrase = passphrase
+	vaultPassphraseMu.Unlock()
+
+	v, err := DefaultVault()
+	if err != nil {
+		return err
+	}
+	if _, err := v.List(); err != nil {
+		vaultPassphraseMu.Lock()
+		vaultPassphrase = ""
+		vaultPassphraseMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// DefaultVault ouvre le coffre de l'utilisateur (vault.json dans le dossier de configuration)
+func DefaultVault() (*Vault, error) {
+	dir, err := UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase := os.Getenv("AIDALINFO_VAULT_PASSPHRASE")
+	vaultPassphraseMu.RLock()
+	if vaultPassphrase != "" {
+		passphrase = vaultPassphrase
+	}
+	vaultPassphraseMu.RUnlock()
+
+	var kr Keyring = osKeyring{}
+	if strings.EqualFold(os.Getenv("AIDALINFO_KEYRING"), "file") {
+		kr = &FileKeyring{Path: filepath.Join(dir, "keyring.json")}
+	}
+	return NewVault(filepath.Join(dir, "vault.json"), kr, passphrase), nil
+}
+
+// VaultStatus décrit l'état du coffre pour le GUI
+type VaultStatus struct {
+	Exists     bool   `json:"exists"`
+	Protection string `json:"protection"`
+	Unlocked   bool   `json:"unlocked"`
+	Entries    int    `json:"entries"`
+}
+
+// Status retourne l'état du coffre sans jamais exposer les secrets
+func (v *Vault) Status() VaultStatus {
+	status := VaultStatus{}
+	vf, err := v.readFile()
+	if err != nil || vf == nil {
+		return status
+	}
+	status.Exists = true
+	status.Protection = vf.Protection
+	if entries, err := v.List(); err == nil {
+		status.Unlocked = true
+		status.Entries = len(entries)
+	}
+	return status
+}
+
+// Get retourne le secret associé à ref
+func (v *Vault) Get(ref string) (string, error) {
+	defer v.lock()()
+	secrets, _, err := v.load(false)
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[ref]
+	if !ok {
+		return "", fmt.Errorf("secret '%s' introuvable dans le coffre", ref)
+	}
+	RegisterSecret(secret)
+	return secret, nil
+}
+
+// Set enregistre (ou remplace) le secret associé à ref
+func (v *Vault) Set(ref, secret string) error {
+	defer v.lock()()
+	secrets, key, err := v.load(true)
+	if err != nil {
+		return err
+	}
+	RegisterSecret(secret)
+	secrets[ref] = secret
+	return v.store(secrets, key)
+}
+
+// Delete supprime le secret associé à ref (sans erreur s'il n'existe pas)
+func (v *Vault) Delete(ref string) error {
+	defer v.lock()()
+	secrets, key, err := v.load(false)
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[ref]; !ok {
+		return nil
+	}
+	delete(secrets, ref)
+	return v.store(secrets, key)
+}
+
+// List retourne les références des secrets (jamais leurs valeurs)
+func (v *Vault) List() ([]string, error) {
+	defer v.lock()()
+	secrets, _, err := v.load(false)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]string, 0, len(secrets))
+	for ref := range secrets {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+func (v *Vault) readFile() (*vaultFile, error) {
+	data, err := os.ReadFile(v.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture du coffre: %v", err)
+	}
+	var vf vaultFile
+	if err := json.Unmarshal(data, &vf); err != nil {
+		return nil, fmt.Errorf("coffre invalide (%s): %v", v.path, err)
+	}
+	return &vf, nil
+}
+
+// load déchiffre le coffre et retourne les secrets avec la clé maître.
+// Si le coffre n'existe pas encore, une clé maître n'est générée que lorsque create est vrai.
+func (v *Vault) load(create bool) (map[string]string, []byte, error) {
+	vf, err := v.readFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	if vf == nil {
+		if !create {
+			return map[string]string{}, nil, nil
+		}
+		key, err := v.newMasterKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		return map[string]string{}, key, nil
+	}
+
+	key, err := v.masterKey(vf)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := base64.StdEncoding.DecodeString(vf.Nonce)
+	if err != nil {
+		return nil, nil, fmt.Errorf("coffre corrompu: %v", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(vf.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("coffre corrompu: %v", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("impossible de déchiffrer le coffre (passphrase incorrecte ?)")
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, nil, fmt.Errorf("coffre corrompu: %v", err)
+	}
+	return secrets, key, nil
+}
+
+func (v *Vault) store(secrets map[string]string, key []byte) error {
+	vf, err := v.readFile()
+	if err != nil {
+		return err
+	}
+	if vf == nil {
+		vf = &vaultFile{Version: 1, Protection: vaultProtectionKeyring}
+		if v.passphrase != "" {
+			vf.Protection = vaultProtectionPassphrase
+			vf.Salt = base64.StdEncoding.EncodeToString(v.pendingSalt)
+		}
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	vf.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	vf.Data = base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil))
+
+	data, err := json.MarshalIndent(vf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(v.path), 0o700); err != nil {
+		return fmt.Errorf("erreur création dossier du coffre: %v", err)
+	}
+	if err := writeVaultFile(v.path, data); err != nil {
+		return fmt.Errorf("erreur écriture du coffre: %v", err)
+	}
+	return nil
+}
+
+// writeVaultFile remplace path par data via un fichier temporaire synchronisé sur disque puis
+// renommé : un arrêt brutal laisse l'ancien coffre ou le nouveau, jamais un fichier tronqué
+func writeVaultFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	// CreateTemp crée le fichier en 0600
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// masterKey retrouve la clé maître d'un coffre existant selon son mode de protection
+func (v *Vault) masterKey(vf *vaultFile) ([]byte, error) {
+	switch vf.Protection {
+	case vaultProtectionPassphrase:
+		if v.passphrase == "" {
+			return nil, ErrVaultLocked
+		}
+		salt, err := base64.StdEncoding.DecodeString(vf.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("coffre corrompu: %v", err)
+		}
+		return deriveVaultKey(v.passphrase, salt)
+	case vaultProtectionKeyring:
+		encoded, err := v.keyring.Get(vaultKeyringUser)
+		if err != nil {
+			return nil, fmt.Errorf("%w (trousseau: %v)", ErrVaultLocked, err)
+		}
+		return base64.StdEncoding.DecodeString(encoded)
+	}
+	return nil, fmt.Errorf("mode de protection du coffre inconnu: %s", vf.Protection)
+}
+
+// newMasterKey prépare la clé d'un nouveau coffre : passphrase si fournie, sinon trousseau
+func (v *Vault) newMasterKey() ([]byte, error) {
+	if v.passphrase != "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		v.pendingSalt = salt
+		return deriveVaultKey(v.passphrase, salt)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := v.keyring.Set(vaultKeyringUser, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("%w (trousseau indisponible: %v)", ErrVaultLocked, err)
+	}
+	return key, nil
+}
+
+func deriveVaultKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("erreur dérivation de la clé du coffre: %v", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("clé du coffre invalide: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
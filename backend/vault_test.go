@@ -0,0 +1,179 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// useTestVault redirige le coffre par défaut (DefaultVault) vers un dossier temporaire, avec un
+// trousseau fichier
+func useTestVault(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("AppData", filepath.Join(home, "AppData"))
+	t.Setenv("AIDALINFO_KEYRING", "file")
+	t.Setenv("AIDALINFO_VAULT_PASSPHRASE", "")
+}
+
+func TestVaultKeyringRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	kr := &FileKeyring{Path: filepath.Join(dir, "keyring.json")}
+	vault := NewVault(filepath.Join(dir, "vault.json"), kr, "")
+
+	if refs, err := vault.List(); err != nil || len(refs) != 0 {
+		t.Fatalf("List sur un coffre absent = %v, %v", refs, err)
+	}
+	if err := vault.Set("server/pg", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vault.Set("server/mongo", "autre"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Une nouvelle instance relit le fichier et la clé maître du trousseau
+	reopened := NewVault(filepath.Join(dir, "vault.json"), kr, "")
+	if secret, err := reopened.Get("server/pg"); err != nil || secret != "s3cr3t" {
+		t.Errorf("Get = %q, %v", secret, err)
+	}
+	if refs, err := reopened.List(); err != nil || !reflect.DeepEqual(refs, []string{"server/mongo", "server/pg"}) {
+		t.Errorf("List = %v, %v", refs, err)
+	}
+	if status := reopened.Status(); !status.Exists || status.Protection != vaultProtectionKeyring || !status.Unlocked || status.Entries != 2 {
+		t.Errorf("Status = %+v", status)
+	}
+
+	if err := reopened.Delete("server/pg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Delete("server/pg"); err != nil {
+		t.Errorf("Delete d'un secret absent: %v", err)
+	}
+	if _, err := reopened.Get("server/pg"); err == nil {
+		t.Error("secret supprimé encore lisible")
+	}
+	if refs, _ := reopened.List(); !reflect.DeepEqual(refs, []string{"server/mongo"}) {
+		t.Errorf("List après suppression = %v", refs)
+	}
+}
+
+func TestVaultWrongPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault.json")
+	kr := &FileKeyring{Path: filepath.Join(dir, "keyring.json")}
+	if err := NewVault(path, kr, "bonne passphrase").Set("server/pg", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong := NewVault(path, kr, "mauvaise passphrase")
+	if _, err := wrong.Get("server/pg"); err == nil {
+		t.Error("Get avec une mauvaise passphrase sans erreur")
+	}
+	if _, err := wrong.List(); err == nil {
+		t.Error("List avec une mauvaise passphrase sans erreur")
+	}
+	if err := wrong.Set("server/other", "x"); err == nil {
+		t.Error("Set avec une mauvaise passphrase sans erreur")
+	}
+	if secret, err := NewVault(path, kr, "bonne passphrase").Get("server/pg"); err != nil || secret != "s3cr3t" {
+		t.Errorf("Get avec la bonne passphrase = %q, %v", secret, err)
+	}
+}
+
+func TestVaultLocked(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault.json")
+
+	// Coffre protégé par passphrase, ouvert sans passphrase
+	if err := NewVault(path, &FileKeyring{Path: filepath.Join(dir, "keyring.json")}, "passphrase").Set("server/pg", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	locked := NewVault(path, &FileKeyring{Path: filepath.Join(dir, "keyring.json")}, "")
+	if _, err := locked.Get("server/pg"); !errors.Is(err, ErrVaultLocked) {
+		t.Errorf("Get: %v, attendu ErrVaultLocked", err)
+	}
+	if status := locked.Status(); !status.Exists || status.Unlocked {
+		t.Errorf("Status = %+v, attendu verrouillé", status)
+	}
+
+	// Coffre protégé par le trousseau, dont la clé maître a disparu
+	krPath := filepath.Join(dir, "keyring2.json")
+	path2 := filepath.Join(dir, "vault2.json")
+	if err := NewVault(path2, &FileKeyring{Path: krPath}, "").Set("server/pg", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewVault(path2, &FileKeyring{Path: filepath.Join(dir, "empty.json")}, "").List(); !errors.Is(err, ErrVaultLocked) {
+		t.Errorf("List sans clé maître: %v, attendu ErrVaultLocked", err)
+	}
+}
+
+func TestDefaultVaultUsesFileKeyring(t *testing.T) {
+	useTestVault(t)
+	vault, err := DefaultVault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vault.keyring.(*FileKeyring); !ok {
+		t.Fatalf("trousseau = %T, attendu *FileKeyring", vault.keyring)
+	}
+	if err := vault.Set("server/pg", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := DefaultVault()
+	if secret, err := other.Get("server/pg"); err != nil || secret != "s3cr3t" {
+		t.Errorf("Get = %q, %v", secret, err)
+	}
+}
+
+func TestDefaultVaultConcurrentUpdates(t *testing.T) {
+	useTestVault(t)
+	// Le coffre existe déjà : seules les mises à jour sont concurrentes
+	first, _ := DefaultVault()
+	if err := first.Set("server/init", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	const goroutines, perGoroutine = 8, 10
+	const writers = goroutines*perGoroutine + 1
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				// Une instance par écriture, comme les appels successifs à DefaultVault
+				vault, err := DefaultVault()
+				if err == nil {
+					err = vault.Set(fmt.Sprintf("server/%d-%d", i, j), "secret")
+				}
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vault, _ := DefaultVault()
+	refs, err := vault.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != writers {
+		t.Errorf("%d secret(s) dans le coffre, attendu %d: des mises à jour se sont écrasées", len(refs), writers)
+	}
+	// Aucun fichier temporaire ne reste à côté du coffre
+	dir := filepath.Dir(vault.path)
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(matches) != 0 {
+		t.Errorf("fichiers temporaires restants: %v", matches)
+	}
+}
@@ -0,0 +1,520 @@
+package backend
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Contrôles d'un rapport de vérification
+const (
+	VerifyCheckChecksum = "checksum"
+	VerifyCheckArchive  = "archive"
+	VerifyCheckRestore  = "restore"
+	VerifyCheckSanity   = "sanity"
+)
+
+// mongoArchiveMagic est l'en-tête d'une archive mongodump --archive
+var mongoArchiveMagic = []byte{0x6d, 0xe2, 0x99, 0x81}
+
+// VerifyOptions règle la vérification d'une sauvegarde
+type VerifyOptions struct {
+	// RestoreServer (ID ou nom de profil), s'il n'est pas vide, reçoit la sauvegarde dans une
+	// base jetable, supprimée après les contrôles
+	RestoreServer string `json:"restoreServer"`
+}
+
+// VerifyCheck est le résultat d'un contrôle
+type VerifyCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Detail  string `json:"detail"`
+}
+
+// TableCount est le nombre d'enregistrements d'une table (ou collection) restaurée
+type TableCount struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// VerifyReport est le rapport de vérification enregistré à côté de la sauvegarde
+// (<clé>.verify.json)
+type VerifyReport struct {
+	Bucket        string        `json:"bucket"`
+	Key           string        `json:"key"`
+	Engine        string        `json:"engine"`
+	VerifiedAt    time.Time     `json:"verifiedAt"`
+	ToolVersion   string        `json:"toolVersion"`
+	Passed        bool          `json:"passed"`
+	Checks        []VerifyCheck `json:"checks"`
+	RestoreServer string        `json:"restoreServer,omitempty"`
+	Tables        []TableCount  `json:"tables,omitempty"`
+}
+
+func (r *VerifyReport) add(check VerifyCheck) {
+	r.Checks = append(r.Checks, check)
+}
+
+func (r *VerifyReport) failed(name string) bool {
+	for _, check := range r.Checks {
+		if check.Name == name && !check.Passed && !check.Skipped {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBackupRef accepte une clé du dépôt ou une URL s3://bucket/clé
+func parseBackupRef(ref string) (bucket, key string) {
+	if rest, ok := strings.CutPrefix(ref, "s3://"); ok {
+		bucket, key, _ = strings.Cut(rest, "/")
+		return bucket, key
+	}
+	return "", strings.TrimPrefix(ref, "/")
+}
+
+// VerifyBackup vérifie qu'une sauvegarde du dépôt est restaurable : l'objet est lu en flux,
+// son empreinte SHA-256 comparée à celle du manifeste et son contenu entièrement décompressé.
+// Avec opts.RestoreServer, la sauvegarde est en plus restaurée dans une base jetable où le
+// nombre d'enregistrements de chaque table est compté. Le rapport est enregistré sous
+// <clé>.verify.json ; une vérification en échec n'est pas une erreur (voir report.Passed).
+func VerifyBackup(ctx context.Context, ref string, opts VerifyOptions) (*VerifyReport, error) {
+	bucket, key := parseBackupRef(ref)
+	if key == "" {
+		return nil, fmt.Errorf("la clé de la sauvegarde est requise")
+	}
+	repo, err := openBackupRepository(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	backup, err := repo.describeBackup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if storage, ok := repo.storage.(*s3Storage); ok {
+		if err := ensureRetrievable(ctx, storage.client, storage.bucket, key); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &VerifyReport{
+		Bucket:        repo.bucket,
+		Key:           key,
+		Engine:        backup.Engine,
+		VerifiedAt:    time.Now().UTC(),
+		ToolVersion:   GetCurrentVersion(),
+		RestoreServer: opts.RestoreServer,
+	}
+	Log.Info(fmt.Sprintf("Vérification de %s/%s (%s)", repo.bucket, key, FormatBytes(backup.Size)))
+	if err := repo.verifyIntegrity(ctx, backup, report); err != nil {
+		return nil, err
+	}
+
+	if opts.RestoreServer != "" {
+		switch {
+		case report.failed(VerifyCheckChecksum) || report.failed(VerifyCheckArchive):
+			report.add(VerifyCheck{Name: VerifyCheckRestore, Skipped: true, Detail: "sauvegarde illisible, restauration non tentée"})
+		default:
+			repo.verifyRestore(ctx, backup, opts.RestoreServer, report)
+		}
+	}
+
+	report.Passed = true
+	for _, check := range report.Checks {
+		if !check.Passed && !check.Skipped {
+			report.Passed = false
+		}
+	}
+	if err := repo.writeVerifyReport(ctx, report); err != nil {
+		Log.Warn(err.Error())
+	}
+	if report.Passed {
+		Log.Success(fmt.Sprintf("Sauvegarde %s vérifiée", key))
+	} else {
+		Log.Error(fmt.Sprintf("Vérification de %s en échec", key))
+	}
+	return report, nil
+}
+
+// describeBackup retourne la description d'une sauvegarde : son manifeste s'il existe, sinon
+// les informations de sa clé et de ses métadonnées S3
+func (r *backupRepository) describeBackup(ctx context.Context, key string) (*BackupResult, error) {
+	head, err := r.storage.Stat(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("sauvegarde introuvable %s/%s: %v", r.storage, key, err)
+	}
+	manifest, err := r.readManifest(ctx, key)
+	if err == nil {
+		return manifest, nil
+	}
+	Log.Debug(err.Error())
+
+	backup := &BackupResult{
+		Bucket:      r.bucket,
+		Key:         key,
+		Engine:      head.Metadata[MetaEngine],
+		Server:      head.Metadata[MetaServer],
+		Database:    head.Metadata[MetaDatabase],
+		Size:        head.Size,
+		SHA256:      head.Metadata[MetaSHA256],
+		Compression: string(compressionFromName(key)),
+		Encryption:  head.Metadata[MetaEncryption],
+		KeyID:       head.Metadata[MetaKeyID],
+	}
+	if parsed, ok := parseBackupKey(r.prefix, key); ok {
+		if backup.Engine == "" {
+			backup.Engine = parsed.Engine
+		}
+		if backup.Database == "" {
+			backup.Database = parsed.Database
+		}
+		backup.CreatedAt = parsed.Time
+	}
+	return backup, nil
+}
+
+// verifyIntegrity lit la sauvegarde une fois : l'empreinte est calculée sur les octets reçus
+// pendant que le contenu est décompressé et parcouru
+func (r *backupRepository) verifyIntegrity(ctx context.Context, backup *BackupResult, report *VerifyReport) error {
+	body, size, err := r.storage.Open(ctx, backup.Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	progress := NewProgress(ctx, PhaseVerify, backup.Key, size)
+	h := sha256.New()
+	tee := io.TeeReader(progress.Reader(body), h)
+
+	detail, inspectErr := inspectBackupStream(backup.Engine, tee)
+	// Le reste de l'objet (données après la fin du flux compressé) compte dans l'empreinte
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return fmt.Errorf("erreur lecture de %s: %v", backup.Key, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	progress.Finish()
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	switch {
+	case backup.SHA256 == "":
+		report.add(VerifyCheck{Name: VerifyCheckChecksum, Skipped: true, Detail: fmt.Sprintf("aucune empreinte enregistrée (calculée: %s)", sum)})
+	case strings.EqualFold(backup.SHA256, sum):
+		report.add(VerifyCheck{Name: VerifyCheckChecksum, Passed: true, Detail: "SHA-256 " + sum})
+	default:
+		report.add(VerifyCheck{Name: VerifyCheckChecksum, Detail: fmt.Sprintf("SHA-256 %s, attendu %s", sum, backup.SHA256)})
+	}
+	if inspectErr != nil {
+		report.add(VerifyCheck{Name: VerifyCheckArchive, Detail: inspectErr.Error()})
+	} else {
+		report.add(VerifyCheck{Name: VerifyCheckArchive, Passed: true, Detail: detail})
+	}
+	return nil
+}
+
+// inspectBackupStream décompresse entièrement une sauvegarde et vérifie sa structure
+// (entrées tar, en-tête d'archive mongodump)
+func inspectBackupStream(engine string, r io.Reader) (string, error) {
+	dump, format, err := openDumpStream(r)
+	if err != nil {
+		return "", err
+	}
+	if engine == EngineMongo {
+		magic := make([]byte, len(mongoArchiveMagic))
+		if _, err := io.ReadFull(dump, magic); err != nil || !bytes.Equal(magic, mongoArchiveMagic) {
+			return "", fmt.Errorf("archive mongodump invalide")
+		}
+	}
+	if format == dumpFormatTar {
+		tr := tar.NewReader(dump)
+		entries := 0
+		var size int64
+		for {
+			_, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", fmt.Errorf("archive tar invalide: %v", err)
+			}
+			n, err := io.Copy(io.Discard, tr)
+			if err != nil {
+				return "", fmt.Errorf("archive tar invalide: %v", err)
+			}
+			entries++
+			size += n
+		}
+		return fmt.Sprintf("archive tar lisible: %d entrée(s), %s", entries, FormatBytes(size)), nil
+	}
+	n, err := io.Copy(io.Discard, dump)
+	if err != nil {
+		return "", fmt.Errorf("erreur décompression: %v", err)
+	}
+	return fmt.Sprintf("%s décompressés (format %s)", FormatBytes(n), format), nil
+}
+
+// verifyRestore restaure la sauvegarde dans une base jetable de serverRef puis compte les
+// enregistrements de chaque table. La base est supprimée même en cas d'échec.
+func (r *backupRepository) verifyRestore(ctx context.Context, backup *BackupResult, serverRef string, report *VerifyReport) {
+	fail := func(err error) {
+		report.add(VerifyCheck{Name: VerifyCheckRestore, Detail: err.Error()})
+	}
+	switch backup.Engine {
+	case EngineMongo, EngineMySQL, EnginePostgres:
+	default:
+		report.add(VerifyCheck{Name: VerifyCheckRestore, Skipped: true, Detail: fmt.Sprintf("restauration non prise en charge pour le type %s", backup.Engine)})
+		return
+	}
+	profile, err := GetServerProfile(serverRef)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if profile.Engine != backup.Engine {
+		fail(fmt.Errorf("le serveur '%s' est de type %s (attendu: %s)", profile.Name, profile.Engine, backup.Engine))
+		return
+	}
+
+	body, size, err := r.storage.Open(ctx, backup.Key)
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer body.Close()
+	progress := NewProgress(ctx, PhaseRestore, backup.Key, size)
+
+	scratch := "aidalinfo_verify_" + time.Now().UTC().Format("20060102150405")
+	Log.Info(fmt.Sprintf("Restauration de contrôle dans %s sur %s", scratch, profile.Name))
+	defer func() {
+		// La base jetable est supprimée même si la vérification a été annulée
+		if err := dropScratchDatabase(context.WithoutCancel(ctx), profile, scratch); err != nil {
+			Log.Warn(fmt.Sprintf("Base de contrôle %s non supprimée sur %s: %v", scratch, profile.Name, err))
+		}
+	}()
+	if err := restoreScratchDatabase(ctx, profile, progress.Reader(body), backup.Database, scratch); err != nil {
+		fail(err)
+		return
+	}
+	progress.Finish()
+	report.add(VerifyCheck{Name: VerifyCheckRestore, Passed: true, Detail: fmt.Sprintf("restaurée dans %s sur %s", scratch, profile.Name)})
+
+	tables, err := countScratchTables(ctx, profile, scratch)
+	if err != nil {
+		report.add(VerifyCheck{Name: VerifyCheckSanity, Detail: err.Error()})
+		return
+	}
+	report.Tables = tables
+	unit, rowsUnit := "table(s)", "ligne(s)"
+	if profile.Engine == EngineMongo {
+		unit, rowsUnit = "collection(s)", "document(s)"
+	}
+	var rows int64
+	for _, t := range tables {
+		rows += t.Rows
+	}
+	report.add(VerifyCheck{
+		Name:   VerifyCheckSanity,
+		Passed: len(tables) > 0,
+		Detail: fmt.Sprintf("%d %s, %d %s", len(tables), unit, rows, rowsUnit),
+	})
+}
+
+// restoreScratchDatabase crée la base scratch sur profile et y restaure le dump lu sur r.
+// source est la base d'origine, utilisée pour renommer les collections d'une archive Mongo.
+func restoreScratchDatabase(ctx context.Context, profile *ServerProfile, r io.Reader, source, scratch string) error {
+	switch profile.Engine {
+	case EnginePostgres:
+		env := postgresEnv(profile.Password)
+		if err := ensurePostgresDatabase(ctx, env, profile.Host, profile.Port, profile.User, scratch); err != nil {
+			return err
+		}
+		return restorePostgresStream(ctx, r, env, profile.Host, profile.Port, profile.User, scratch)
+	case EngineMySQL:
+		args, cleanup, err := mysqlClientArgs(profile.Host, profile.Port, profile.User, profile.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		createCmd := exec.CommandContext(ctx, "mysql", append(append([]string{}, args...), "-e", "CREATE DATABASE "+quoteMySQLIdent(scratch))...)
+		if output, err := createCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("impossible de créer la base %s: %v: %s", scratch, err, strings.TrimSpace(string(output)))
+		}
+		return restoreMySQLStream(ctx, r, args, scratch)
+	default:
+		if source == "" {
+			return fmt.Errorf("base d'origine inconnue, impossible de renommer l'archive Mongo")
+		}
+		connArgs, cleanup, err := mongoToolArgs(profile.Host, profile.Port, profile.User, profile.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		// Une sauvegarde chiffrée est déchiffrée à la volée avant mongorestore
+		stdin, err := maybeDecrypt(bufio.NewReaderSize(r, 64*1024))
+		if err != nil {
+			return err
+		}
+		args := append([]string{"--gzip", "--archive", "--nsFrom", source + ".*", "--nsTo", scratch + ".*"}, connArgs...)
+		cmd := exec.CommandContext(ctx, "mongorestore", args...)
+		cmd.Stdin = stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("erreur mongorestore: %v", err)
+		}
+		return nil
+	}
+}
+
+// countScratchTables compte les enregistrements de chaque table (ou collection) de database
+func countScratchTables(ctx context.Context, profile *ServerProfile, database string) ([]TableCount, error) {
+	switch profile.Engine {
+	case EnginePostgres:
+		names, err := psqlLines(ctx, profile, database,
+			"SELECT format('%I.%I', table_schema, table_name) FROM information_schema.tables "+
+				"WHERE table_type = 'BASE TABLE' AND table_schema NOT IN ('pg_catalog', 'information_schema') ORDER BY 1")
+		if err != nil || len(names) == 0 {
+			return nil, err
+		}
+		lines, err := psqlLines(ctx, profile, database, tableCountQuery(names, func(name string) string { return name }))
+		if err != nil {
+			return nil, err
+		}
+		return parseTableCounts(lines)
+	case EngineMySQL:
+		args, cleanup, err := mysqlClientArgs(profile.Host, profile.Port, profile.User, profile.Password)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		query := func(sql string) ([]string, error) {
+			output, err := exec.CommandContext(ctx, "mysql", append(append([]string{}, args...), "-N", "-B", database, "-e", sql)...).Output()
+			if err != nil {
+				return nil, fmt.Errorf("erreur requête MySQL: %v", err)
+			}
+			return nonEmptyLines(output), nil
+		}
+		names, err := query("SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY 1")
+		if err != nil || len(names) == 0 {
+			return nil, err
+		}
+		lines, err := query(tableCountQuery(names, quoteMySQLIdent))
+		if err != nil {
+			return nil, err
+		}
+		return parseTableCounts(lines)
+	default:
+		var counts []TableCount
+		err := withMongoProfile(ctx, profile, func(client *mongo.Client) error {
+			var err error
+			counts, err = mongoCollectionCounts(ctx, client, database)
+			return err
+		})
+		return counts, err
+	}
+}
+
+// tableCountQuery construit une requête qui retourne "<table>\t<nombre>" pour chaque table
+func tableCountQuery(tables []string, quote func(string) string) string {
+	parts := make([]string, len(tables))
+	for i, table := range tables {
+		parts[i] = fmt.Sprintf("SELECT '%s', COUNT(*) FROM %s", strings.ReplaceAll(table, "'", "''"), quote(table))
+	}
+	return strings.Join(parts, " UNION ALL ")
+}
+
+// parseTableCounts lit les lignes "<table>\t<nombre>"
+func parseTableCounts(lines []string) ([]TableCount, error) {
+	var counts []TableCount
+	for _, line := range lines {
+		i := strings.LastIndex(line, "\t")
+		if i < 0 {
+			return nil, fmt.Errorf("ligne de comptage inattendue: %q", line)
+		}
+		rows, err := strconv.ParseInt(strings.TrimSpace(line[i+1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ligne de comptage inattendue: %q", line)
+		}
+		counts = append(counts, TableCount{Name: line[:i], Rows: rows})
+	}
+	return counts, nil
+}
+
+// psqlLines exécute une requête sur database et retourne les lignes de résultat (colonnes
+// séparées par une tabulation)
+func psqlLines(ctx context.Context, profile *ServerProfile, database, query string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "psql",
+		"-h", profile.Host,
+		"-p", profile.Port,
+		"-U", profile.User,
+		"-d", database,
+		"-t", "-A", "-F", "\t",
+		"-c", query)
+	cmd.Env = postgresEnv(profile.Password)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("erreur requête PostgreSQL: %v", err)
+	}
+	return nonEmptyLines(output), nil
+}
+
+// dropScratchDatabase supprime la base jetable d'une vérification
+func dropScratchDatabase(ctx context.Context, profile *ServerProfile, database string) error {
+	switch profile.Engine {
+	case EnginePostgres:
+		_, err := psqlLines(ctx, profile, "postgres", fmt.Sprintf(`DROP DATABASE IF EXISTS "%s"`, database))
+		return err
+	case EngineMySQL:
+		args, cleanup, err := mysqlClientArgs(profile.Host, profile.Port, profile.User, profile.Password)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		return exec.CommandContext(ctx, "mysql", append(args, "-e", "DROP DATABASE IF EXISTS "+quoteMySQLIdent(database))...).Run()
+	default:
+		return withMongoProfile(ctx, profile, func(client *mongo.Client) error {
+			return client.Database(database).Drop(ctx)
+		})
+	}
+}
+
+// writeVerifyReport enregistre le rapport à côté de la sauvegarde
+func (r *backupRepository) writeVerifyReport(ctx context.Context, report *VerifyReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := r.storage.Put(ctx, report.Key+verifyReportSuffix, bytes.NewReader(data), int64(len(data)), nil); err != nil {
+		return fmt.Errorf("erreur écriture du rapport de vérification de %s: %v", report.Key, err)
+	}
+	return nil
+}
+
+func quoteMySQLIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func nonEmptyLines(output []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeTool installe en tête du PATH un script name qui recopie son entrée standard dans le
+// fichier retourné
+func fakeTool(t *testing.T, name string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("outil factice en script shell")
+	}
+	dir := t.TempDir()
+	capture := filepath.Join(dir, name+".stdin")
+	script := "#!/bin/sh\ncat > '" + capture + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return capture
+}
+
+func TestRestoreScratchMongoDecrypts(t *testing.T) {
+	useTestVault(t)
+	capture := fakeTool(t, "mongorestore")
+
+	id, err := CreateBackupKey("test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := loadBackupKey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noncePrefix, err := newEncryptionNoncePrefix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := append(append([]byte{}, mongoArchiveMagic...), bytes.Repeat([]byte("collection"), 10000)...)
+	encrypted, err := newEncryptingReader(bytes.NewReader(archive), &backupEncryption{keyID: id, key: key}, noncePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile := &ServerProfile{Engine: EngineMongo, Host: "localhost", Port: "27017"}
+	if err := restoreScratchDatabase(context.Background(), profile, encrypted, "app", "app_verify"); err != nil {
+		t.Fatalf("restoreScratchDatabase: %v", err)
+	}
+	got, err := os.ReadFile(capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, archive) {
+		t.Errorf("mongorestore a reçu %d octets non déchiffrés (attendu l'archive en clair de %d octets)", len(got), len(archive))
+	}
+}
@@ -0,0 +1,186 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Les dumps, archives, extractions et téléchargements temporaires sont écrits dans un dossier de
+// travail : <backup.workDir>/aidalinfo-cli si backup.workDir est configuré, sinon
+// <cache utilisateur>/aidalinfo-cli/work (~/.cache sous Linux, ~/Library/Caches sous macOS,
+// %LocalAppData% sous Windows). Le sous-dossier dédié est indispensable : le nettoyage supprime
+// tout ce qu'il contient, alors que backup.workDir peut désigner un dossier partagé (/tmp,
+// Downloads) et que le dossier de cache de l'application contient aussi les logs.
+// Les fichiers laissés par une exécution interrompue sont supprimés au premier accès au dossier
+// une fois expirés, ou à la demande avec la commande cache clean.
+
+const (
+	// workDirOrphanAge est l'âge au-delà duquel un fichier temporaire est considéré abandonné
+	workDirOrphanAge = 24 * time.Hour
+	// workDirResumableAge est la durée de conservation d'un téléchargement interrompu, qui peut
+	// être repris
+	workDirResumableAge = 7 * 24 * time.Hour
+	// freeSpaceMargin est l'espace laissé libre en plus de la taille attendue d'une opération
+	freeSpaceMargin = 512 * 1024 * 1024
+)
+
+// ErrInsufficientSpace est retournée quand le dossier de travail n'a pas la place nécessaire
+var ErrInsufficientSpace = errors.New("espace disque insuffisant")
+
+var workDirCleanOnce sync.Once
+
+// workDirName est le sous-dossier de backup.workDir réservé aux fichiers temporaires de l'outil
+const workDirName = "aidalinfo-cli"
+
+// defaultWorkDirName est le sous-dossier du dossier de cache de l'application (qui contient
+// aussi logs/) réservé aux fichiers temporaires
+const defaultWorkDirName = "work"
+
+// WorkDir retourne le dossier de travail, créé si besoin
+func WorkDir() (string, error) {
+	dir := strings.TrimSpace(CurrentConfig().Backup.WorkDir)
+	if dir != "" {
+		dir = filepath.Join(dir, workDirName)
+	} else {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("erreur récupération du dossier de cache: %v", err)
+		}
+		dir = filepath.Join(cacheDir, "aidalinfo-cli", defaultWorkDirName)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("erreur création du dossier de travail: %v", err)
+	}
+	return dir, nil
+}
+
+// getWorkDir retourne le dossier de travail des opérations et, au premier appel, supprime les
+// fichiers abandonnés par des exécutions précédentes
+func getWorkDir() (string, error) {
+	dir, err := WorkDir()
+	if err != nil {
+		return "", err
+	}
+	workDirCleanOnce.Do(func() {
+		report, err := cleanWorkDir(dir, false)
+		if err != nil {
+			Log.Debug(fmt.Sprintf("Nettoyage du dossier de travail: %v", err))
+			return
+		}
+		if report.Removed > 0 {
+			Log.Info(fmt.Sprintf("%d fichier(s) temporaire(s) abandonné(s) supprimé(s) (%s)", report.Removed, FormatBytes(report.Freed)))
+		}
+	})
+	return dir, nil
+}
+
+// ensureFreeSpace vérifie que dir peut recevoir needed octets (plus une marge) avant de lancer
+// une opération. Si l'espace libre ne peut pas être mesuré, l'opération est tentée.
+func ensureFreeSpace(dir string, needed int64, what string) error {
+	if needed <= 0 {
+		return nil
+	}
+	free, err := diskFreeSpace(dir)
+	if err != nil {
+		Log.Debug(fmt.Sprintf("Espace libre de %s inconnu: %v", dir, err))
+		return nil
+	}
+	if required := uint64(needed) + freeSpaceMargin; free < required {
+		return fmt.Errorf("%w dans %s pour %s: %s nécessaires, %s disponibles (dossier configurable avec backup.workDir)",
+			ErrInsufficientSpace, dir, what, FormatBytes(int64(required)), FormatBytes(int64(free)))
+	}
+	return nil
+}
+
+// CacheCleanReport résume un nettoyage du dossier de travail
+type CacheCleanReport struct {
+	Dir     string `json:"dir"`
+	Removed int    `json:"removed"`
+	Freed   int64  `json:"freed"`
+}
+
+// CleanWorkDir supprime les fichiers abandonnés du dossier de travail (et de l'ancien dossier
+// ~/Downloads/aidalinfo-cli-tmp). Avec all, tout le contenu est supprimé, téléchargements
+// interrompus compris : à n'utiliser que lorsqu'aucune opération n'est en cours.
+func CleanWorkDir(all bool) (*CacheCleanReport, error) {
+	dir, err := WorkDir()
+	if err != nil {
+		return nil, err
+	}
+	report, err := cleanWorkDir(dir, all)
+	if err != nil {
+		return nil, err
+	}
+	// Les versions précédentes travaillaient dans le dossier Downloads
+	if home, err := os.UserHomeDir(); err == nil {
+		legacy := filepath.Join(home, "Downloads", "aidalinfo-cli-tmp")
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			if old, err := cleanWorkDir(legacy, true); err == nil {
+				report.Removed += old.Removed
+				report.Freed += old.Freed
+				os.Remove(legacy)
+			}
+		}
+	}
+	return report, nil
+}
+
+// cleanWorkDir supprime les entrées de dir expirées (toutes avec all). Un téléchargement
+// interrompu, reconnaissable à son fichier d'état, est conservé plus longtemps pour être repris.
+func cleanWorkDir(dir string, all bool) (*CacheCleanReport, error) {
+	report := &CacheCleanReport{Dir: dir}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture du dossier de travail: %v", err)
+	}
+	names := map[string]bool{}
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		name := entry.Name()
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		maxAge := workDirOrphanAge
+		download := strings.TrimSuffix(name, downloadStateSuffix)
+		if download != name || names[name+downloadStateSuffix] {
+			maxAge = workDirResumableAge
+			// L'état et le fichier d'un téléchargement expirent ensemble, d'après le fichier d'état
+			if state, err := os.Stat(filepath.Join(dir, download+downloadStateSuffix)); err == nil {
+				info = state
+			}
+		}
+		if !all && now.Sub(info.ModTime()) < maxAge {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		size := pathSize(path)
+		if err := os.RemoveAll(path); err != nil {
+			Log.Warn(fmt.Sprintf("Impossible de supprimer %s: %v", path, err))
+			continue
+		}
+		report.Removed++
+		report.Freed += size
+	}
+	return report, nil
+}
+
+// pathSize retourne la taille d'un fichier ou le total d'un dossier
+func pathSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
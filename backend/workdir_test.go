@@ -0,0 +1,146 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withConfig active cfg pendant le test
+func withConfig(t *testing.T, cfg *Config) {
+	t.Helper()
+	previous := CurrentConfig()
+	SetConfig(cfg)
+	t.Cleanup(func() { SetConfig(previous) })
+}
+
+func TestCleanWorkDirKeepsFilesOfConfiguredDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	base := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Backup.WorkDir = base
+	withConfig(t, cfg)
+
+	// Un fichier de l'utilisateur, ancien, à côté du dossier de travail
+	userFile := filepath.Join(base, "photos.tar")
+	if err := os.WriteFile(userFile, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	os.Chtimes(userFile, old, old)
+
+	dir, err := WorkDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != filepath.Join(base, workDirName) {
+		t.Fatalf("WorkDir = %s, attendu le sous-dossier %s", dir, workDirName)
+	}
+	tmp := filepath.Join(dir, "mongo-dump-app-1.bson.gz")
+	if err := os.WriteFile(tmp, []byte("dump"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := CleanWorkDir(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Removed != 1 {
+		t.Errorf("Removed = %d, attendu 1", report.Removed)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Errorf("fichier temporaire conservé: %v", err)
+	}
+	if _, err := os.Stat(userFile); err != nil {
+		t.Errorf("fichier de l'utilisateur supprimé: %v", err)
+	}
+}
+
+func TestCleanWorkDirKeepsLogs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("LocalAppData", filepath.Join(home, "AppData", "Local"))
+	withConfig(t, DefaultConfig())
+
+	logPath, err := DefaultLogFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logsDir := filepath.Dir(logPath)
+	if err := os.MkdirAll(logsDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logPath, []byte("log"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	os.Chtimes(logPath, old, old)
+	os.Chtimes(logsDir, old, old)
+
+	dir, err := WorkDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir == filepath.Dir(logsDir) {
+		t.Fatalf("le dossier de travail %s contient les logs", dir)
+	}
+
+	for _, all := range []bool{false, true} {
+		tmp := filepath.Join(dir, "mysql-dump-app-1.sql.gz")
+		if err := os.WriteFile(tmp, []byte("dump"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(tmp, old, old)
+		report, err := cleanWorkDir(dir, all)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Removed != 1 {
+			t.Errorf("all=%t: Removed = %d, attendu 1", all, report.Removed)
+		}
+		if _, err := os.Stat(logPath); err != nil {
+			t.Fatalf("all=%t: log supprimé: %v", all, err)
+		}
+	}
+	if _, err := CleanWorkDir(true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(logPath); err != nil {
+		t.Errorf("log supprimé par CleanWorkDir: %v", err)
+	}
+}
+
+func TestCleanWorkDirExpiry(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, age time.Duration) {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		os.Chtimes(p, mtime, mtime)
+	}
+	write("recent.sql.gz", time.Hour)
+	write("orphan.sql.gz", 2*24*time.Hour)
+	// Téléchargement interrompu de trois jours : encore repris
+	write("backup.tar.gz", 3*24*time.Hour)
+	write("backup.tar.gz"+downloadStateSuffix, 3*24*time.Hour)
+
+	report, err := cleanWorkDir(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Removed != 1 {
+		t.Errorf("Removed = %d, attendu 1", report.Removed)
+	}
+	for name, kept := range map[string]bool{
+		"recent.sql.gz": true, "orphan.sql.gz": false,
+		"backup.tar.gz": true, "backup.tar.gz" + downloadStateSuffix: true,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != kept {
+			t.Errorf("%s: conservé = %t, attendu %t", name, err == nil, kept)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package cmd
 import (
 	"aidalinfo-copilot/backend"
 	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -12,6 +15,11 @@ var (
 	s3Bucket   string
 	localPath  string
 	restore    bool
+
+	catalogFilter backend.CatalogFilter
+	verifyOptions backend.VerifyOptions
+	thawOptions   backend.ThawOptions
+	restoreOpts   backend.RestoreOptions
 )
 
 var backupCmd = &cobra.Command{
@@ -19,6 +27,17 @@ var backupCmd = &cobra.Command{
 	Short: "Sauvegarder ou restaurer le projet",
 	Long:  `Sauvegarde ou restaure le projet vers/depuis S3 ou un répertoire local.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// Les flags non renseignés retombent sur la section backup de la configuration
+		if !cmd.Flags().Changed("s3-bucket") {
+			s3Bucket = cfg.Backup.S3.Bucket
+		}
+		if !cmd.Flags().Changed("local-path") {
+			localPath = cfg.Backup.LocalPath
+		}
+		if backupType == "" && localPath != "" && !cmd.Flags().Changed("s3-bucket") {
+			backupType = "local"
+		}
+
 		if restore {
 			fmt.Println("Restauration du projet...")
 			
@@ -57,8 +76,224 @@ var backupCmd = &cobra.Command{
 	},
 }
 
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lister les sauvegardes du dépôt de backups",
+	Long: `Liste les sauvegardes du dépôt de backups, les plus récentes en premier, avec les
+informations de leur manifeste (moteur, source, compression, version de l'outil, SHA-256).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := backend.ListBackupCatalog(cmd.Context(), catalogFilter)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("Aucune sauvegarde.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tMOTEUR\tSERVEUR\tBASE\tTAILLE\tCOMPRESSION\tCLASSE\tOUTIL\tSHA-256\tCLÉ")
+		for _, entry := range entries {
+			b := entry.Backup
+			sum, tool := "-", "-"
+			if entry.HasManifest {
+				sum, tool = b.SHA256, b.ToolVersion
+				if len(sum) > 12 {
+					sum = sum[:12]
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				b.CreatedAt.Local().Format("2006-01-02 15:04"), b.Engine, b.Server, orDash(b.Database),
+				backend.FormatBytes(b.Size), b.Compression, entry.StorageClass, tool, sum, b.Key)
+		}
+		return w.Flush()
+	},
+}
+
+var backupVerifyCmd = &cobra.Command{
+	Use:   "verify <clé>",
+	Short: "Vérifier qu'une sauvegarde du dépôt est restaurable",
+	Long: `Lit la sauvegarde en flux depuis le dépôt de backups, compare son empreinte SHA-256 à
+celle de son manifeste et la décompresse entièrement. Avec --restore-server, la sauvegarde est
+aussi restaurée dans une base jetable de ce serveur, qui est supprimée après le comptage des
+enregistrements de chaque table. Le rapport est enregistré sous <clé>.verify.json.
+
+La clé peut aussi être donnée sous la forme s3://bucket/clé.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := backend.VerifyBackup(cmd.Context(), args[0], verifyOptions)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CONTRÔLE\tRÉSULTAT\tDÉTAIL")
+		for _, check := range report.Checks {
+			result := "échec"
+			switch {
+			case check.Skipped:
+				result = "ignoré"
+			case check.Passed:
+				result = "ok"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, result, check.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if len(report.Tables) > 0 {
+			fmt.Println()
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TABLE\tENREGISTREMENTS")
+			for _, table := range report.Tables {
+				fmt.Fprintf(w, "%s\t%d\n", table.Name, table.Rows)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if !report.Passed {
+			return fmt.Errorf("la sauvegarde %s n'a pas passé la vérification", report.Key)
+		}
+		fmt.Println("\nSauvegarde vérifiée avec succès!")
+		return nil
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <clé>",
+	Short: "Restaurer une sauvegarde du dépôt sur un serveur enregistré",
+	Long: `Restaure une sauvegarde Postgres, MySQL ou MongoDB du dépôt de backups (S3, ou dossier
+backup.repository) sur un serveur enregistré. Sans --database, la sauvegarde est restaurée dans sa
+base d'origine.
+
+La clé peut aussi être donnée sous la forme s3://bucket/clé.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if restoreOpts.Server == "" {
+			return fmt.Errorf("le serveur est requis (--server)")
+		}
+		if err := backend.RestoreRepositoryBackup(cmd.Context(), args[0], restoreOpts); err != nil {
+			return err
+		}
+		fmt.Println("\nRestauration terminée avec succès!")
+		return nil
+	},
+}
+
+var backupThawCmd = &cobra.Command{
+	Use:   "thaw <clé>",
+	Short: "Restaurer une sauvegarde archivée en stockage froid",
+	Long: `Demande la restauration d'une sauvegarde archivée (GLACIER, DEEP_ARCHIVE) du dépôt de
+backups. Une copie temporaire est rendue disponible après quelques minutes (Expedited) à
+quelques heures (Standard, Bulk) ; avec --wait, la commande attend qu'elle soit lisible.
+
+La clé peut aussi être donnée sous la forme s3://bucket/clé.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := backend.ThawBackup(cmd.Context(), args[0], thawOptions)
+		if err != nil {
+			return err
+		}
+		switch {
+		case status.Retrievable:
+			fmt.Printf("%s est disponible (%s)", status.Key, status.StorageClass)
+			if status.RestoredUntil != nil {
+				fmt.Printf(", copie restaurée jusqu'au %s", status.RestoredUntil.Local().Format("2006-01-02 15:04"))
+			}
+			fmt.Println()
+		default:
+			fmt.Printf("Restauration de %s en cours, relancez avec --wait pour attendre sa fin.\n", status.Key)
+		}
+		return nil
+	},
+}
+
+var backupKeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Gérer les clés de chiffrement des sauvegardes",
+	Long: `Les clés de chiffrement (AES-256-GCM) sont conservées dans le coffre sous backup-key/<id>.
+Avec backup.encryptionKey (ou AIDALINFO_BACKUP_ENCRYPTION_KEY) égal à l'identifiant d'une clé, les
+sauvegardes envoyées vers le dépôt sont chiffrées avant l'envoi ; les restaurations les déchiffrent
+automatiquement. Exportez chaque clé et conservez-la hors du poste : sans elle, les sauvegardes
+chiffrées sont illisibles.`,
+}
+
+var backupKeyCreateCmd = &cobra.Command{
+	Use:   "create [id]",
+	Short: "Générer une clé de chiffrement dans le coffre",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := ""
+		if len(args) == 1 {
+			id = args[0]
+		}
+		id, err := backend.CreateBackupKey(id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Clé '%s' créée. Activez-la avec backup.encryptionKey: %s\n", id, id)
+		return nil
+	},
+}
+
+var backupKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lister les clés de chiffrement du coffre",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := backend.ListBackupKeys()
+		if err != nil {
+			return err
+		}
+		active := cfg.Backup.EncryptionKey
+		for _, id := range ids {
+			if id == active {
+				fmt.Printf("%s (active)\n", id)
+			} else {
+				fmt.Println(id)
+			}
+		}
+		return nil
+	},
+}
+
+var backupKeyExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Afficher une clé (base64) pour la conserver hors du poste",
+	Long: `Affiche la clé en base64. Pour la réimporter sur un autre poste :
+  echo <clé> | aidalinfo-cli vault set backup-key/<id>`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := backend.ExportBackupKey(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	},
+}
+
+// orDash remplace une valeur vide par "-" dans les tableaux
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func init() {
 	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupListCmd, backupVerifyCmd, backupRestoreCmd, backupThawCmd, backupKeyCmd)
+	backupKeyCmd.AddCommand(backupKeyCreateCmd, backupKeyListCmd, backupKeyExportCmd)
+	backupListCmd.Flags().StringVar(&catalogFilter.Engine, "engine", "", "Filtrer par moteur (mongo, mysql, postgres, project, s3)")
+	backupListCmd.Flags().StringVar(&catalogFilter.Server, "server", "", "Filtrer par serveur source")
+	backupListCmd.Flags().StringVar(&catalogFilter.Database, "database", "", "Filtrer par base de données")
+	backupThawCmd.Flags().IntVar(&thawOptions.Days, "days", 7, "Durée de conservation de la copie restaurée (jours)")
+	backupThawCmd.Flags().StringVar(&thawOptions.Tier, "tier", "Standard", "Niveau de restauration : Expedited, Standard ou Bulk")
+	backupThawCmd.Flags().BoolVar(&thawOptions.Wait, "wait", false, "Attendre que la sauvegarde soit disponible")
+	backupThawCmd.Flags().DurationVar(&thawOptions.PollInterval, "interval", 5*time.Minute, "Intervalle de vérification pendant l'attente")
+	backupRestoreCmd.Flags().StringVar(&restoreOpts.Server, "server", "", "Serveur (ID ou nom) qui reçoit la sauvegarde")
+	backupRestoreCmd.Flags().StringVar(&restoreOpts.Database, "database", "", "Base de destination (par défaut la base d'origine)")
+	backupVerifyCmd.Flags().StringVar(&verifyOptions.RestoreServer, "restore-server", "", "Serveur (ID ou nom) où restaurer la sauvegarde dans une base jetable")
 	backupCmd.Flags().StringVar(&backupType, "type", "", "Type de sauvegarde (s3 ou local)")
 	backupCmd.Flags().StringVar(&s3Bucket, "s3-bucket", "", "Nom du bucket S3")
 	backupCmd.Flags().StringVar(&localPath, "local-path", "", "Chemin local pour la sauvegarde")
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCleanAll bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Gérer le dossier de travail",
+	Long: `Le dossier de travail reçoit les dumps, archives et téléchargements temporaires. Il s'agit
+du sous-dossier aidalinfo-cli de backup.workDir (ou AIDALINFO_BACKUP_WORK_DIR) s'il est configuré,
+sinon de ~/.cache/aidalinfo-cli/work sous Linux (à côté des logs, qui ne sont jamais
+supprimés). Seul ce sous-dossier est nettoyé.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := backend.WorkDir()
+		if err != nil {
+			return err
+		}
+		fmt.Println(dir)
+		return nil
+	},
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Supprimer les fichiers temporaires abandonnés",
+	Long: `Supprime les fichiers temporaires laissés par des opérations interrompues : plus d'un jour
+pour les dumps et archives, plus de 7 jours pour les téléchargements qui pouvaient encore être
+repris. L'ancien dossier ~/Downloads/aidalinfo-cli-tmp est vidé. Avec --all, tout le dossier de
+travail est vidé : n'utilisez cette option que si aucune opération n'est en cours.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := backend.CleanWorkDir(cacheCleanAll)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s : %d élément(s) supprimé(s), %s libéré(s)\n", report.Dir, report.Removed, backend.FormatBytes(report.Freed))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	cacheCleanCmd.Flags().BoolVar(&cacheCleanAll, "all", false, "Vider tout le dossier de travail, téléchargements interrompus compris")
+}
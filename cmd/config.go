@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Afficher la configuration effective",
+	Long: `Affiche la configuration effective après fusion de la configuration utilisateur,
+du fichier .aidalinfo.yaml du projet et des variables d'environnement AIDALINFO_*.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if userPath, err := backend.UserConfigPath(); err == nil {
+			fmt.Printf("# Config utilisateur : %s\n", userPath)
+		}
+		fmt.Printf("# Config projet      : %s\n", filepath.Join(projectPath, backend.ProjectConfigFile))
+
+		out, err := cfg.Marshal()
+		if err != nil {
+			return fmt.Errorf("erreur sérialisation de la configuration: %w", err)
+		}
+		fmt.Print(out)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
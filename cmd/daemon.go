@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Exécuter les sauvegardes planifiées en continu",
+	Long: `Lance le planificateur : chaque sauvegarde de la section schedules de la configuration
+est exécutée selon son expression cron, envoyée vers le dépôt de backups S3, puis les anciennes
+sauvegardes sont supprimées selon sa politique de rétention. Ctrl+C (ou SIGTERM) arrête le démon.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schedules := make([]backend.ScheduleConfig, 0, len(cfg.Schedules))
+		for _, sc := range cfg.Schedules {
+			sc.Path = scheduleProjectPath(sc)
+			schedules = append(schedules, sc)
+		}
+		scheduler, err := backend.NewScheduler(schedules)
+		if err != nil {
+			return err
+		}
+		return scheduler.Run(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
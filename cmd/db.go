@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbServer   string
+	dbDatabase string
+	dbStats    bool
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Opérations sur les bases de données",
+	Long:  `Opérations sur les bases MongoDB, MySQL et PostgreSQL d'un serveur enregistré (--server).`,
+}
+
+var dbListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lister les bases d'un serveur",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := resolveServer(dbServer, backend.EngineMongo, backend.EngineMySQL, backend.EnginePostgres)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		if dbStats {
+			if server.Engine != backend.EngineMongo {
+				return fmt.Errorf("--stats n'est disponible que pour MongoDB")
+			}
+			stats, err := backend.ListMongoDatabaseStats(ctx, server.Host, server.Port, server.User, server.Password)
+			if err != nil {
+				return err
+			}
+			for _, db := range stats {
+				fmt.Printf("%-32s %10s %6d collection(s) %12d document(s)\n",
+					db.Name, backend.FormatBytes(db.SizeOnDisk), db.Collections, db.Documents)
+			}
+			return nil
+		}
+
+		var databases []string
+		switch server.Engine {
+		case backend.EngineMongo:
+			databases, err = backend.ListMongoDatabases(ctx, server.Host, server.Port, server.User, server.Password)
+		case backend.EngineMySQL:
+			databases, err = backend.ListMySQLDatabases(ctx, server.Host, server.Port, server.User, server.Password)
+		case backend.EnginePostgres:
+			databases, err = backend.ListPostgresDatabases(ctx, server.Host, server.Port, server.User, server.Password)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, database := range databases {
+			fmt.Println(database)
+		}
+		return nil
+	},
+}
+
+var dbTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Tester la connexion à un serveur",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := resolveServer(dbServer, backend.EngineMongo, backend.EngineMySQL)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		switch server.Engine {
+		case backend.EngineMongo:
+			err = backend.TestMongoConnection(ctx, server.Host, server.Port, server.User, server.Password)
+		case backend.EngineMySQL:
+			err = backend.TestMySQLConnection(ctx, server.Host, server.Port, server.User, server.Password)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Connexion à %s réussie\n", server.Name)
+		return nil
+	},
+}
+
+var dbDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Créer un dump local d'une base",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := resolveServer(dbServer, backend.EngineMongo, backend.EngineMySQL, backend.EnginePostgres)
+		if err != nil {
+			return err
+		}
+		if dbDatabase == "" {
+			return fmt.Errorf("la base est requise (--database)")
+		}
+
+		ctx := cmd.Context()
+		var dumpPath string
+		switch server.Engine {
+		case backend.EngineMongo:
+			dumpPath, err = backend.DumpMongoDatabase(ctx, server.Host, server.Port, server.User, server.Password, dbDatabase)
+		case backend.EngineMySQL:
+			dumpPath, err = backend.DumpMySQLDatabase(ctx, server.Host, server.Port, server.User, server.Password, dbDatabase)
+		case backend.EnginePostgres:
+			dumpPath, err = backend.DumpPostgresDatabase(ctx, server.Host, server.Port, server.User, server.Password, dbDatabase)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Dump créé: %s\n", dumpPath)
+		return nil
+	},
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Sauvegarder une base vers le dépôt de backups S3",
+	Long: `Exporte une base puis l'envoie vers le dépôt de backups S3 sous
+<prefix>/<moteur>/<serveur>/<base>/<horodatage>.<ext>. Le moteur, la version du serveur,
+la taille et l'empreinte SHA-256 sont enregistrés en métadonnées de l'objet.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := resolveServer(dbServer, backend.EngineMongo, backend.EngineMySQL, backend.EnginePostgres)
+		if err != nil {
+			return err
+		}
+		if dbDatabase == "" {
+			return fmt.Errorf("la base est requise (--database)")
+		}
+
+		result, err := backend.BackupDatabase(cmd.Context(), server, dbDatabase)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Sauvegarde : s3://%s/%s\n", result.Bucket, result.Key)
+		fmt.Printf("Taille     : %s\n", backend.FormatBytes(result.Size))
+		fmt.Printf("SHA-256    : %s\n", result.SHA256)
+		if result.EngineVersion != "" {
+			fmt.Printf("Version    : %s %s\n", result.Engine, result.EngineVersion)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbListCmd, dbTestCmd, dbDumpCmd, dbBackupCmd)
+	dbCmd.PersistentFlags().StringVar(&dbServer, "server", "", "Nom du serveur enregistré (voir 'server list')")
+	dbListCmd.Flags().BoolVar(&dbStats, "stats", false, "Afficher la taille, le nombre de collections et de documents (MongoDB)")
+	dbDumpCmd.Flags().StringVar(&dbDatabase, "database", "", "Base de données à exporter")
+	dbBackupCmd.Flags().StringVar(&dbDatabase, "database", "", "Base de données à sauvegarder")
+}
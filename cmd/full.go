@@ -15,12 +15,12 @@ var fullCmd = &cobra.Command{
 		fmt.Println("Installation complète en cours...")
 		
 		fmt.Println("1. Installation des submodules...")
-		if err := backend.SubmoduleAction(projectPath); err != nil {
+		if err := backend.SubmoduleAction(cmd.Context(), projectPath); err != nil {
 			return fmt.Errorf("erreur lors de l'installation des submodules: %w", err)
 		}
 		
 		fmt.Println("2. Installation des dépendances NPM...")
-		if err := backend.NpmAction(projectPath, true); err != nil {
+		if err := backend.NpmAction(cmd.Context(), projectPath, true); err != nil {
 			return fmt.Errorf("erreur lors de l'installation NPM: %w", err)
 		}
 		
@@ -19,17 +19,24 @@ var installCmd = &cobra.Command{
 		if branchArg != "" {
 			branches = strings.Fields(branchArg)
 			fmt.Printf("Installation des sous-modules avec les branches : %v\n", branches)
+		} else if len(cfg.Git.Branches) > 0 {
+			branches = cfg.Git.Branches
+			fmt.Printf("Installation des sous-modules avec les branches de la configuration : %v\n", branches)
 		} else {
 			fmt.Println("Installation des sous-modules avec les branches par défaut")
 		}
 
-		if err := backend.SubmoduleAction(projectPath, branches...); err != nil {
+		if err := backend.SubmoduleAction(cmd.Context(), projectPath, branches...); err != nil {
 			return fmt.Errorf("erreur lors de l'installation des submodules: %w", err)
 		}
 
-		if npmFlag {
+		runNpm := cfg.NpmInstallEnabled()
+		if cmd.Flags().Changed("npm") {
+			runNpm = npmFlag
+		}
+		if runNpm {
 			fmt.Println("Installation des dépendances NPM...")
-			if err := backend.NpmAction(projectPath, true); err != nil {
+			if err := backend.NpmAction(cmd.Context(), projectPath, true); err != nil {
 				return fmt.Errorf("erreur lors de l'installation NPM: %w", err)
 			}
 		}
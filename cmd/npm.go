@@ -13,7 +13,7 @@ var npmCmd = &cobra.Command{
 	Long:  `Installer toutes les dépendances NPM pour les submodules du projet.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("Installation des dépendances NPM...")
-		if err := backend.NpmAction(projectPath, true); err != nil {
+		if err := backend.NpmAction(cmd.Context(), projectPath, true); err != nil {
 			return fmt.Errorf("erreur lors de l'installation NPM: %w", err)
 		}
 		fmt.Println("Installation NPM terminée avec succès!")
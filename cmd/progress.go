@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressBar affiche les événements de progression du backend sur une ligne du terminal
+type progressBar struct {
+	mu    sync.Mutex
+	out   io.Writer
+	width int
+}
+
+func newProgressBar(out io.Writer) *progressBar {
+	return &progressBar{out: out, width: 30}
+}
+
+func (b *progressBar) update(ev backend.ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var bar, amount string
+	if ev.Percent >= 0 {
+		filled := int(ev.Percent / 100 * float64(b.width))
+		bar = strings.Repeat("=", filled) + strings.Repeat(" ", b.width-filled)
+		amount = fmt.Sprintf("%3.0f%% %s/%s", ev.Percent, backend.FormatBytes(ev.BytesDone), backend.FormatBytes(ev.BytesTotal))
+	} else {
+		// Taille inconnue : on affiche seulement le volume traité
+		bar = strings.Repeat("-", b.width)
+		amount = backend.FormatBytes(ev.BytesDone)
+	}
+
+	line := fmt.Sprintf("%-8s [%s] %s %s/s", ev.Phase, bar, amount, backend.FormatBytes(int64(ev.Rate)))
+	if ev.ETASeconds > 0 {
+		line += " ETA " + formatETA(ev.ETASeconds)
+	}
+	fmt.Fprintf(b.out, "\r\033[K%s", line)
+	if ev.Done {
+		fmt.Fprintln(b.out)
+	}
+}
+
+func formatETA(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"aidalinfo-copilot/backend"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -11,21 +15,77 @@ var (
 	projectPath string
 	branchArg   string
 	Version     = "1.0.0"
+	cfg         = backend.DefaultConfig()
+
+	verbose   bool
+	quiet     bool
+	logFormat string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "aidalinfo-cli",
 	Short: "Aidalinfo CLI - Outil de gestion des projets",
 	Long:  `Aidalinfo CLI est un outil pour gérer les sous-modules Git, installer les dépendances NPM et automatiser les tâches de développement.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(); err != nil {
+			return err
+		}
+
+		// Charge la config utilisateur + .aidalinfo.yaml du projet + variables d'environnement,
+		// les flags de chaque commande sont appliqués ensuite et restent prioritaires
+		loaded, err := backend.LoadConfig(projectPath)
+		if err != nil {
+			return fmt.Errorf("erreur chargement de la configuration: %w", err)
+		}
+		cfg = loaded
+		backend.SetConfig(cfg)
+		return nil
+	},
+}
+
+// setupLogging applique --verbose, --quiet et --log-format au logger du backend
+func setupLogging() error {
+	if verbose && quiet {
+		return fmt.Errorf("--verbose et --quiet sont incompatibles")
+	}
+	if logFormat != "text" && logFormat != "json" {
+		return fmt.Errorf("format de log inconnu: %s (text ou json)", logFormat)
+	}
+
+	opts := backend.LogOptions{Level: backend.LevelInfo, JSON: logFormat == "json", File: true}
+	switch {
+	case verbose:
+		opts.Level = backend.LevelDebug
+	case quiet:
+		opts.Level = backend.LevelWarn
+	}
+	if err := backend.SetupLogging(opts); err != nil {
+		backend.Log.Debug("Fichier de log indisponible", backend.F("error", err))
+	}
+
+	// Barre de progression seulement dans un terminal interactif et en mode texte
+	if !quiet && !opts.JSON && backend.IsTerminal(os.Stderr) {
+		backend.SetProgressListener(newProgressBar(os.Stderr).update)
+	}
+	return nil
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl+C (ou SIGTERM pour le démon) annule proprement l'opération en cours
+	// (les outils lancés sont tués)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Erreur: %v\n", err)
+		stop()
 		os.Exit(1)
 	}
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&projectPath, "path", ".", "Chemin du projet")
-}
\ No newline at end of file
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Afficher les messages de debug")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "N'afficher que les avertissements et les erreurs")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format des logs : text ou json")
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	s3CmdServer    string
+	s3CmdBucket    string
+	s3CmdPrefix    string
+	s3CmdOutput    string
+	s3CmdRecursive bool
+	s3CmdMethod    string
+	s3CmdExpires   time.Duration
+	syncOptions    backend.SyncOptions
+)
+
+var s3Cmd = &cobra.Command{
+	Use:   "s3",
+	Short: "Opérations sur les buckets S3",
+	Long:  `Navigation, snapshots et synchronisation des buckets des serveurs S3 enregistrés.`,
+}
+
+var s3SnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Copier un bucket dans une archive tar",
+	Long: `Copie tous les objets d'un bucket (ou ceux sous --prefix) dans une archive tar compressée,
+sous <bucket>/<clé> : le format attendu par la restauration S3 depuis un fichier local.
+
+Sans --output, l'archive est envoyée vers le dépôt de backups S3 sous
+<prefix>/s3/<serveur>/<bucket>/<horodatage>.tar.gz. Avec --output, elle est écrite localement ;
+la compression suit l'extension (.tar.gz, .tar.zst ou .tar).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := resolveServer(s3CmdServer, backend.EngineS3)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		if s3CmdOutput != "" {
+			output, err := filepath.Abs(s3CmdOutput)
+			if err != nil {
+				return fmt.Errorf("chemin de sortie invalide: %v", err)
+			}
+			archivePath, err := backend.SnapshotS3BucketWithCreds(ctx, server.S3Credentials(), s3CmdBucket, s3CmdPrefix, output)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Snapshot créé: %s\n", archivePath)
+			return nil
+		}
+
+		result, err := backend.BackupS3Bucket(ctx, server, s3CmdBucket, s3CmdPrefix)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Sauvegarde : s3://%s/%s\n", result.Bucket, result.Key)
+		fmt.Printf("Taille     : %s\n", backend.FormatBytes(result.Size))
+		fmt.Printf("SHA-256    : %s\n", result.SHA256)
+		return nil
+	},
+}
+
+var s3SyncCmd = &cobra.Command{
+	Use:   "sync <source> <destination>",
+	Short: "Synchroniser deux buckets ou un dossier et un bucket",
+	Long: `Copie vers la destination les objets absents ou différents de la source (taille, ETag, puis
+date de modification). Un emplacement est soit <serveur>:<bucket>[/<préfixe>] pour un serveur S3
+enregistré, soit un dossier local.
+
+Les motifs --include et --exclude (glob) portent sur la clé relative, ou sur le nom seul s'ils
+ne contiennent pas de "/". Avec --delete, les objets de la destination absents de la source sont
+supprimés. --dry-run affiche les changements sans les appliquer.`,
+	Example: `  aidalinfo-cli s3 sync scaleway-staging:medias minio-dev:medias --delete
+  aidalinfo-cli s3 sync ./uploads scaleway-prod:medias/uploads --exclude "*.tmp" --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, err := backend.ParseSyncEndpoint(args[0])
+		if err != nil {
+			return err
+		}
+		dst, err := backend.ParseSyncEndpoint(args[1])
+		if err != nil {
+			return err
+		}
+
+		report, err := backend.SyncS3(cmd.Context(), src, dst, syncOptions)
+		if report != nil {
+			symbols := map[string]string{backend.SyncCopy: "+", backend.SyncUpdate: "~", backend.SyncDelete: "-"}
+			for _, change := range report.Changes {
+				line := fmt.Sprintf("%s %s (%s)", symbols[change.Action], change.Key, backend.FormatBytes(change.Size))
+				if change.Reason != "" {
+					line += " : " + change.Reason
+				}
+				fmt.Println(line)
+			}
+			for _, change := range report.Skipped {
+				fmt.Printf("! %s : ignoré, %s\n", change.Key, change.Reason)
+			}
+			verb := "appliqué(s)"
+			if report.DryRun {
+				verb = "à appliquer (dry-run)"
+			}
+			fmt.Printf("%d changement(s) %s, %d objet(s) identique(s)\n", len(report.Changes), verb, report.Unchanged)
+		}
+		return err
+	},
+}
+
+// s3Target retourne les identifiants du serveur S3 (--server) avec le bucket de --bucket
+func s3Target() (backend.S3Credentials, error) {
+	server, err := resolveServer(s3CmdServer, backend.EngineS3)
+	if err != nil {
+		return backend.S3Credentials{}, err
+	}
+	creds := server.S3Credentials()
+	if s3CmdBucket != "" {
+		creds.Bucket = s3CmdBucket
+	}
+	return creds, nil
+}
+
+var s3ListCmd = &cobra.Command{
+	Use:   "ls [préfixe]",
+	Short: "Lister les objets d'un bucket",
+	Long: `Liste les objets et les dossiers placés directement sous le préfixe, ou tous les objets
+sous le préfixe avec --recursive.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := s3Target()
+		if err != nil {
+			return err
+		}
+		query := backend.BackupListQuery{Folders: !s3CmdRecursive, PageSize: 1000}
+		if len(args) == 1 {
+			query.Prefix = args[0]
+		}
+
+		var folders []string
+		var items []backend.BackupInfo
+		for {
+			page, err := backend.ListBackupsPage(cmd.Context(), creds, query)
+			if err != nil {
+				return err
+			}
+			folders = append(folders, page.Folders...)
+			items = append(items, page.Items...)
+			if page.NextToken == "" {
+				break
+			}
+			query.ContinuationToken = page.NextToken
+		}
+		sort.Strings(folders)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, folder := range folders {
+			fmt.Fprintf(w, "\t\tDOSSIER\t%s\n", folder)
+		}
+		for _, item := range items {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.LastModified.Local().Format("2006-01-02 15:04"), backend.FormatBytes(item.Size), item.StorageClass, item.Key)
+		}
+		return w.Flush()
+	},
+}
+
+var s3StatCmd = &cobra.Command{
+	Use:   "stat <clé>",
+	Short: "Afficher les métadonnées d'un objet",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := s3Target()
+		if err != nil {
+			return err
+		}
+		info, err := backend.StatS3Object(cmd.Context(), creds, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Objet      : s3://%s/%s\n", info.Bucket, info.Key)
+		fmt.Printf("Taille     : %s (%d octets)\n", backend.FormatBytes(info.Size), info.Size)
+		fmt.Printf("Modifié    : %s\n", info.LastModified.Local().Format(time.RFC3339))
+		fmt.Printf("ETag       : %s\n", info.ETag)
+		fmt.Printf("Type       : %s\n", orDash(info.ContentType))
+		fmt.Printf("Classe     : %s\n", info.StorageClass)
+		if info.Archive != nil {
+			state := "archivé, restauration nécessaire"
+			switch {
+			case info.Archive.RestoreInProgress:
+				state = "restauration en cours"
+			case info.Archive.RestoredUntil != nil:
+				state = "restauré jusqu'au " + info.Archive.RestoredUntil.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("Archive    : %s\n", state)
+		}
+		keys := make([]string, 0, len(info.Metadata))
+		for k := range info.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("x-amz-meta-%s: %s\n", k, info.Metadata[k])
+		}
+		return nil
+	},
+}
+
+var s3GetCmd = &cobra.Command{
+	Use:   "get <clé> [fichier]",
+	Short: "Télécharger un objet",
+	Long:  `Télécharge un objet (par plages parallèles, avec reprise après interruption) dans le fichier indiqué, ou sous son nom dans le dossier courant.`,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := s3Target()
+		if err != nil {
+			return err
+		}
+		dest := filepath.Base(args[0])
+		if len(args) == 2 {
+			dest = args[1]
+		}
+		if dest, err = filepath.Abs(dest); err != nil {
+			return fmt.Errorf("chemin de destination invalide: %v", err)
+		}
+		if err := backend.DownloadBackupWithCreds(cmd.Context(), creds, args[0], dest); err != nil {
+			return err
+		}
+		fmt.Printf("Téléchargé : %s\n", dest)
+		return nil
+	},
+}
+
+var s3PutCmd = &cobra.Command{
+	Use:   "put <fichier> [clé]",
+	Short: "Envoyer un fichier",
+	Long:  `Envoie un fichier local sous la clé indiquée. Une clé terminée par "/" (ou absente) reçoit le nom du fichier.`,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := s3Target()
+		if err != nil {
+			return err
+		}
+		key := ""
+		if len(args) == 2 {
+			key = args[1]
+		}
+		if key == "" || key[len(key)-1] == '/' {
+			key += filepath.Base(args[0])
+		}
+		return backend.UploadS3Object(cmd.Context(), creds, args[0], key)
+	},
+}
+
+var s3RemoveCmd = &cobra.Command{
+	Use:   "rm <clé>...",
+	Short: "Supprimer des objets",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := s3Target()
+		if err != nil {
+			return err
+		}
+		for _, key := range args {
+			if err := backend.DeleteS3Object(cmd.Context(), creds, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var s3PresignCmd = &cobra.Command{
+	Use:   "presign <clé>",
+	Short: "Générer un lien temporaire vers un objet",
+	Long: `Génère un lien présigné de téléchargement (GET) ou d'envoi (PUT, par exemple
+curl -T fichier "<lien>"), valable --expires (7 jours au plus).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		creds, err := s3Target()
+		if err != nil {
+			return err
+		}
+		presigned, err := backend.PresignS3Object(cmd.Context(), creds, args[0], s3CmdMethod, s3CmdExpires)
+		if err != nil {
+			return err
+		}
+		fmt.Println(presigned.URL)
+		fmt.Fprintf(os.Stderr, "%s valable jusqu'au %s\n", presigned.Method, presigned.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(s3Cmd)
+	s3Cmd.AddCommand(s3ListCmd, s3StatCmd, s3GetCmd, s3PutCmd, s3RemoveCmd, s3PresignCmd, s3SnapshotCmd, s3SyncCmd)
+	for _, c := range []*cobra.Command{s3ListCmd, s3StatCmd, s3GetCmd, s3PutCmd, s3RemoveCmd, s3PresignCmd, s3SnapshotCmd} {
+		c.Flags().StringVar(&s3CmdServer, "server", "", "Nom du serveur S3 enregistré (voir 'server list')")
+		c.Flags().StringVar(&s3CmdBucket, "bucket", "", "Bucket (par défaut celui du serveur)")
+	}
+	s3ListCmd.Flags().BoolVarP(&s3CmdRecursive, "recursive", "r", false, "Lister tous les objets sous le préfixe, sous-dossiers compris")
+	s3PresignCmd.Flags().StringVar(&s3CmdMethod, "method", "GET", "GET (téléchargement) ou PUT (envoi)")
+	s3PresignCmd.Flags().DurationVar(&s3CmdExpires, "expires", time.Hour, "Durée de validité du lien")
+	s3SnapshotCmd.Flags().StringVar(&s3CmdPrefix, "prefix", "", "Ne copier que les objets sous ce préfixe")
+	s3SnapshotCmd.Flags().StringVarP(&s3CmdOutput, "output", "o", "", "Écrire l'archive dans ce fichier au lieu du dépôt de backups")
+
+	s3SyncCmd.Flags().BoolVar(&syncOptions.Delete, "delete", false, "Supprimer de la destination les objets absents de la source")
+	s3SyncCmd.Flags().BoolVar(&syncOptions.DryRun, "dry-run", false, "Afficher les changements sans les appliquer")
+	s3SyncCmd.Flags().StringSliceVar(&syncOptions.Include, "include", nil, "Ne synchroniser que les clés correspondant à ces motifs")
+	s3SyncCmd.Flags().StringSliceVar(&syncOptions.Exclude, "exclude", nil, "Ignorer les clés correspondant à ces motifs")
+}
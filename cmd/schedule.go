@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Gérer les sauvegardes planifiées",
+	Long: `Affiche et exécute les sauvegardes planifiées de la section schedules de la configuration.
+Les sauvegardes sont exécutées automatiquement par 'aidalinfo-cli daemon'.`,
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lister les sauvegardes planifiées",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(cfg.Schedules) == 0 {
+			fmt.Println("Aucune sauvegarde planifiée.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NOM\tTYPE\tSOURCE\tCRON\tPROCHAINE\tRÉTENTION")
+		for _, sc := range cfg.Schedules {
+			next := "-"
+			if t, err := backend.NextScheduleRun(sc, time.Now()); err == nil {
+				next = t.Format("2006-01-02 15:04")
+			}
+			source := sc.Server
+			if sc.Database != "" {
+				source += "/" + sc.Database
+			}
+			if sc.Type == backend.BackupKindProject {
+				source = scheduleProjectPath(sc)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", sc.Name, sc.Type, source, sc.Cron, next, sc.Retention)
+		}
+		return w.Flush()
+	},
+}
+
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run <nom>",
+	Short: "Exécuter immédiatement une sauvegarde planifiée",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sc, err := backend.FindSchedule(args[0])
+		if err != nil {
+			return err
+		}
+		sc.Path = scheduleProjectPath(sc)
+		return backend.RunScheduledBackup(cmd.Context(), sc)
+	},
+}
+
+// scheduleProjectPath retourne le dossier archivé par une sauvegarde project (par défaut --path)
+func scheduleProjectPath(sc backend.ScheduleConfig) string {
+	if sc.Path != "" {
+		return sc.Path
+	}
+	return projectPath
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleListCmd, scheduleRunCmd)
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serverEngine   string
+	serverProfile  backend.ServerProfile
+	serverDefault  bool
+	serverPassword bool
+)
+
+var serverCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Gérer les serveurs enregistrés",
+	Long:  `Gère les profils de serveurs MongoDB, MySQL, PostgreSQL et S3 partagés entre le GUI et la CLI.`,
+}
+
+var serverListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lister les serveurs enregistrés",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := backend.ListServerProfiles(serverEngine)
+		if err != nil {
+			return err
+		}
+		if len(profiles) == 0 {
+			fmt.Println("Aucun serveur enregistré.")
+			return nil
+		}
+		for _, p := range profiles {
+			marker := " "
+			if p.IsDefault {
+				marker = "*"
+			}
+			fmt.Printf("%s %-8s %-24s %s\n", marker, p.Engine, p.Name, p.Address())
+		}
+		return nil
+	},
+}
+
+var serverShowCmd = &cobra.Command{
+	Use:   "show <nom>",
+	Short: "Afficher un serveur",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := backend.GetServerProfile(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("ID       : %s\n", p.ID)
+		fmt.Printf("Nom      : %s\n", p.Name)
+		fmt.Printf("Moteur   : %s\n", p.Engine)
+		fmt.Printf("Hôte     : %s\n", p.Address())
+		if p.User != "" {
+			fmt.Printf("User     : %s\n", p.User)
+		}
+		if p.Engine == backend.EngineS3 {
+			fmt.Printf("Région   : %s\n", p.Region)
+			fmt.Printf("Bucket   : %s\n", p.Bucket)
+			fmt.Printf("HTTPS    : %t\n", p.UseHttps)
+		}
+		fmt.Printf("Défaut   : %t\n", p.IsDefault)
+		return nil
+	},
+}
+
+var serverAddCmd = &cobra.Command{
+	Use:   "add <nom>",
+	Short: "Enregistrer un serveur",
+	Long: `Enregistre un nouveau serveur. Avec --password-stdin, le mot de passe (ou la secret key S3)
+est lu sur l'entrée standard pour ne pas apparaître dans l'historique du shell.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := serverProfile
+		p.Name = args[0]
+		p.Engine = serverEngine
+		p.IsDefault = serverDefault
+		if serverPassword {
+			secret, err := readSecretFromStdin()
+			if err != nil {
+				return err
+			}
+			if strings.EqualFold(p.Engine, backend.EngineS3) {
+				p.SecretKey = secret
+			} else {
+				p.Password = secret
+			}
+		}
+		saved, err := backend.SaveServerProfile(p)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Serveur '%s' enregistré (%s)\n", saved.Name, saved.ID)
+		return nil
+	},
+}
+
+var serverRemoveCmd = &cobra.Command{
+	Use:   "rm <nom>",
+	Short: "Supprimer un serveur",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := backend.DeleteServerProfile(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Serveur '%s' supprimé\n", args[0])
+		return nil
+	},
+}
+
+var serverImportCmd = &cobra.Command{
+	Use:   "import <moteur> <fichier.json>",
+	Short: "Importer des serveurs exportés depuis le GUI",
+	Long:  `Importe un export JSON des anciens serveurs du GUI (mongo, mysql, postgres ou s3).`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("erreur lecture de %s: %w", args[1], err)
+		}
+		count, err := backend.ImportLocalStorageProfiles(args[0], string(data))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d serveur(s) importé(s)\n", count)
+		return nil
+	},
+}
+
+// readSecretFromStdin lit la première ligne de l'entrée standard
+func readSecretFromStdin() (string, error) {
+	var secret string
+	if _, err := fmt.Fscanln(os.Stdin, &secret); err != nil {
+		return "", fmt.Errorf("erreur lecture du secret sur l'entrée standard: %w", err)
+	}
+	return strings.TrimSpace(secret), nil
+}
+
+// resolveServer récupère un serveur enregistré et vérifie son moteur si engines est renseigné
+func resolveServer(ref string, engines ...string) (*backend.ServerProfile, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("le serveur est requis (--server)")
+	}
+	p, err := backend.GetServerProfile(ref)
+	if err != nil {
+		return nil, err
+	}
+	if len(engines) == 0 {
+		return p, nil
+	}
+	for _, engine := range engines {
+		if p.Engine == engine {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("le serveur '%s' est de type %s (attendu: %s)", ref, p.Engine, strings.Join(engines, ", "))
+}
+
+func init() {
+	rootCmd.AddCommand(serverCmd)
+	serverCmd.AddCommand(serverListCmd, serverShowCmd, serverAddCmd, serverRemoveCmd, serverImportCmd)
+
+	serverListCmd.Flags().StringVar(&serverEngine, "engine", "", "Filtrer par moteur (mongo, mysql, postgres, s3)")
+
+	serverAddCmd.Flags().StringVar(&serverEngine, "engine", "", "Moteur du serveur (mongo, mysql, postgres, s3)")
+	serverAddCmd.Flags().StringVar(&serverProfile.Host, "host", "localhost", "Hôte du serveur (ou URI mongodb:// / mongodb+srv:// pour MongoDB)")
+	serverAddCmd.Flags().StringVar(&serverProfile.Port, "port", "", "Port du serveur")
+	serverAddCmd.Flags().StringVar(&serverProfile.User, "user", "", "Utilisateur")
+	serverAddCmd.Flags().StringVar(&serverProfile.AuthDatabase, "auth-database", "", "Base d'authentification")
+	serverAddCmd.Flags().StringVar(&serverProfile.AccessKey, "access-key", "", "Access key S3")
+	serverAddCmd.Flags().StringVar(&serverProfile.Region, "region", "", "Région S3")
+	serverAddCmd.Flags().StringVar(&serverProfile.Bucket, "bucket", "", "Bucket S3")
+	serverAddCmd.Flags().BoolVar(&serverProfile.UseHttps, "https", false, "Utiliser HTTPS (S3)")
+	serverAddCmd.Flags().BoolVar(&serverDefault, "default", false, "Définir comme serveur par défaut")
+	serverAddCmd.Flags().BoolVar(&serverPassword, "password-stdin", false, "Lire le mot de passe sur l'entrée standard")
+	serverAddCmd.MarkFlagRequired("engine")
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"aidalinfo-copilot/backend"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Gérer le coffre de secrets",
+	Long: `Gère le coffre chiffré qui contient les mots de passe des bases et les secret keys S3.
+Le coffre est protégé par le trousseau du système, ou par une passphrase fournie via
+AIDALINFO_VAULT_PASSPHRASE. AIDALINFO_KEYRING=file utilise un trousseau fichier (tests, CI).`,
+}
+
+var vaultStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Afficher l'état du coffre",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vault, err := backend.DefaultVault()
+		if err != nil {
+			return err
+		}
+		status := vault.Status()
+		if !status.Exists {
+			fmt.Println("Coffre non initialisé (créé au premier secret enregistré).")
+			return nil
+		}
+		fmt.Printf("Protection : %s\n", status.Protection)
+		if status.Unlocked {
+			fmt.Printf("État       : déverrouillé (%d secret(s))\n", status.Entries)
+		} else {
+			fmt.Println("État       : verrouillé")
+		}
+		return nil
+	},
+}
+
+var vaultListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lister les références des secrets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vault, err := backend.DefaultVault()
+		if err != nil {
+			return err
+		}
+		refs, err := vault.List()
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			fmt.Println(ref)
+		}
+		return nil
+	},
+}
+
+var vaultSetCmd = &cobra.Command{
+	Use:   "set <référence>",
+	Short: "Enregistrer un secret (lu sur l'entrée standard)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secret, err := readSecretFromStdin()
+		if err != nil {
+			return err
+		}
+		vault, err := backend.DefaultVault()
+		if err != nil {
+			return err
+		}
+		if err := vault.Set(args[0], secret); err != nil {
+			return err
+		}
+		fmt.Printf("Secret '%s' enregistré\n", args[0])
+		return nil
+	},
+}
+
+var vaultRemoveCmd = &cobra.Command{
+	Use:   "rm <référence>",
+	Short: "Supprimer un secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vault, err := backend.DefaultVault()
+		if err != nil {
+			return err
+		}
+		if err := vault.Delete(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Secret '%s' supprimé\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vaultCmd)
+	vaultCmd.AddCommand(vaultStatusCmd, vaultListCmd, vaultSetCmd, vaultRemoveCmd)
+}
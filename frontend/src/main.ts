@@ -1,8 +1,13 @@
 import { createApp } from "vue";
 import App from "./App.vue";
 import router from "./router";
+import { initServerProfiles } from "./utils/serverProfiles";
 import "./index.css";
 
 const app = createApp(App);
 app.use(router);
-app.mount("#app");
+
+// Les profils serveurs sont stockés côté Go : on les charge (et migre le localStorage) avant le montage
+initServerProfiles().finally(() => {
+  app.mount("#app");
+});
@@ -1,3 +1,5 @@
+import { getCachedProfiles, setCachedProfiles, ServerEngine } from './serverProfiles';
+
 export interface MongoServer {
   id: string;
   name: string;
@@ -11,7 +13,7 @@ export interface MongoServer {
   updatedAt: string;
 }
 
-const STORAGE_KEY = 'mongoServers';
+const ENGINE: ServerEngine = 'mongo';
 const OLD_STORAGE_KEY = 'mongodb';
 
 export class MongoServersManager {
@@ -19,17 +21,13 @@ export class MongoServersManager {
    * Récupère tous les serveurs MongoDB stockés
    */
   static getServers(): MongoServer[] {
-    const stored = localStorage.getItem(STORAGE_KEY);
+    const stored = getCachedProfiles<MongoServer>(ENGINE);
     if (!stored) {
       // Migration des anciennes données
       this.migrateOldData();
       return this.getServers();
     }
-    try {
-      return JSON.parse(stored);
-    } catch {
-      return [];
-    }
+    return stored;
   }
 
   /**
@@ -239,10 +237,10 @@ export class MongoServersManager {
   }
 
   /**
-   * Sauvegarde les serveurs dans le localStorage
+   * Sauvegarde les serveurs dans le store de profils Go
    */
   private static saveServers(servers: MongoServer[]): void {
-    localStorage.setItem(STORAGE_KEY, JSON.stringify(servers));
+    setCachedProfiles(ENGINE, servers);
   }
 
   /**
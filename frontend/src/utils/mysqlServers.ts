@@ -1,4 +1,5 @@
 import { TestMySQLConnection } from '../../wailsjs/go/main/App';
+import { getCachedProfiles, setCachedProfiles, ServerEngine } from './serverProfiles';
 
 export interface MySQLServer {
   id: string;
@@ -13,7 +14,7 @@ export interface MySQLServer {
   updatedAt: string;
 }
 
-const STORAGE_KEY = 'mysqlServers';
+const ENGINE: ServerEngine = 'mysql';
 const OLD_STORAGE_KEY = 'mysql';
 
 export class MySQLServersManager {
@@ -21,17 +22,13 @@ export class MySQLServersManager {
    * Récupère tous les serveurs MySQL stockés
    */
   static getServers(): MySQLServer[] {
-    const stored = localStorage.getItem(STORAGE_KEY);
+    const stored = getCachedProfiles<MySQLServer>(ENGINE);
     if (!stored) {
       // Migration des anciennes données
       this.migrateOldData();
       return this.getServers();
     }
-    try {
-      return JSON.parse(stored);
-    } catch {
-      return [];
-    }
+    return stored;
   }
 
   /**
@@ -246,10 +243,10 @@ export class MySQLServersManager {
   }
 
   /**
-   * Sauvegarde les serveurs dans le localStorage
+   * Sauvegarde les serveurs dans le store de profils Go
    */
   private static saveServers(servers: MySQLServer[]): void {
-    localStorage.setItem(STORAGE_KEY, JSON.stringify(servers));
+    setCachedProfiles(ENGINE, servers);
   }
 
   /**
@@ -1,3 +1,5 @@
+import { getCachedProfiles, setCachedProfiles, ServerEngine } from './serverProfiles';
+
 export interface PostgresServer {
   id: string;
   name: string;
@@ -11,7 +13,7 @@ export interface PostgresServer {
   updatedAt?: string;
 }
 
-const STORAGE_KEY = 'postgresServers';
+const ENGINE: ServerEngine = 'postgres';
 const OLD_STORAGE_KEY = 'postgresql';
 
 export class PostgresServersManager {
@@ -19,17 +21,13 @@ export class PostgresServersManager {
    * Récupère tous les serveurs PostgreSQL stockés
    */
   static getServers(): PostgresServer[] {
-    const stored = localStorage.getItem(STORAGE_KEY);
+    const stored = getCachedProfiles<PostgresServer>(ENGINE);
     if (!stored) {
       // Migration des anciennes données
       this.migrateOldData();
       return this.getServers();
     }
-    try {
-      return JSON.parse(stored);
-    } catch {
-      return [];
-    }
+    return stored;
   }
 
   /**
@@ -242,10 +240,10 @@ export class PostgresServersManager {
   }
 
   /**
-   * Sauvegarde les serveurs dans le localStorage
+   * Sauvegarde les serveurs dans le store de profils Go
    */
   private static saveServers(servers: PostgresServer[]): void {
-    localStorage.setItem(STORAGE_KEY, JSON.stringify(servers));
+    setCachedProfiles(ENGINE, servers);
   }
 
   /**
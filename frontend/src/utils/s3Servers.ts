@@ -1,3 +1,11 @@
+import {
+  getCachedProfiles,
+  setCachedProfiles,
+  getCachedBackupRepositoryId,
+  setCachedBackupRepositoryId,
+  ServerEngine
+} from './serverProfiles';
+
 export interface S3Server {
   id: string;
   name: string;
@@ -13,21 +21,16 @@ export interface S3Server {
   updatedAt: string;
 }
 
-const STORAGE_KEY = 's3Servers';
-const BACKUP_REPOSITORY_SERVER_KEY = 'backupRepositoryServerId';
+const ENGINE: ServerEngine = 's3';
 
 export class S3ServersManager {
   static getServers(): S3Server[] {
-    const stored = localStorage.getItem(STORAGE_KEY);
+    const stored = getCachedProfiles<S3Server>(ENGINE);
     if (!stored) {
       this.migrateOldData();
       return this.getServers();
     }
-    try {
-      return JSON.parse(stored);
-    } catch {
-      return [];
-    }
+    return stored;
   }
 
   static getServer(id: string): S3Server | undefined {
@@ -223,7 +226,7 @@ export class S3ServersManager {
   }
 
   private static saveServers(servers: S3Server[]): void {
-    localStorage.setItem(STORAGE_KEY, JSON.stringify(servers));
+    setCachedProfiles(ENGINE, servers);
   }
 
   private static generateId(): string {
@@ -232,15 +235,15 @@ export class S3ServersManager {
 }
 
 export function getBackupRepositoryServerId(): string | null {
-  return localStorage.getItem(BACKUP_REPOSITORY_SERVER_KEY);
+  return getCachedBackupRepositoryId();
 }
 
 export function setBackupRepositoryServerId(id: string): void {
-  localStorage.setItem(BACKUP_REPOSITORY_SERVER_KEY, id);
+  setCachedBackupRepositoryId(id);
 }
 
 export function clearBackupRepositoryServerId(): void {
-  localStorage.removeItem(BACKUP_REPOSITORY_SERVER_KEY);
+  setCachedBackupRepositoryId(null);
 }
 
 export function getBackupRepositoryServer(): S3Server | undefined {
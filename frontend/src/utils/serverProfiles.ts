@@ -0,0 +1,86 @@
+import {
+  ListServerProfiles,
+  ReplaceServerProfiles,
+  ImportLocalStorageProfiles,
+  GetBackupRepositoryServerID,
+  SetBackupRepositoryServer
+} from '../../wailsjs/go/main/App';
+
+export type ServerEngine = 'mongo' | 'mysql' | 'postgres' | 's3';
+
+// Anciennes clés localStorage migrées vers le store de profils Go
+const LEGACY_STORAGE_KEYS: Record<ServerEngine, string> = {
+  mongo: 'mongoServers',
+  mysql: 'mysqlServers',
+  postgres: 'postgresServers',
+  s3: 's3Servers'
+};
+const LEGACY_BACKUP_REPOSITORY_KEY = 'backupRepositoryServerId';
+
+const cache: Record<ServerEngine, any[] | null> = {
+  mongo: null,
+  mysql: null,
+  postgres: null,
+  s3: null
+};
+let backupRepositoryId: string | null = null;
+
+/**
+ * Charge les profils depuis le backend Go et migre les données localStorage existantes.
+ * À appeler avant le montage de l'application.
+ */
+export async function initServerProfiles(): Promise<void> {
+  for (const engine of Object.keys(LEGACY_STORAGE_KEYS) as ServerEngine[]) {
+    const legacyKey = LEGACY_STORAGE_KEYS[engine];
+    const legacy = localStorage.getItem(legacyKey);
+    try {
+      if (legacy) {
+        await ImportLocalStorageProfiles(engine, legacy);
+        localStorage.removeItem(legacyKey);
+      }
+      const profiles = await ListServerProfiles(engine);
+      cache[engine] = profiles.length > 0 ? profiles : null;
+    } catch (error) {
+      console.error(`Chargement des serveurs ${engine} impossible:`, error);
+    }
+  }
+
+  try {
+    const legacyRepository = localStorage.getItem(LEGACY_BACKUP_REPOSITORY_KEY);
+    if (legacyRepository) {
+      await SetBackupRepositoryServer(legacyRepository);
+      localStorage.removeItem(LEGACY_BACKUP_REPOSITORY_KEY);
+    }
+    backupRepositoryId = (await GetBackupRepositoryServerID()) || null;
+  } catch (error) {
+    console.error('Chargement du dépôt de backups impossible:', error);
+  }
+}
+
+/**
+ * Retourne les profils en cache pour un moteur (null si aucun profil n'a encore été enregistré)
+ */
+export function getCachedProfiles<T>(engine: ServerEngine): T[] | null {
+  return cache[engine] as T[] | null;
+}
+
+/**
+ * Met à jour le cache et persiste les profils côté Go
+ */
+export function setCachedProfiles<T>(engine: ServerEngine, servers: T[]): void {
+  cache[engine] = servers;
+  ReplaceServerProfiles(engine, servers as any).catch(error => {
+    console.error(`Sauvegarde des serveurs ${engine} impossible:`, error);
+  });
+}
+
+export function getCachedBackupRepositoryId(): string | null {
+  return backupRepositoryId;
+}
+
+export function setCachedBackupRepositoryId(id: string | null): void {
+  backupRepositoryId = id;
+  SetBackupRepositoryServer(id || '').catch(error => {
+    console.error('Sauvegarde du dépôt de backups impossible:', error);
+  });
+}
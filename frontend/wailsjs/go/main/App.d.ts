@@ -2,14 +2,28 @@
 // This file is automatically generated. DO NOT EDIT
 import {backend} from '../models';
 
+export function BackupDatabase(arg1:string,arg2:string):Promise<backend.BackupResult>;
+
+export function BackupS3Bucket(arg1:string,arg2:string,arg3:string):Promise<backend.BackupResult>;
+
+export function CancelJob(arg1:string):Promise<void>;
+
 export function ChangeBranch(arg1:string,arg2:string):Promise<void>;
 
 export function CheckForUpdates():Promise<backend.UpdateInfo>;
 
 export function CleanSubmodules(arg1:Array<string>):Promise<Array<string>>;
 
+export function CleanWorkDir(arg1:boolean):Promise<backend.CacheCleanReport>;
+
+export function CreateBackupKey(arg1:string):Promise<string>;
+
 export function CreateTag(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function DeleteS3Object(arg1:backend.S3Credentials,arg2:string):Promise<void>;
+
+export function DeleteServerProfile(arg1:string):Promise<void>;
+
 export function DownloadBackupWithCreds(arg1:backend.S3Credentials,arg2:string,arg3:string):Promise<void>;
 
 export function DumpMongoDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<string>;
@@ -18,6 +32,10 @@ export function DumpMySQLDatabase(arg1:string,arg2:string,arg3:string,arg4:strin
 
 export function DumpPostgresDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<string>;
 
+export function GetArchiveStatusWithCreds(arg1:backend.S3Credentials,arg2:string):Promise<backend.ArchiveStatus>;
+
+export function GetBackupRepositoryServerID():Promise<string>;
+
 export function GetBranches(arg1:string):Promise<Array<string>>;
 
 export function GetCurrentBranch(arg1:string):Promise<string>;
@@ -34,22 +52,38 @@ export function GetLastTags(arg1:string):Promise<backend.TagsResult>;
 
 export function GetPendingChanges(arg1:string):Promise<string>;
 
+export function GetVaultStatus():Promise<backend.VaultStatus>;
+
 export function GitStatus(arg1:string):Promise<string>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportLocalStorageProfiles(arg1:string,arg2:string):Promise<number>;
+
 export function InstallNpmDependencies(arg1:string,arg2:boolean):Promise<void>;
 
 export function InstallSubmodules(arg1:string,arg2:Array<string>):Promise<void>;
 
+export function ListBackupCatalog(arg1:backend.CatalogFilter):Promise<Array<backend.CatalogEntry>>;
+
+export function ListBackupKeys():Promise<Array<string>>;
+
+export function ListBackupsPage(arg1:backend.S3Credentials,arg2:backend.BackupListQuery):Promise<backend.BackupPage>;
+
 export function ListBackupsWithCreds(arg1:backend.S3Credentials,arg2:string):Promise<Array<backend.BackupInfo>>;
 
+export function ListJobs():Promise<Array<backend.JobInfo>>;
+
+export function ListMongoDatabaseStats(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Array<backend.MongoDatabaseStats>>;
+
 export function ListMongoDatabases(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Array<string>>;
 
 export function ListMySQLDatabases(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Array<string>>;
 
 export function ListPostgresDatabases(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Array<string>>;
 
+export function ListServerProfiles(arg1:string):Promise<Array<backend.ServerProfile>>;
+
 export function ListSubmodules(arg1:string):Promise<Array<string>>;
 
 export function NpmUpdateAction(arg1:string):Promise<void>;
@@ -58,24 +92,52 @@ export function OpenS3BackupFileDialog():Promise<string>;
 
 export function PerformUpdate(arg1:string):Promise<void>;
 
+export function PresignS3Object(arg1:backend.S3Credentials,arg2:string,arg3:string,arg4:number):Promise<backend.PresignedURL>;
+
+export function ReplaceServerProfiles(arg1:string,arg2:Array<backend.ServerProfile>):Promise<void>;
+
 export function RestoreMongoBackup(arg1:backend.S3Credentials,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<void>;
 
 export function RestoreMySQLBackup(arg1:backend.S3Credentials,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string):Promise<void>;
 
 export function RestorePostgresBackup(arg1:backend.S3Credentials,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string):Promise<void>;
 
+export function RestoreRepositoryBackup(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function RestoreS3Backup(arg1:backend.S3Credentials,arg2:backend.S3Credentials,arg3:string,arg4:string,arg5:string,arg6:string,arg7:boolean):Promise<void>;
 
 export function RestoreS3BackupFromLocal(arg1:backend.S3Credentials,arg2:string,arg3:string,arg4:string,arg5:string,arg6:boolean):Promise<void>;
 
+export function SaveServerProfile(arg1:backend.ServerProfile):Promise<backend.ServerProfile>;
+
+export function SetBackupRepositoryServer(arg1:string):Promise<void>;
+
+export function SnapshotS3BucketWithCreds(arg1:backend.S3Credentials,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function StatS3Object(arg1:backend.S3Credentials,arg2:string):Promise<backend.ObjectInfo>;
+
+export function SyncS3(arg1:backend.SyncEndpoint,arg2:backend.SyncEndpoint,arg3:backend.SyncOptions):Promise<backend.SyncReport>;
+
 export function TagAction(arg1:string,arg2:string):Promise<void>;
 
+export function TestMongoConnection(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
 export function TestMySQLConnection(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function ThawBackup(arg1:string,arg2:backend.ThawOptions):Promise<backend.ArchiveStatus>;
+
+export function ThawBackupWithCreds(arg1:backend.S3Credentials,arg2:string,arg3:backend.ThawOptions):Promise<backend.ArchiveStatus>;
+
 export function TransferMongoDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:boolean):Promise<void>;
 
 export function TransferMySQLDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:boolean):Promise<void>;
 
 export function TransferPostgresDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:boolean):Promise<void>;
 
+export function UnlockVault(arg1:string):Promise<void>;
+
 export function UpdateGitSubmodules(arg1:string,arg2:Array<string>):Promise<void>;
+
+export function UploadS3Object(arg1:backend.S3Credentials,arg2:string,arg3:string):Promise<void>;
+
+export function VerifyBackup(arg1:string,arg2:string):Promise<backend.VerifyReport>;
@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function BackupDatabase(arg1, arg2) {
+  return window['go']['main']['App']['BackupDatabase'](arg1, arg2);
+}
+
+export function BackupS3Bucket(arg1, arg2, arg3) {
+  return window['go']['main']['App']['BackupS3Bucket'](arg1, arg2, arg3);
+}
+
+export function CancelJob(arg1) {
+  return window['go']['main']['App']['CancelJob'](arg1);
+}
+
 export function ChangeBranch(arg1, arg2) {
   return window['go']['main']['App']['ChangeBranch'](arg1, arg2);
 }
@@ -14,10 +26,26 @@ export function CleanSubmodules(arg1) {
   return window['go']['main']['App']['CleanSubmodules'](arg1);
 }
 
+export function CleanWorkDir(arg1) {
+  return window['go']['main']['App']['CleanWorkDir'](arg1);
+}
+
+export function CreateBackupKey(arg1) {
+  return window['go']['main']['App']['CreateBackupKey'](arg1);
+}
+
 export function CreateTag(arg1, arg2, arg3) {
   return window['go']['main']['App']['CreateTag'](arg1, arg2, arg3);
 }
 
+export function DeleteS3Object(arg1, arg2) {
+  return window['go']['main']['App']['DeleteS3Object'](arg1, arg2);
+}
+
+export function DeleteServerProfile(arg1) {
+  return window['go']['main']['App']['DeleteServerProfile'](arg1);
+}
+
 export function DownloadBackupWithCreds(arg1, arg2, arg3) {
   return window['go']['main']['App']['DownloadBackupWithCreds'](arg1, arg2, arg3);
 }
@@ -34,6 +62,14 @@ export function DumpPostgresDatabase(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['App']['DumpPostgresDatabase'](arg1, arg2, arg3, arg4, arg5);
 }
 
+export function GetArchiveStatusWithCreds(arg1, arg2) {
+  return window['go']['main']['App']['GetArchiveStatusWithCreds'](arg1, arg2);
+}
+
+export function GetBackupRepositoryServerID() {
+  return window['go']['main']['App']['GetBackupRepositoryServerID']();
+}
+
 export function GetBranches(arg1) {
   return window['go']['main']['App']['GetBranches'](arg1);
 }
@@ -66,6 +102,10 @@ export function GetPendingChanges(arg1) {
   return window['go']['main']['App']['GetPendingChanges'](arg1);
 }
 
+export function GetVaultStatus() {
+  return window['go']['main']['App']['GetVaultStatus']();
+}
+
 export function GitStatus(arg1) {
   return window['go']['main']['App']['GitStatus'](arg1);
 }
@@ -74,6 +114,10 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportLocalStorageProfiles(arg1, arg2) {
+  return window['go']['main']['App']['ImportLocalStorageProfiles'](arg1, arg2);
+}
+
 export function InstallNpmDependencies(arg1, arg2) {
   return window['go']['main']['App']['InstallNpmDependencies'](arg1, arg2);
 }
@@ -82,10 +126,30 @@ export function InstallSubmodules(arg1, arg2) {
   return window['go']['main']['App']['InstallSubmodules'](arg1, arg2);
 }
 
+export function ListBackupCatalog(arg1) {
+  return window['go']['main']['App']['ListBackupCatalog'](arg1);
+}
+
+export function ListBackupKeys() {
+  return window['go']['main']['App']['ListBackupKeys']();
+}
+
+export function ListBackupsPage(arg1, arg2) {
+  return window['go']['main']['App']['ListBackupsPage'](arg1, arg2);
+}
+
 export function ListBackupsWithCreds(arg1, arg2) {
   return window['go']['main']['App']['ListBackupsWithCreds'](arg1, arg2);
 }
 
+export function ListJobs() {
+  return window['go']['main']['App']['ListJobs']();
+}
+
+export function ListMongoDatabaseStats(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ListMongoDatabaseStats'](arg1, arg2, arg3, arg4);
+}
+
 export function ListMongoDatabases(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['ListMongoDatabases'](arg1, arg2, arg3, arg4);
 }
@@ -98,6 +162,10 @@ export function ListPostgresDatabases(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['ListPostgresDatabases'](arg1, arg2, arg3, arg4);
 }
 
+export function ListServerProfiles(arg1) {
+  return window['go']['main']['App']['ListServerProfiles'](arg1);
+}
+
 export function ListSubmodules(arg1) {
   return window['go']['main']['App']['ListSubmodules'](arg1);
 }
@@ -114,6 +182,14 @@ export function PerformUpdate(arg1) {
   return window['go']['main']['App']['PerformUpdate'](arg1);
 }
 
+export function PresignS3Object(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['PresignS3Object'](arg1, arg2, arg3, arg4);
+}
+
+export function ReplaceServerProfiles(arg1, arg2) {
+  return window['go']['main']['App']['ReplaceServerProfiles'](arg1, arg2);
+}
+
 export function RestoreMongoBackup(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['RestoreMongoBackup'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
@@ -126,6 +202,10 @@ export function RestorePostgresBackup(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
   return window['go']['main']['App']['RestorePostgresBackup'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
 
+export function RestoreRepositoryBackup(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RestoreRepositoryBackup'](arg1, arg2, arg3);
+}
+
 export function RestoreS3Backup(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
   return window['go']['main']['App']['RestoreS3Backup'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
@@ -134,14 +214,46 @@ export function RestoreS3BackupFromLocal(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['RestoreS3BackupFromLocal'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
+export function SaveServerProfile(arg1) {
+  return window['go']['main']['App']['SaveServerProfile'](arg1);
+}
+
+export function SetBackupRepositoryServer(arg1) {
+  return window['go']['main']['App']['SetBackupRepositoryServer'](arg1);
+}
+
+export function SnapshotS3BucketWithCreds(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SnapshotS3BucketWithCreds'](arg1, arg2, arg3, arg4);
+}
+
+export function StatS3Object(arg1, arg2) {
+  return window['go']['main']['App']['StatS3Object'](arg1, arg2);
+}
+
+export function SyncS3(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SyncS3'](arg1, arg2, arg3);
+}
+
 export function TagAction(arg1, arg2) {
   return window['go']['main']['App']['TagAction'](arg1, arg2);
 }
 
+export function TestMongoConnection(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['TestMongoConnection'](arg1, arg2, arg3, arg4);
+}
+
 export function TestMySQLConnection(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['TestMySQLConnection'](arg1, arg2, arg3, arg4);
 }
 
+export function ThawBackup(arg1, arg2) {
+  return window['go']['main']['App']['ThawBackup'](arg1, arg2);
+}
+
+export function ThawBackupWithCreds(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ThawBackupWithCreds'](arg1, arg2, arg3);
+}
+
 export function TransferMongoDatabase(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10) {
   return window['go']['main']['App']['TransferMongoDatabase'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10);
 }
@@ -154,6 +266,18 @@ export function TransferPostgresDatabase(arg1, arg2, arg3, arg4, arg5, arg6, arg
   return window['go']['main']['App']['TransferPostgresDatabase'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10);
 }
 
+export function UnlockVault(arg1) {
+  return window['go']['main']['App']['UnlockVault'](arg1);
+}
+
 export function UpdateGitSubmodules(arg1, arg2) {
   return window['go']['main']['App']['UpdateGitSubmodules'](arg1, arg2);
 }
+
+export function UploadS3Object(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UploadS3Object'](arg1, arg2, arg3);
+}
+
+export function VerifyBackup(arg1, arg2) {
+  return window['go']['main']['App']['VerifyBackup'](arg1, arg2);
+}
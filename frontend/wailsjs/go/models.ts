@@ -1,9 +1,34 @@
 export namespace backend {
 	
+	export class ArchiveStatus {
+	    key: string;
+	    storageClass: string;
+	    archived: boolean;
+	    restoreInProgress: boolean;
+	    restoredUntil?: any;
+	    retrievable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.storageClass = source["storageClass"];
+	        this.archived = source["archived"];
+	        this.restoreInProgress = source["restoreInProgress"];
+	        this.restoredUntil = source["restoredUntil"];
+	        this.retrievable = source["retrievable"];
+	    }
+	}
 	export class BackupInfo {
 	    name: string;
+	    key: string;
 	    size: number;
-	    lastModified: string;
+	    lastModified: any;
+	    storageClass: string;
+	    archived: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new BackupInfo(source);
@@ -12,8 +37,177 @@ export namespace backend {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
+	        this.key = source["key"];
 	        this.size = source["size"];
 	        this.lastModified = source["lastModified"];
+	        this.storageClass = source["storageClass"];
+	        this.archived = source["archived"];
+	    }
+	}
+	export class BackupListQuery {
+	    prefix: string;
+	    folders: boolean;
+	    delimiter: string;
+	    startAfter: string;
+	    continuationToken: string;
+	    pageSize: number;
+	    contains: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupListQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.prefix = source["prefix"];
+	        this.folders = source["folders"];
+	        this.delimiter = source["delimiter"];
+	        this.startAfter = source["startAfter"];
+	        this.continuationToken = source["continuationToken"];
+	        this.pageSize = source["pageSize"];
+	        this.contains = source["contains"];
+	    }
+	}
+	export class BackupPage {
+	    items: BackupInfo[];
+	    folders: string[];
+	    nextToken: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], BackupInfo);
+	        this.folders = source["folders"];
+	        this.nextToken = source["nextToken"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupResult {
+	    bucket: string;
+	    key: string;
+	    engine: string;
+	    engineVersion?: string;
+	    toolVersion: string;
+	    server: string;
+	    database?: string;
+	    size: number;
+	    sha256: string;
+	    compression: string;
+	    encryption?: string;
+	    keyId?: string;
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.engine = source["engine"];
+	        this.engineVersion = source["engineVersion"];
+	        this.toolVersion = source["toolVersion"];
+	        this.server = source["server"];
+	        this.database = source["database"];
+	        this.size = source["size"];
+	        this.sha256 = source["sha256"];
+	        this.compression = source["compression"];
+	        this.encryption = source["encryption"];
+	        this.keyId = source["keyId"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	export class CacheCleanReport {
+	    dir: string;
+	    removed: number;
+	    freed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CacheCleanReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.removed = source["removed"];
+	        this.freed = source["freed"];
+	    }
+	}
+	export class CatalogEntry {
+	    backup: BackupResult;
+	    objectSize: number;
+	    lastModified: any;
+	    storageClass: string;
+	    archived: boolean;
+	    hasManifest: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.backup = this.convertValues(source["backup"], BackupResult);
+	        this.objectSize = source["objectSize"];
+	        this.lastModified = source["lastModified"];
+	        this.storageClass = source["storageClass"];
+	        this.archived = source["archived"];
+	        this.hasManifest = source["hasManifest"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CatalogFilter {
+	    engine: string;
+	    server: string;
+	    database: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.engine = source["engine"];
+	        this.server = source["server"];
+	        this.database = source["database"];
 	    }
 	}
 	export class Commit {
@@ -36,6 +230,116 @@ export namespace backend {
 	        this.Branch = source["Branch"];
 	    }
 	}
+	export class JobInfo {
+	    id: string;
+	    kind: string;
+	    title: string;
+	    state: string;
+	    progress: number;
+	    message?: string;
+	    error?: string;
+	    startedAt: any;
+	    finishedAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.title = source["title"];
+	        this.state = source["state"];
+	        this.progress = source["progress"];
+	        this.message = source["message"];
+	        this.error = source["error"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	    }
+	}
+	export class MongoDatabaseStats {
+	    name: string;
+	    sizeOnDisk: number;
+	    collections: number;
+	    documents: number;
+	    empty: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new MongoDatabaseStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.sizeOnDisk = source["sizeOnDisk"];
+	        this.collections = source["collections"];
+	        this.documents = source["documents"];
+	        this.empty = source["empty"];
+	    }
+	}
+	export class ObjectInfo {
+	    bucket: string;
+	    key: string;
+	    size: number;
+	    lastModified: any;
+	    etag: string;
+	    contentType: string;
+	    storageClass: string;
+	    metadata: Record<string, string>;
+	    archive?: ArchiveStatus;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.size = source["size"];
+	        this.lastModified = source["lastModified"];
+	        this.etag = source["etag"];
+	        this.contentType = source["contentType"];
+	        this.storageClass = source["storageClass"];
+	        this.metadata = source["metadata"];
+	        this.archive = this.convertValues(source["archive"], ArchiveStatus);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PresignedURL {
+	    url: string;
+	    method: string;
+	    expiresAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PresignedURL(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.method = source["method"];
+	        this.expiresAt = source["expiresAt"];
+	    }
+	}
 	export class S3Credentials {
 	    accessKey: string;
 	    secretKey: string;
@@ -44,6 +348,10 @@ export namespace backend {
 	    region: string;
 	    useHttps: boolean;
 	    bucket: string;
+	    sessionToken?: string;
+	    profile?: string;
+	    virtualHost?: boolean;
+	    caBundle?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new S3Credentials(source);
@@ -58,6 +366,170 @@ export namespace backend {
 	        this.region = source["region"];
 	        this.useHttps = source["useHttps"];
 	        this.bucket = source["bucket"];
+	        this.sessionToken = source["sessionToken"];
+	        this.profile = source["profile"];
+	        this.virtualHost = source["virtualHost"];
+	        this.caBundle = source["caBundle"];
+	    }
+	}
+	export class ServerProfile {
+	    id: string;
+	    name: string;
+	    engine: string;
+	    host: string;
+	    port: string;
+	    user?: string;
+	    password?: string;
+	    authDatabase?: string;
+	    database?: string;
+	    accessKey?: string;
+	    secretKey?: string;
+	    region?: string;
+	    useHttps?: boolean;
+	    bucket?: string;
+	    awsProfile?: string;
+	    virtualHost?: boolean;
+	    caBundle?: string;
+	    secretRef?: string;
+	    isDefault?: boolean;
+	    createdAt?: string;
+	    updatedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.engine = source["engine"];
+	        this.host = source["host"];
+	        this.port = source["port"];
+	        this.user = source["user"];
+	        this.password = source["password"];
+	        this.authDatabase = source["authDatabase"];
+	        this.database = source["database"];
+	        this.accessKey = source["accessKey"];
+	        this.secretKey = source["secretKey"];
+	        this.region = source["region"];
+	        this.useHttps = source["useHttps"];
+	        this.bucket = source["bucket"];
+	        this.awsProfile = source["awsProfile"];
+	        this.virtualHost = source["virtualHost"];
+	        this.caBundle = source["caBundle"];
+	        this.secretRef = source["secretRef"];
+	        this.isDefault = source["isDefault"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
+	export class SyncChange {
+	    action: string;
+	    key: string;
+	    size: number;
+	    reason?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.key = source["key"];
+	        this.size = source["size"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class SyncEndpoint {
+	    server: string;
+	    bucket: string;
+	    prefix: string;
+	    localPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncEndpoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server = source["server"];
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.localPath = source["localPath"];
+	    }
+	}
+	export class SyncOptions {
+	    delete: boolean;
+	    dryRun: boolean;
+	    include: string[];
+	    exclude: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.delete = source["delete"];
+	        this.dryRun = source["dryRun"];
+	        this.include = source["include"];
+	        this.exclude = source["exclude"];
+	    }
+	}
+	export class SyncReport {
+	    source: string;
+	    destination: string;
+	    dryRun: boolean;
+	    changes: SyncChange[];
+	    unchanged: number;
+	    bytesCopied: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.destination = source["destination"];
+	        this.dryRun = source["dryRun"];
+	        this.changes = this.convertValues(source["changes"], SyncChange);
+	        this.unchanged = source["unchanged"];
+	        this.bytesCopied = source["bytesCopied"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TableCount {
+	    name: string;
+	    rows: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.rows = source["rows"];
 	    }
 	}
 	export class TagsResult {
@@ -74,6 +546,22 @@ export namespace backend {
 	        this.rcTags = source["rcTags"];
 	    }
 	}
+	export class ThawOptions {
+	    days: number;
+	    tier: string;
+	    wait: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ThawOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.days = source["days"];
+	        this.tier = source["tier"];
+	        this.wait = source["wait"];
+	    }
+	}
 	export class UpdateInfo {
 	    currentVersion: string;
 	    latestVersion: string;
@@ -92,6 +580,88 @@ export namespace backend {
 	        this.downloadUrl = source["downloadUrl"];
 	    }
 	}
+	export class VaultStatus {
+	    exists: boolean;
+	    protection: string;
+	    unlocked: boolean;
+	    entries: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VaultStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.exists = source["exists"];
+	        this.protection = source["protection"];
+	        this.unlocked = source["unlocked"];
+	        this.entries = source["entries"];
+	    }
+	}
+	export class VerifyCheck {
+	    name: string;
+	    passed: boolean;
+	    skipped?: boolean;
+	    detail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new VerifyCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.passed = source["passed"];
+	        this.skipped = source["skipped"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class VerifyReport {
+	    bucket: string;
+	    key: string;
+	    engine: string;
+	    verifiedAt: any;
+	    toolVersion: string;
+	    passed: boolean;
+	    checks: VerifyCheck[];
+	    restoreServer?: string;
+	    tables?: TableCount[];
+	
+	    static createFrom(source: any = {}) {
+	        return new VerifyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.engine = source["engine"];
+	        this.verifiedAt = source["verifiedAt"];
+	        this.toolVersion = source["toolVersion"];
+	        this.passed = source["passed"];
+	        this.checks = this.convertValues(source["checks"], VerifyCheck);
+	        this.restoreServer = source["restoreServer"];
+	        this.tables = this.convertValues(source["tables"], TableCount);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 